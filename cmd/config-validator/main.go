@@ -13,11 +13,16 @@ func main() {
 	var (
 		configPath = flag.String("config", "config.yaml", "Path to configuration file")
 		validate   = flag.Bool("validate", false, "Validate configuration only")
+		unresolved = flag.Bool("unresolved", false, "Keep ${...} references unresolved, e.g. to validate a config outside its environment")
 	)
 	flag.Parse()
 
 	// Load configuration
-	cfg, err := config.LoadConfig(*configPath)
+	load := config.LoadConfig
+	if *unresolved {
+		load = config.LoadConfigUnresolved
+	}
+	cfg, err := load(*configPath)
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
@@ -28,6 +28,8 @@ func main() {
 	configPath := flag.String("config", defaultConfigPath, "Path to configuration file")
 	manualTrigger := flag.Bool("manual", false, "Execute all backup strategies manually and exit")
 	manualStrategy := flag.String("strategy", "", "Execute a specific backup strategy manually and exit")
+	restoreStrategy := flag.String("restore", "", "Restore the latest backup chain of a strategy and exit")
+	restoreTarget := flag.String("restore-target", "", "Database URL, or data directory for physical backups, to restore into (used with -restore)")
 	flag.Parse()
 
 	// Load configuration
@@ -60,7 +62,7 @@ func main() {
 		cfg,
 		backupService,
 		s3Service,
-		slackService,
+		notification.NewNotifier(cfg, slackService),
 		monitoringService,
 	)
 
@@ -81,6 +83,18 @@ func main() {
 		return
 	}
 
+	if *restoreStrategy != "" {
+		if *restoreTarget == "" {
+			log.Fatal("-restore-target is required when restoring a backup")
+		}
+		log.WithField("strategy", *restoreStrategy).Info("Restore mode: restoring latest backup chain")
+		if err := schedulerService.RestoreLatestBackup(*restoreStrategy, *restoreTarget); err != nil {
+			log.Fatalf("Failed to restore backup: %v", err)
+		}
+		log.Info("Restore completed, exiting")
+		return
+	}
+
 	// Create context for graceful shutdown
 	_, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -91,6 +105,12 @@ func main() {
 
 	var wg sync.WaitGroup
 
+	// Serve Slack commands and "Retry now" buttons on the monitoring server
+	if cfg.Global.SlackCommands.Enabled() {
+		monitoringService.Handle(cfg.Global.SlackCommands.Path, notification.NewSlackCommandHandler(cfg, slackService, schedulerService))
+		log.WithField("path", cfg.Global.SlackCommands.Path).Info("Slack commands enabled")
+	}
+
 	// Start monitoring HTTP server
 	wg.Add(1)
 	go func() {
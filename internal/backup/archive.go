@@ -0,0 +1,155 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// writeTarGz writes the contents of sourceDir into a gzip-compressed tar archive.
+// Paths inside the archive are relative to sourceDir.
+func writeTarGz(sourceDir, targetPath string) error {
+	targetFile, err := os.Create(targetPath)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer targetFile.Close()
+
+	gzipWriter := gzip.NewWriter(targetFile)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	err = filepath.Walk(sourceDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(sourceDir, path)
+		if err != nil {
+			return err
+		}
+		if relPath == "." {
+			return nil
+		}
+
+		return addTarEntry(tarWriter, path, filepath.ToSlash(relPath), info)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to archive %s: %w", sourceDir, err)
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return fmt.Errorf("failed to finish tar archive: %w", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return fmt.Errorf("failed to finish gzip stream: %w", err)
+	}
+	return targetFile.Close()
+}
+
+// addTarEntry writes a single file, directory or symlink to the archive
+func addTarEntry(tarWriter *tar.Writer, path, name string, info os.FileInfo) error {
+	var link string
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+		link = target
+	}
+
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	if info.IsDir() {
+		header.Name += "/"
+	}
+
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Copy exactly the size recorded in the header in case the file is growing
+	_, err = io.CopyN(tarWriter, file, header.Size)
+	return err
+}
+
+// extractTarGz extracts a gzip-compressed tar archive into targetDir. Entries that
+// would be written outside of targetDir are rejected.
+func extractTarGz(archivePath, targetDir string) error {
+	archiveFile, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer archiveFile.Close()
+
+	gzipReader, err := gzip.NewReader(archiveFile)
+	if err != nil {
+		return fmt.Errorf("failed to read gzip stream: %w", err)
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar archive: %w", err)
+		}
+
+		path := filepath.Join(targetDir, filepath.FromSlash(header.Name))
+		if path != filepath.Clean(targetDir) && !strings.HasPrefix(path, filepath.Clean(targetDir)+string(os.PathSeparator)) {
+			return fmt.Errorf("archive entry %s points outside of the target directory", header.Name)
+		}
+
+		if err := extractTarEntry(tarReader, header, path); err != nil {
+			return fmt.Errorf("failed to extract %s: %w", header.Name, err)
+		}
+	}
+}
+
+// extractTarEntry writes a single archive entry to path
+func extractTarEntry(tarReader *tar.Reader, header *tar.Header, path string) error {
+	switch header.Typeflag {
+	case tar.TypeDir:
+		return os.MkdirAll(path, 0750)
+	case tar.TypeSymlink:
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			return err
+		}
+		return os.Symlink(header.Linkname, path)
+	case tar.TypeReg:
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			return err
+		}
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode)&0777)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		if _, err := io.CopyN(file, tarReader, header.Size); err != nil {
+			return err
+		}
+		return file.Close()
+	default:
+		// Other entry types are never written by writeTarGz
+		return nil
+	}
+}
@@ -3,6 +3,7 @@ package backup
 import (
 	"compress/gzip"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -30,6 +31,50 @@ type BackupResult struct {
 	StartTime   time.Time
 	EndTime     time.Time
 	CommandLogs []string
+	Databases   []DatabaseResult
+	Details     []ResultDetail // Strategy specific details shown in notifications
+
+	ManifestPath string
+	S3Locations  []string // Locations of the uploaded artifacts, set once they are uploaded
+	Incremental  bool
+	BaseBackup   string          // Manifest name of the full backup an incremental backup chains from
+	BinlogStart  *BinlogPosition // First binlog position covered by an incremental backup
+	BinlogEnd    *BinlogPosition // Binlog position the backup is consistent with
+
+	ReplicationLag *time.Duration // Lag of the replica measured before the backup
+	UsedFallback   bool           // The backup was taken from the fallback URL instead of the replica
+	ServerVersion  string         // Version of the server the backup was taken from, if detected
+	ToolVersion    string         // Version of the dump tool used, if detected
+}
+
+// DatabaseResult holds per-database details for strategies that dump several databases
+type DatabaseResult struct {
+	Name       string
+	BackupPath string // Set only when each database is stored as its own artifact
+	Size       int64  // Uncompressed dump size
+	Duration   time.Duration
+}
+
+// ResultDetail is a labelled value describing a backup, e.g. the etcd revision of a snapshot
+type ResultDetail struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// ArtifactPaths returns the local files produced by the backup that need to be uploaded
+func (br *BackupResult) ArtifactPaths() []string {
+	if !br.splitArtifacts() {
+		if br.BackupPath == "" {
+			return nil
+		}
+		return []string{br.BackupPath}
+	}
+
+	paths := make([]string, 0, len(br.Databases))
+	for _, database := range br.Databases {
+		paths = append(paths, database.BackupPath)
+	}
+	return paths
 }
 
 // DatabaseStrategy interface defines the contract for database backup strategies
@@ -39,6 +84,26 @@ type DatabaseStrategy interface {
 	GetType() string
 }
 
+// ErrNoBinlogChanges is returned by incremental backups when nothing was written since the last backup
+var ErrNoBinlogChanges = errors.New("no binlog changes since the last backup")
+
+// BinlogStrategy is implemented by strategies that support binlog-based incremental backups
+type BinlogStrategy interface {
+	BackupIncremental(ctx context.Context, databaseURL, outputPath string, from BinlogPosition, callback ProgressCallback) (*BackupResult, error)
+}
+
+// RestorableStrategy is implemented by strategies that can restore their own artifacts.
+// Artifacts are restored in the given order into target, whose format depends on the strategy.
+type RestorableStrategy interface {
+	Restore(ctx context.Context, target string, artifactPaths []string, callback ProgressCallback) error
+}
+
+// ConfigurableStrategy is implemented by strategies that accept per-strategy settings.
+// WithConfig returns a copy of the strategy configured for a single backup run.
+type ConfigurableStrategy interface {
+	WithConfig(strategyConfig config.StrategyConfig) DatabaseStrategy
+}
+
 // BackupService handles database backup operations using the Strategy pattern
 type BackupService struct {
 	config     *config.Config
@@ -65,20 +130,37 @@ func (bs *BackupService) registerStrategies() {
 	bs.strategies["mysql"] = NewMySQLStrategy(bs.logger)
 	bs.strategies["mariadb"] = NewMySQLStrategy(bs.logger) // MySQL strategy handles MariaDB too
 	bs.strategies["mongodb"] = NewMongoStrategy(bs.logger)
+	bs.strategies["mariabackup"] = NewMariabackupStrategy(bs.logger, "mariabackup")
+	bs.strategies["xtrabackup"] = NewMariabackupStrategy(bs.logger, "xtrabackup")
+	bs.strategies["sqlite"] = NewSQLiteStrategy(bs.logger)
+	bs.strategies["redis"] = NewRedisStrategy(bs.logger)
+	bs.strategies["files"] = NewFilesStrategy(bs.logger)
+	bs.strategies["command"] = NewCommandStrategy(bs.logger)
+	bs.strategies["etcd"] = NewEtcdStrategy(bs.logger)
 }
 
 // ExecuteBackup performs a backup for a specific strategy
 func (bs *BackupService) ExecuteBackup(ctx context.Context, strategy config.StrategyConfig) (*BackupResult, error) {
-	return bs.executeBackup(ctx, strategy, nil)
+	return bs.executeBackup(ctx, strategy, nil, nil)
 }
 
 // ExecuteBackupWithProgress performs a backup with progress callbacks
 func (bs *BackupService) ExecuteBackupWithProgress(ctx context.Context, strategy config.StrategyConfig, progressCallback ProgressCallback) (*BackupResult, error) {
-	return bs.executeBackup(ctx, strategy, progressCallback)
+	return bs.executeBackup(ctx, strategy, progressCallback, nil)
 }
 
-// executeBackup is the main backup execution logic
-func (bs *BackupService) executeBackup(ctx context.Context, strategyConfig config.StrategyConfig, progressCallback ProgressCallback) (*BackupResult, error) {
+// ExecuteIncrementalBackup performs an incremental backup continuing from the latest
+// backup described by base
+func (bs *BackupService) ExecuteIncrementalBackup(ctx context.Context, strategy config.StrategyConfig, base *Manifest, progressCallback ProgressCallback) (*BackupResult, error) {
+	if base == nil || base.BinlogEnd == nil {
+		return &BackupResult{Strategy: strategy.Name, StartTime: time.Now()}, fmt.Errorf("no previous backup with a binlog position for strategy %s; a full backup is required first", strategy.Name)
+	}
+	return bs.executeBackup(ctx, strategy, progressCallback, base)
+}
+
+// executeBackup is the main backup execution logic. When base is set an incremental
+// backup continuing from it is taken instead of a full backup.
+func (bs *BackupService) executeBackup(ctx context.Context, strategyConfig config.StrategyConfig, progressCallback ProgressCallback, base *Manifest) (*BackupResult, error) {
 	startTime := time.Now()
 	result := &BackupResult{
 		Strategy:    strategyConfig.Name,
@@ -103,6 +185,11 @@ func (bs *BackupService) executeBackup(ctx context.Context, strategyConfig confi
 		return result, err
 	}
 
+	// Apply per-strategy settings
+	if configurable, ok := dbStrategy.(ConfigurableStrategy); ok {
+		dbStrategy = configurable.WithConfig(strategyConfig)
+	}
+
 	// Validate connection URL
 	if err := dbStrategy.ValidateConnection(strategyConfig.DatabaseURL); err != nil {
 		result.Error = err
@@ -113,6 +200,50 @@ func (bs *BackupService) executeBackup(ctx context.Context, strategyConfig confi
 		return result, err
 	}
 
+	// Fetch short-lived credentials for this run, revoked once the dump is done
+	strategyConfig, releaseCredentials, err := bs.acquireCredentials(ctx, strategyConfig, result)
+	if err != nil {
+		result.Error = err
+		result.Success = false
+		if progressCallback != nil {
+			progressCallback(strategyConfig.Name, fmt.Sprintf("❌ %s", err.Error()))
+		}
+		return result, err
+	}
+	defer releaseCredentials()
+
+	// Check the replication lag of replicas before dumping from them
+	databaseURL, err := bs.resolveSourceURL(ctx, dbStrategy, strategyConfig, result, progressCallback)
+	if err != nil {
+		result.Error = err
+		result.Success = false
+		if progressCallback != nil {
+			progressCallback(strategyConfig.Name, fmt.Sprintf("❌ %s", err.Error()))
+		}
+		return result, err
+	}
+
+	// Pick the client tools matching the server version
+	dbStrategy, err = bs.selectTools(ctx, dbStrategy, databaseURL, result)
+	if err != nil {
+		result.Error = err
+		result.Success = false
+		if progressCallback != nil {
+			progressCallback(strategyConfig.Name, fmt.Sprintf("❌ %s", err.Error()))
+		}
+		return result, err
+	}
+
+	// Check tools, credentials and privileges before spending time on the dump
+	if err := bs.runPreflight(ctx, dbStrategy, databaseURL, result); err != nil {
+		result.Error = err
+		result.Success = false
+		if progressCallback != nil {
+			progressCallback(strategyConfig.Name, fmt.Sprintf("❌ %s", err.Error()))
+		}
+		return result, err
+	}
+
 	// Create temp directory if it doesn't exist
 	if err := os.MkdirAll(bs.config.Global.TempDir, 0755); err != nil {
 		err = fmt.Errorf("failed to create temp directory: %w", err)
@@ -126,6 +257,9 @@ func (bs *BackupService) executeBackup(ctx context.Context, strategyConfig confi
 
 	// Generate backup filename
 	backupPath := bs.generateBackupPath(strategyConfig, startTime)
+	if base != nil {
+		backupPath = bs.generateIncrementalBackupPath(strategyConfig, startTime)
+	}
 
 	// Parse timeout
 	timeout, err := config.ParseDuration(bs.config.Global.Timeout.Backup)
@@ -148,12 +282,24 @@ func (bs *BackupService) executeBackup(ctx context.Context, strategyConfig confi
 		progressCallback(strategyConfig.Name, fmt.Sprintf("Executing %s backup command...", strategyConfig.DatabaseType))
 	}
 
-	backupResult, err := dbStrategy.Backup(timeoutCtx, strategyConfig.DatabaseURL, backupPath, progressCallback)
+	var backupResult *BackupResult
+	if base != nil {
+		binlogStrategy, ok := dbStrategy.(BinlogStrategy)
+		if !ok {
+			err := fmt.Errorf("database type %s does not support incremental backups", strategyConfig.DatabaseType)
+			result.Error = err
+			result.Success = false
+			return result, err
+		}
+		backupResult, err = binlogStrategy.BackupIncremental(timeoutCtx, databaseURL, backupPath, *base.BinlogEnd, progressCallback)
+	} else {
+		backupResult, err = dbStrategy.Backup(timeoutCtx, databaseURL, backupPath, progressCallback)
+	}
 	if err != nil {
 		result.Error = err
 		result.Success = false
 		if backupResult != nil {
-			result.CommandLogs = backupResult.CommandLogs
+			result.CommandLogs = append(result.CommandLogs, backupResult.CommandLogs...)
 		}
 		if progressCallback != nil {
 			progressCallback(strategyConfig.Name, fmt.Sprintf("❌ Backup failed: %s", err.Error()))
@@ -163,12 +309,38 @@ func (bs *BackupService) executeBackup(ctx context.Context, strategyConfig confi
 
 	// Copy result data
 	if backupResult != nil {
-		result.CommandLogs = backupResult.CommandLogs
+		result.CommandLogs = append(result.CommandLogs, backupResult.CommandLogs...)
 		result.BackupPath = backupResult.BackupPath
+		result.Databases = backupResult.Databases
+		result.Details = backupResult.Details
+		result.BinlogStart = backupResult.BinlogStart
+		result.BinlogEnd = backupResult.BinlogEnd
+	}
+	releaseCredentials()
+	if base != nil {
+		result.Incremental = true
+		result.BaseBackup = base.BaseBackup
 	}
 
-	// Handle compression
-	if err := bs.handleCompression(strategyConfig, &backupPath, progressCallback); err != nil {
+	// Handle compression; strategies may store the artifact under a different name
+	artifactPaths := []string{backupPath}
+	if result.BackupPath != "" {
+		artifactPaths[0] = result.BackupPath
+	}
+	if result.splitArtifacts() {
+		artifactPaths = artifactPaths[:0]
+		for i := range result.Databases {
+			if err := bs.handleCompression(strategyConfig, &result.Databases[i].BackupPath, progressCallback); err != nil {
+				result.Error = err
+				result.Success = false
+				if progressCallback != nil {
+					progressCallback(strategyConfig.Name, fmt.Sprintf("❌ Compression failed: %s", err.Error()))
+				}
+				return result, err
+			}
+			artifactPaths = append(artifactPaths, result.Databases[i].BackupPath)
+		}
+	} else if err := bs.handleCompression(strategyConfig, &artifactPaths[0], progressCallback); err != nil {
 		result.Error = err
 		result.Success = false
 		if progressCallback != nil {
@@ -178,7 +350,7 @@ func (bs *BackupService) executeBackup(ctx context.Context, strategyConfig confi
 	}
 
 	// Finalize result
-	if err := bs.finalizeResult(result, backupPath, progressCallback); err != nil {
+	if err := bs.finalizeResult(result, artifactPaths, progressCallback); err != nil {
 		result.Error = err
 		result.Success = false
 		if progressCallback != nil {
@@ -187,21 +359,72 @@ func (bs *BackupService) executeBackup(ctx context.Context, strategyConfig confi
 		return result, err
 	}
 
+	// Write the manifest describing the backup
+	if err := bs.writeManifest(strategyConfig, result); err != nil {
+		result.Error = err
+		result.Success = false
+		if progressCallback != nil {
+			progressCallback(strategyConfig.Name, fmt.Sprintf("❌ Failed to write backup manifest: %s", err.Error()))
+		}
+		return result, err
+	}
+
 	result.Success = true
 	bs.logger.WithFields(logrus.Fields{
 		"strategy": strategyConfig.Name,
 		"size":     result.Size,
 		"duration": result.Duration,
-		"path":     backupPath,
+		"paths":    artifactPaths,
 	}).Info("Backup completed successfully")
 
 	return result, nil
 }
 
+// ExecuteRestore restores the given artifacts, in order, into target using the strategy's restore support
+func (bs *BackupService) ExecuteRestore(ctx context.Context, strategyConfig config.StrategyConfig, target string, artifactPaths []string, progressCallback ProgressCallback) error {
+	dbStrategy, exists := bs.strategies[strategyConfig.DatabaseType]
+	if !exists {
+		return fmt.Errorf("unsupported database type: %s", strategyConfig.DatabaseType)
+	}
+	if configurable, ok := dbStrategy.(ConfigurableStrategy); ok {
+		dbStrategy = configurable.WithConfig(strategyConfig)
+	}
+
+	restorer, ok := dbStrategy.(RestorableStrategy)
+	if !ok {
+		return fmt.Errorf("database type %s does not support restores", strategyConfig.DatabaseType)
+	}
+
+	bs.logger.WithFields(logrus.Fields{
+		"strategy":  strategyConfig.Name,
+		"artifacts": artifactPaths,
+	}).Info("Starting restore")
+
+	if err := restorer.Restore(ctx, target, artifactPaths, progressCallback); err != nil {
+		return fmt.Errorf("restore of strategy %s failed: %w", strategyConfig.Name, err)
+	}
+
+	bs.logger.WithField("strategy", strategyConfig.Name).Info("Restore completed successfully")
+	return nil
+}
+
+// writeManifest writes the manifest of a finalized backup next to its artifacts
+func (bs *BackupService) writeManifest(strategyConfig config.StrategyConfig, result *BackupResult) error {
+	name := bs.backupName(strategyConfig, result.StartTime)
+	manifestPath := filepath.Join(bs.config.Global.TempDir, name+ManifestSuffix)
+
+	if err := NewManifest(name, strategyConfig.DatabaseType, result).Write(manifestPath); err != nil {
+		return err
+	}
+
+	result.ManifestPath = manifestPath
+	return nil
+}
+
 // handleCompression handles file compression if enabled
 func (bs *BackupService) handleCompression(strategyConfig config.StrategyConfig, backupPath *string, progressCallback ProgressCallback) error {
-	// Compress if enabled (skip for MongoDB as it's already compressed)
-	if bs.config.Global.S3.Compression == "gzip" && strategyConfig.DatabaseType != "mongodb" {
+	// Compress if enabled (skip artifacts that are already compressed, e.g. MongoDB archives)
+	if bs.config.Global.S3.Compression == "gzip" && !strings.HasSuffix(*backupPath, ".gz") {
 		if progressCallback != nil {
 			progressCallback(strategyConfig.Name, "Compressing backup file...")
 		}
@@ -214,24 +437,27 @@ func (bs *BackupService) handleCompression(strategyConfig config.StrategyConfig,
 		*backupPath = compressedPath
 	}
 
-	// For MongoDB, the backup path might have been changed to .tar.gz
-	if strategyConfig.DatabaseType == "mongodb" && !strings.HasSuffix(*backupPath, ".tar.gz") {
-		*backupPath = *backupPath + ".tar.gz"
-	}
-
 	return nil
 }
 
 // finalizeResult finalizes the backup result with file information
-func (bs *BackupService) finalizeResult(result *BackupResult, backupPath string, progressCallback ProgressCallback) error {
-	// Get file size
-	fileInfo, err := os.Stat(backupPath)
-	if err != nil {
-		return fmt.Errorf("failed to get backup file info: %w", err)
+func (bs *BackupService) finalizeResult(result *BackupResult, artifactPaths []string, progressCallback ProgressCallback) error {
+	// Get file sizes
+	var totalSize int64
+	for _, artifactPath := range artifactPaths {
+		fileInfo, err := os.Stat(artifactPath)
+		if err != nil {
+			return fmt.Errorf("failed to get backup file info: %w", err)
+		}
+		totalSize += fileInfo.Size()
 	}
 
-	result.BackupPath = backupPath
-	result.Size = fileInfo.Size()
+	if result.splitArtifacts() {
+		result.BackupPath = ""
+	} else {
+		result.BackupPath = artifactPaths[0]
+	}
+	result.Size = totalSize
 	result.EndTime = time.Now()
 	result.Duration = result.EndTime.Sub(result.StartTime)
 
@@ -243,11 +469,20 @@ func (bs *BackupService) finalizeResult(result *BackupResult, backupPath string,
 	return nil
 }
 
+// splitArtifacts reports whether the result consists of one artifact per database
+func (br *BackupResult) splitArtifacts() bool {
+	for _, database := range br.Databases {
+		if database.BackupPath != "" {
+			return true
+		}
+	}
+	return false
+}
+
 // generateBackupPath generates a backup file path
 func (bs *BackupService) generateBackupPath(strategy config.StrategyConfig, startTime time.Time) string {
 	// Generate backup filename with simplified format
-	timestamp := startTime.Format("20060102-150405")
-	filename := fmt.Sprintf("%s-%s", strategy.Name, timestamp)
+	filename := bs.backupName(strategy, startTime)
 
 	// Add appropriate extension based on database type
 	switch strategy.DatabaseType {
@@ -257,6 +492,22 @@ func (bs *BackupService) generateBackupPath(strategy config.StrategyConfig, star
 		filename += ".sql"
 	case "mongodb":
 		filename += ".archive"
+	case "mariabackup", "xtrabackup":
+		filename += ".xb"
+	case "sqlite":
+		filename += ".db"
+	case "redis":
+		filename += ".rdb"
+	case "files":
+		filename += ".tar"
+	case "etcd":
+		filename += ".snapshot"
+	case "command":
+		if strategy.Command.Extension != "" {
+			filename += strategy.Command.Extension
+		} else {
+			filename += ".backup"
+		}
 	default:
 		filename += ".backup"
 	}
@@ -264,6 +515,16 @@ func (bs *BackupService) generateBackupPath(strategy config.StrategyConfig, star
 	return filepath.Join(bs.config.Global.TempDir, filename)
 }
 
+// generateIncrementalBackupPath generates the file path of an incremental binlog backup
+func (bs *BackupService) generateIncrementalBackupPath(strategy config.StrategyConfig, startTime time.Time) string {
+	return filepath.Join(bs.config.Global.TempDir, bs.backupName(strategy, startTime)+".binlog.sql")
+}
+
+// backupName returns the base name shared by the artifacts and manifest of a backup run
+func (bs *BackupService) backupName(strategy config.StrategyConfig, startTime time.Time) string {
+	return fmt.Sprintf("%s-%s", strategy.Name, startTime.Format("20060102-150405"))
+}
+
 // CleanupTempFiles removes temporary backup files
 func (bs *BackupService) CleanupTempFiles(filePath string) error {
 	if filePath == "" {
@@ -303,6 +564,36 @@ func (bs *BackupService) compressFile(srcPath, dstPath string) error {
 	return nil
 }
 
+// openArtifact opens a backup artifact for reading, transparently decompressing gzip files
+func openArtifact(artifactPath string) (io.ReadCloser, error) {
+	file, err := os.Open(artifactPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open artifact: %w", err)
+	}
+	if !strings.HasSuffix(artifactPath, ".gz") {
+		return file, nil
+	}
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read compressed artifact %s: %w", filepath.Base(artifactPath), err)
+	}
+	return &gzipArtifact{Reader: gzipReader, file: file}, nil
+}
+
+// gzipArtifact closes both the gzip reader and the underlying file
+type gzipArtifact struct {
+	*gzip.Reader
+	file *os.File
+}
+
+// Close closes the gzip reader and the underlying file
+func (ga *gzipArtifact) Close() error {
+	ga.Reader.Close()
+	return ga.file.Close()
+}
+
 // formatBytes formats byte size to human readable format
 func formatBytes(bytes int64) string {
 	const unit = 1024
@@ -0,0 +1,305 @@
+package backup
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/sirupsen/logrus"
+
+	"easy-backup/internal/config"
+)
+
+// CommandStrategy implements DatabaseStrategy for arbitrary dump tools described in the config
+type CommandStrategy struct {
+	logger        *logrus.Logger
+	strategyName  string
+	options       config.CommandConfig
+	errorPatterns []*regexp.Regexp
+}
+
+// commandTemplateData is the data available to command argument templates
+type commandTemplateData struct {
+	OutputPath   string
+	DatabaseURL  string // Without its password, arguments are visible to every user of the host
+	StrategyName string
+}
+
+// NewCommandStrategy creates a new command backup strategy
+func NewCommandStrategy(logger *logrus.Logger) *CommandStrategy {
+	return &CommandStrategy{logger: logger}
+}
+
+// GetType returns the database type
+func (cs *CommandStrategy) GetType() string {
+	return "command"
+}
+
+// WithConfig returns a copy of the strategy running the command of the strategy
+func (cs *CommandStrategy) WithConfig(strategyConfig config.StrategyConfig) DatabaseStrategy {
+	configured := *cs
+	configured.strategyName = strategyConfig.Name
+	configured.options = strategyConfig.Command
+	configured.errorPatterns = nil
+	for _, pattern := range strategyConfig.Command.ErrorPatterns {
+		// Patterns are validated when the configuration is loaded
+		if compiled, err := regexp.Compile(pattern); err == nil {
+			configured.errorPatterns = append(configured.errorPatterns, compiled)
+		}
+	}
+	return &configured
+}
+
+// ValidateConnection checks that a command is configured. The database URL is
+// only passed to the command through its environment and argument templates.
+func (cs *CommandStrategy) ValidateConnection(databaseURL string) error {
+	if cs.options.Command == "" {
+		return fmt.Errorf("no command configured")
+	}
+	return nil
+}
+
+// Preflight checks that the configured command is installed
+func (cs *CommandStrategy) Preflight(ctx context.Context, databaseURL string) error {
+	return requireTools(cs.options.Command)
+}
+
+// Backup runs the configured command. The command either writes the backup to
+// {{.OutputPath}} or, with stdout enabled, to its standard output. Directories
+// written to the output path are archived as tar.gz.
+func (cs *CommandStrategy) Backup(ctx context.Context, databaseURL, outputPath string, callback ProgressCallback) (*BackupResult, error) {
+	result := &BackupResult{
+		CommandLogs: make([]string, 0),
+	}
+
+	if callback != nil {
+		callback("command", fmt.Sprintf("Starting %s backup...", cs.options.Command))
+	}
+
+	args, err := cs.renderArgs(commandTemplateData{
+		OutputPath:   outputPath,
+		DatabaseURL:  urlWithoutPassword(databaseURL),
+		StrategyName: cs.strategyName,
+	})
+	if err != nil {
+		if callback != nil {
+			callback("command", fmt.Sprintf("❌ %s", err.Error()))
+		}
+		return result, err
+	}
+	// The command may print the URL it read from the environment
+	var secrets []string
+	if parsedURL, err := url.Parse(databaseURL); err == nil && parsedURL.User != nil {
+		if password, ok := parsedURL.User.Password(); ok && password != "" {
+			secrets = append(secrets, password)
+		}
+	}
+	commandLine := cs.sanitize(strings.Join(append([]string{cs.options.Command}, args...), " "), secrets)
+
+	cmd := exec.CommandContext(ctx, cs.options.Command, args...)
+	// The URL is also provided in the environment, which unlike the arguments is not
+	// visible to other users of the host
+	cmd.Env = append(os.Environ(), "EASY_BACKUP_DATABASE_URL="+databaseURL)
+
+	var output *os.File
+	var stdout io.ReadCloser
+	if cs.options.Stdout {
+		output, err = os.Create(outputPath)
+		if err != nil {
+			return result, fmt.Errorf("failed to create backup file: %w", err)
+		}
+		defer output.Close()
+		cmd.Stdout = output
+	} else {
+		stdout, err = cmd.StdoutPipe()
+		if err != nil {
+			return result, fmt.Errorf("failed to get stdout pipe: %w", err)
+		}
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return result, fmt.Errorf("failed to get stderr pipe: %w", err)
+	}
+
+	// Start the command
+	if err := cmd.Start(); err != nil {
+		commandLog := fmt.Sprintf("Command failed to start: %s - Error: %s", commandLine, err.Error())
+		result.CommandLogs = append(result.CommandLogs, commandLog)
+		if callback != nil {
+			callback("command", fmt.Sprintf("❌ Command failed to start: %s", err.Error()))
+		}
+		return result, fmt.Errorf("%s failed to start: %w", cs.options.Command, err)
+	}
+
+	// Log the command execution (with secrets masked)
+	result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Command: %s", commandLine))
+
+	// Capture output in real-time
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	capture := func(pipe io.ReadCloser, streamType string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cs.captureOutput(pipe, streamType, secrets, result, &mu, callback)
+		}()
+	}
+	if stdout != nil {
+		capture(stdout, "stdout")
+	}
+	capture(stderr, "stderr")
+	wg.Wait()
+
+	// Wait for command to complete and check the exit code against the accepted ones
+	if err := cmd.Wait(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || !cs.acceptedExitCode(exitErr.ExitCode()) {
+			errorLog := fmt.Sprintf("Command failed: %s - Error: %s", commandLine, err.Error())
+			result.CommandLogs = append(result.CommandLogs, errorLog)
+			if callback != nil {
+				callback("command", fmt.Sprintf("❌ %s failed: %s", cs.options.Command, err.Error()))
+			}
+			return result, fmt.Errorf("%s failed: %w", cs.options.Command, err)
+		}
+	} else if !cs.acceptedExitCode(0) {
+		errorMsg := fmt.Sprintf("%s exited with code 0, which is not an accepted exit code", cs.options.Command)
+		result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Error: %s", errorMsg))
+		return result, fmt.Errorf("%s", errorMsg)
+	}
+
+	if output != nil {
+		if err := output.Close(); err != nil {
+			return result, fmt.Errorf("failed to write backup file: %w", err)
+		}
+	}
+
+	fileInfo, err := os.Stat(outputPath)
+	if err != nil {
+		errorMsg := "backup file was not created"
+		result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Error: %s", errorMsg))
+		if callback != nil {
+			callback("command", fmt.Sprintf("❌ %s", errorMsg))
+		}
+		return result, fmt.Errorf("%s", errorMsg)
+	}
+
+	result.BackupPath = outputPath
+	if fileInfo.IsDir() {
+		// Tools that write a directory (e.g. snapshot repositories) are archived as a whole
+		tarPath := outputPath + ".tar.gz"
+		if err := writeTarGz(outputPath, tarPath); err != nil {
+			os.Remove(tarPath)
+			return result, fmt.Errorf("failed to create tar archive: %w", err)
+		}
+		if err := os.RemoveAll(outputPath); err != nil {
+			cs.logger.WithError(err).Warn("Failed to clean up command output directory")
+		}
+		result.BackupPath = tarPath
+	}
+
+	if callback != nil {
+		callback("command", fmt.Sprintf("%s backup completed successfully", cs.options.Command))
+	}
+
+	return result, nil
+}
+
+// renderArgs executes the argument templates. Arguments are visible to every user of the
+// host, so the templates have no access to secrets: the command inherits the environment of
+// the service and reads them there.
+func (cs *CommandStrategy) renderArgs(data commandTemplateData) ([]string, error) {
+	args := make([]string, 0, len(cs.options.Args))
+	for _, arg := range cs.options.Args {
+		tmpl, err := template.New("arg").Option("missingkey=error").Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument template '%s': %w", arg, err)
+		}
+		var rendered strings.Builder
+		if err := tmpl.Execute(&rendered, data); err != nil {
+			return nil, fmt.Errorf("failed to render argument '%s': %w", arg, err)
+		}
+		args = append(args, rendered.String())
+	}
+	return args, nil
+}
+
+// urlWithoutPassword removes the password from a URL, keeping the user. Commands read the
+// full URL from EASY_BACKUP_DATABASE_URL.
+func urlWithoutPassword(databaseURL string) string {
+	parsedURL, err := url.Parse(databaseURL)
+	if err != nil || parsedURL.User == nil {
+		return databaseURL
+	}
+	if _, ok := parsedURL.User.Password(); !ok {
+		return databaseURL
+	}
+	parsedURL.User = url.User(parsedURL.User.Username())
+	return parsedURL.String()
+}
+
+// acceptedExitCode reports whether the exit code counts as success
+func (cs *CommandStrategy) acceptedExitCode(code int) bool {
+	exitCodes := cs.options.ExitCodes
+	if len(exitCodes) == 0 {
+		exitCodes = []int{0}
+	}
+	for _, accepted := range exitCodes {
+		if code == accepted {
+			return true
+		}
+	}
+	return false
+}
+
+// captureOutput captures command output in real-time
+func (cs *CommandStrategy) captureOutput(pipe io.ReadCloser, streamType string, secrets []string, result *BackupResult, mu *sync.Mutex, callback ProgressCallback) {
+	defer pipe.Close()
+
+	scanner := bufio.NewScanner(pipe)
+	var outputBuffer strings.Builder
+
+	for scanner.Scan() {
+		line := cs.sanitize(scanner.Text(), secrets)
+		outputBuffer.WriteString(line)
+		outputBuffer.WriteString("\n")
+
+		if cs.containsError(line) && callback != nil {
+			callback("command", fmt.Sprintf("❌ %s ERROR: %s", strings.ToUpper(cs.options.Command), line))
+		}
+	}
+
+	// Store the complete output in command logs
+	if outputBuffer.Len() > 0 {
+		mu.Lock()
+		result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Output (%s): %s", streamType, outputBuffer.String()))
+		mu.Unlock()
+	}
+}
+
+// containsError checks if the output matches one of the configured error patterns
+func (cs *CommandStrategy) containsError(output string) bool {
+	for _, pattern := range cs.errorPatterns {
+		if pattern.MatchString(output) {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitize masks secret values in logged command lines and output
+func (cs *CommandStrategy) sanitize(text string, secrets []string) string {
+	for _, secret := range secrets {
+		text = strings.ReplaceAll(text, secret, "***")
+	}
+	return text
+}
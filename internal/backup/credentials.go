@@ -0,0 +1,121 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Credentials are handed to client tools through temporary files readable only by the
+// service user, so that they do not show up in process listings or /proc/<pid>/cmdline.
+
+// writeCredentialFile writes content to a new temporary file with 0600 permissions and
+// returns its path together with a function removing it
+func writeCredentialFile(pattern, content string) (string, func(), error) {
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create credentials file: %w", err)
+	}
+	cleanup := func() { os.Remove(file.Name()) }
+
+	// CreateTemp already uses 0600, make sure a permissive umask cannot widen it
+	if err := file.Chmod(0600); err != nil {
+		file.Close()
+		cleanup()
+		return "", nil, fmt.Errorf("failed to protect credentials file: %w", err)
+	}
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write credentials file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write credentials file: %w", err)
+	}
+	return file.Name(), cleanup, nil
+}
+
+// noCleanup is returned when no credentials file was needed
+func noCleanup() {}
+
+// postgresCredentials removes the password from a PostgreSQL URL and returns the URL
+// together with the environment pointing PGPASSFILE at a temporary password file
+func postgresCredentials(databaseURL string) (string, []string, func(), error) {
+	parsed, err := url.Parse(databaseURL)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("invalid PostgreSQL URL: %w", err)
+	}
+
+	password := ""
+	if parsed.User != nil {
+		password, _ = parsed.User.Password()
+		parsed.User = url.User(parsed.User.Username())
+	}
+	query := parsed.Query()
+	if queryPassword := query.Get("password"); queryPassword != "" {
+		password = queryPassword
+		query.Del("password")
+		parsed.RawQuery = query.Encode()
+	}
+	if password == "" {
+		return databaseURL, os.Environ(), noCleanup, nil
+	}
+
+	// The password file matches any host, port, database and user
+	escaped := strings.NewReplacer(`\`, `\\`, `:`, `\:`).Replace(password)
+	path, cleanup, err := writeCredentialFile("easy-backup-pgpass-", "*:*:*:*:"+escaped+"\n")
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return parsed.String(), append(os.Environ(), "PGPASSFILE="+path), cleanup, nil
+}
+
+// mysqlCredentialArgs writes the password to a temporary option file and returns args
+// prefixed with --defaults-extra-file, which the client tools require as first argument
+func mysqlCredentialArgs(params *ConnectionParams, args []string) ([]string, func(), error) {
+	if params.Password == "" {
+		return args, noCleanup, nil
+	}
+
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(params.Password)
+	path, cleanup, err := writeCredentialFile("easy-backup-my-", "[client]\npassword=\""+escaped+"\"\n")
+	if err != nil {
+		return nil, nil, err
+	}
+	return append([]string{"--defaults-extra-file=" + path}, args...), cleanup, nil
+}
+
+// mongoCredentialArgs returns the connection argument of the MongoDB database tools. URLs
+// with a password are passed through a temporary --config file instead of --uri.
+func mongoCredentialArgs(databaseURL string) ([]string, func(), error) {
+	if !mongoURIHasPassword(databaseURL) {
+		return []string{"--uri=" + databaseURL}, noCleanup, nil
+	}
+
+	// JSON strings are valid YAML scalars
+	uri, err := json.Marshal(databaseURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode MongoDB URI: %w", err)
+	}
+	path, cleanup, err := writeCredentialFile("easy-backup-mongo-", "uri: "+string(uri)+"\n")
+	if err != nil {
+		return nil, nil, err
+	}
+	return []string{"--config=" + path}, cleanup, nil
+}
+
+// mongoURIHasPassword reports whether a MongoDB URL contains a password. Multi-host URLs
+// are not valid for net/url, so the user info is located by hand.
+func mongoURIHasPassword(databaseURL string) bool {
+	_, rest, found := strings.Cut(databaseURL, "://")
+	if !found {
+		return false
+	}
+	// Credentials may contain '/', so the user info ends at the last '@' before the options
+	rest, _, _ = strings.Cut(rest, "?")
+	at := strings.LastIndex(rest, "@")
+	return at != -1 && strings.Contains(rest[:at], ":")
+}
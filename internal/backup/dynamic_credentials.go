@@ -0,0 +1,168 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"easy-backup/internal/config"
+	"easy-backup/internal/vault"
+)
+
+// defaultCredentialsTimeout bounds fetching and revoking dynamic credentials
+const defaultCredentialsTimeout = 30 * time.Second
+
+// dynamicCredentials are short-lived database credentials obtained for a single run
+type dynamicCredentials struct {
+	Username string                          `json:"username"`
+	Password string                          `json:"password"`
+	source   string                          // Vault path or helper command, for logs
+	ttl      time.Duration                   // Lease duration, zero when unknown
+	revoke   func(ctx context.Context) error // Revokes the lease, nil when there is none
+}
+
+// acquireCredentials fetches the dynamic credentials of a strategy and returns the strategy
+// config with them injected into its database URLs. The returned function revokes the
+// lease; it may be called several times and only revokes once.
+func (bs *BackupService) acquireCredentials(ctx context.Context, strategyConfig config.StrategyConfig, result *BackupResult) (config.StrategyConfig, func(), error) {
+	settings := strategyConfig.DynamicCredentials
+	if !settings.Enabled() {
+		return strategyConfig, func() {}, nil
+	}
+
+	timeout := defaultCredentialsTimeout
+	if parsed, err := time.ParseDuration(settings.Timeout); err == nil && parsed > 0 {
+		timeout = parsed
+	}
+	fetchCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var credentials *dynamicCredentials
+	var err error
+	if settings.VaultPath != "" {
+		credentials, err = fetchVaultCredentials(fetchCtx, settings.VaultPath)
+	} else {
+		credentials, err = fetchHelperCredentials(fetchCtx, settings, strategyConfig.Name)
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to fetch dynamic credentials: %w", err)
+		result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Error: %s", err.Error()))
+		return strategyConfig, nil, err
+	}
+
+	released := false
+	release := func() {
+		if released || credentials.revoke == nil {
+			return
+		}
+		released = true
+
+		revokeCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := credentials.revoke(revokeCtx); err != nil {
+			bs.logger.WithError(err).WithFields(logrus.Fields{
+				"strategy": strategyConfig.Name,
+			}).Warn("Failed to revoke dynamic credentials")
+			result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Warning: %s", err.Error()))
+			return
+		}
+		result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Revoked dynamic credentials of user %s", credentials.Username))
+	}
+
+	configured := strategyConfig
+	if configured.DatabaseURL, err = injectCredentials(strategyConfig.DatabaseURL, credentials); err == nil && strategyConfig.FallbackDatabaseURL != "" {
+		configured.FallbackDatabaseURL, err = injectCredentials(strategyConfig.FallbackDatabaseURL, credentials)
+	}
+	if err != nil {
+		release()
+		result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Error: %s", err.Error()))
+		return strategyConfig, nil, err
+	}
+
+	logEntry := fmt.Sprintf("Using dynamic credentials of user %s from %s", credentials.Username, credentials.source)
+	if credentials.ttl > 0 {
+		logEntry += fmt.Sprintf(" (lease %s)", credentials.ttl)
+	}
+	result.CommandLogs = append(result.CommandLogs, logEntry)
+	return configured, release, nil
+}
+
+// fetchVaultCredentials reads credentials from a Vault database secrets engine role
+func fetchVaultCredentials(ctx context.Context, path string) (*dynamicCredentials, error) {
+	client, err := vault.NewClientFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := client.Read(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	username, _ := secret.Data["username"].(string)
+	password, _ := secret.Data["password"].(string)
+	if username == "" || password == "" {
+		if secret.LeaseID != "" {
+			client.RevokeLease(ctx, secret.LeaseID)
+		}
+		return nil, fmt.Errorf("%s did not return a username and password", path)
+	}
+
+	credentials := &dynamicCredentials{
+		Username: username,
+		Password: password,
+		source:   "vault:" + path,
+		ttl:      time.Duration(secret.LeaseDuration) * time.Second,
+	}
+	if secret.LeaseID != "" {
+		leaseID := secret.LeaseID
+		credentials.revoke = func(ctx context.Context) error {
+			return client.RevokeLease(ctx, leaseID)
+		}
+	}
+	return credentials, nil
+}
+
+// fetchHelperCredentials runs a credential helper printing a JSON object with a
+// username and password
+func fetchHelperCredentials(ctx context.Context, settings config.DynamicCredentialsConfig, strategyName string) (*dynamicCredentials, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, settings.Command, settings.Args...)
+	cmd.Env = append(os.Environ(), "EASY_BACKUP_STRATEGY="+strategyName)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s failed: %w: %s", settings.Command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var credentials dynamicCredentials
+	if err := json.Unmarshal(stdout.Bytes(), &credentials); err != nil {
+		return nil, fmt.Errorf("%s printed invalid JSON: %w", settings.Command, err)
+	}
+	if credentials.Username == "" || credentials.Password == "" {
+		return nil, fmt.Errorf("%s did not print a username and password", settings.Command)
+	}
+	credentials.source = settings.Command
+	return &credentials, nil
+}
+
+// injectCredentials replaces the user info of a database URL. Multi-host MongoDB URLs are
+// not valid for net/url, so the user info is located by hand.
+func injectCredentials(databaseURL string, credentials *dynamicCredentials) (string, error) {
+	scheme, rest, found := strings.Cut(databaseURL, "://")
+	if !found {
+		return "", fmt.Errorf("dynamic credentials require a database URL of the form scheme://host")
+	}
+	authority, _, _ := strings.Cut(rest, "?")
+	if at := strings.LastIndex(authority, "@"); at != -1 {
+		rest = rest[at+1:]
+	}
+	return scheme + "://" + url.UserPassword(credentials.Username, credentials.Password).String() + "@" + rest, nil
+}
@@ -0,0 +1,254 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"easy-backup/internal/config"
+)
+
+// EtcdStrategy implements DatabaseStrategy for etcd v3 snapshots
+type EtcdStrategy struct {
+	logger  *logrus.Logger
+	options config.EtcdConfig
+}
+
+// etcdSnapshotStatus is the JSON output of "snapshot status"
+type etcdSnapshotStatus struct {
+	Hash      uint32 `json:"hash"`
+	Revision  int64  `json:"revision"`
+	TotalKey  int    `json:"totalKey"`
+	TotalSize int64  `json:"totalSize"`
+	Version   string `json:"version"`
+}
+
+// NewEtcdStrategy creates a new etcd snapshot strategy
+func NewEtcdStrategy(logger *logrus.Logger) *EtcdStrategy {
+	return &EtcdStrategy{logger: logger}
+}
+
+// GetType returns the database type
+func (es *EtcdStrategy) GetType() string {
+	return "etcd"
+}
+
+// WithConfig returns a copy of the strategy using the etcd settings of the strategy
+func (es *EtcdStrategy) WithConfig(strategyConfig config.StrategyConfig) DatabaseStrategy {
+	configured := *es
+	configured.options = strategyConfig.Etcd
+	return &configured
+}
+
+// ValidateConnection checks that endpoints are configured. etcd strategies
+// do not use the database URL.
+func (es *EtcdStrategy) ValidateConnection(databaseURL string) error {
+	if len(es.options.Endpoints) == 0 {
+		return fmt.Errorf("no etcd endpoints configured")
+	}
+	for _, endpoint := range es.options.Endpoints {
+		if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+			return fmt.Errorf("invalid etcd endpoint '%s', expected http:// or https://", endpoint)
+		}
+	}
+	return nil
+}
+
+// Preflight checks that etcdctl is installed and that an endpoint reports itself healthy.
+// Snapshots are taken from the first endpoint that succeeds, so one healthy endpoint is enough.
+func (es *EtcdStrategy) Preflight(ctx context.Context, databaseURL string) error {
+	if err := requireTools("etcdctl"); err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, endpoint := range es.options.Endpoints {
+		cmd := exec.CommandContext(ctx, "etcdctl", append(es.connectionArgs(endpoint), "endpoint", "health")...)
+		cmd.Env = append(os.Environ(), "ETCDCTL_API=3")
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			return nil
+		}
+		lastErr = fmt.Errorf("%s: %w: %s", endpoint, err, strings.TrimSpace(string(output)))
+	}
+	if lastErr == nil {
+		return fmt.Errorf("no etcd endpoints configured")
+	}
+	return preflightError(classifyConnectionError(lastErr.Error()), "no healthy etcd endpoint: %s", lastErr.Error())
+}
+
+// Backup saves a snapshot through the maintenance API of the first endpoint that
+// succeeds, verifies its integrity hash and reads its revision
+func (es *EtcdStrategy) Backup(ctx context.Context, databaseURL, outputPath string, callback ProgressCallback) (*BackupResult, error) {
+	result := &BackupResult{
+		CommandLogs: make([]string, 0),
+	}
+
+	if callback != nil {
+		callback("etcd", "Starting etcd snapshot...")
+	}
+
+	// etcdctl only takes snapshots from a single endpoint
+	var endpoint string
+	var lastErr error
+	for _, candidate := range es.options.Endpoints {
+		if lastErr = es.saveSnapshot(ctx, candidate, outputPath, result); lastErr == nil {
+			endpoint = candidate
+			break
+		}
+		if callback != nil {
+			callback("etcd", fmt.Sprintf("⚠️ Snapshot from %s failed: %s", candidate, lastErr.Error()))
+		}
+	}
+	if endpoint == "" {
+		if callback != nil {
+			callback("etcd", fmt.Sprintf("❌ etcd snapshot failed on all endpoints: %s", lastErr.Error()))
+		}
+		return result, fmt.Errorf("etcd snapshot failed on all endpoints: %w", lastErr)
+	}
+
+	if callback != nil {
+		callback("etcd", fmt.Sprintf("Snapshot saved from %s, verifying...", endpoint))
+	}
+
+	if err := verifySnapshotHash(outputPath); err != nil {
+		result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Error: %s", err.Error()))
+		if callback != nil {
+			callback("etcd", fmt.Sprintf("❌ %s", err.Error()))
+		}
+		return result, err
+	}
+
+	status, err := es.snapshotStatus(ctx, outputPath, result)
+	if err != nil {
+		if callback != nil {
+			callback("etcd", fmt.Sprintf("❌ Failed to read snapshot status: %s", err.Error()))
+		}
+		return result, err
+	}
+	if status.Revision <= 0 {
+		errorMsg := fmt.Sprintf("snapshot has invalid revision %d", status.Revision)
+		result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Error: %s", errorMsg))
+		return result, fmt.Errorf("%s", errorMsg)
+	}
+
+	result.BackupPath = outputPath
+	result.Details = []ResultDetail{
+		{Label: "Revision", Value: strconv.FormatInt(status.Revision, 10)},
+		{Label: "Keys", Value: strconv.Itoa(status.TotalKey)},
+		{Label: "DB size", Value: formatBytes(status.TotalSize)},
+		{Label: "Endpoint", Value: endpoint},
+	}
+	if callback != nil {
+		callback("etcd", fmt.Sprintf("etcd snapshot completed successfully (revision %d, %d keys, %s)", status.Revision, status.TotalKey, formatBytes(status.TotalSize)))
+	}
+
+	return result, nil
+}
+
+// saveSnapshot runs etcdctl snapshot save against a single endpoint
+func (es *EtcdStrategy) saveSnapshot(ctx context.Context, endpoint, outputPath string, result *BackupResult) error {
+	args := append(es.connectionArgs(endpoint), "snapshot", "save", outputPath)
+
+	cmd := exec.CommandContext(ctx, "etcdctl", args...)
+	cmd.Env = append(os.Environ(), "ETCDCTL_API=3")
+
+	commandLog := fmt.Sprintf("Command: etcdctl %s", strings.Join(args, " "))
+	result.CommandLogs = append(result.CommandLogs, commandLog)
+
+	output, err := cmd.CombinedOutput()
+	if trimmed := strings.TrimSpace(string(output)); trimmed != "" {
+		result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Output: %s", trimmed))
+	}
+	if err != nil {
+		os.Remove(outputPath)
+		return fmt.Errorf("etcdctl snapshot save failed: %w", err)
+	}
+	return nil
+}
+
+// connectionArgs returns the etcdctl flags selecting the endpoint and TLS files
+func (es *EtcdStrategy) connectionArgs(endpoint string) []string {
+	args := []string{"--endpoints=" + endpoint}
+	if es.options.CACert != "" {
+		args = append(args, "--cacert="+es.options.CACert)
+	}
+	if es.options.Cert != "" {
+		args = append(args, "--cert="+es.options.Cert, "--key="+es.options.Key)
+	}
+	return args
+}
+
+// snapshotStatus reads the revision and size of a snapshot file. etcdutl is used when
+// installed, older etcdctl releases provide the same command.
+func (es *EtcdStrategy) snapshotStatus(ctx context.Context, snapshotPath string, result *BackupResult) (*etcdSnapshotStatus, error) {
+	tool := "etcdutl"
+	if _, err := exec.LookPath(tool); err != nil {
+		tool = "etcdctl"
+	}
+	args := []string{"snapshot", "status", snapshotPath, "--write-out=json"}
+
+	cmd := exec.CommandContext(ctx, tool, args...)
+	cmd.Env = append(os.Environ(), "ETCDCTL_API=3")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Command: %s %s", tool, strings.Join(args, " ")))
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s snapshot status failed: %w, output: %s", tool, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return parseSnapshotStatus(stdout.Bytes())
+}
+
+// parseSnapshotStatus parses the JSON output of "snapshot status"
+func parseSnapshotStatus(output []byte) (*etcdSnapshotStatus, error) {
+	var status etcdSnapshotStatus
+	if err := json.Unmarshal(bytes.TrimSpace(output), &status); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot status: %w", err)
+	}
+	return &status, nil
+}
+
+// verifySnapshotHash checks the SHA-256 checksum etcd appends to snapshots sent
+// through the maintenance API
+func verifySnapshotHash(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("snapshot was not created: %w", err)
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat snapshot: %w", err)
+	}
+	// The database is a multiple of 512 bytes followed by the checksum
+	size := fileInfo.Size()
+	if size < sha256.Size || size%512 != sha256.Size {
+		return fmt.Errorf("snapshot does not contain an integrity hash (size %d)", size)
+	}
+
+	hash := sha256.New()
+	if _, err := io.CopyN(hash, file, size-sha256.Size); err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	expected := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(file, expected); err != nil {
+		return fmt.Errorf("failed to read snapshot hash: %w", err)
+	}
+	if !bytes.Equal(hash.Sum(nil), expected) {
+		return fmt.Errorf("snapshot integrity hash mismatch")
+	}
+	return nil
+}
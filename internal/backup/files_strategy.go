@@ -0,0 +1,221 @@
+package backup
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"easy-backup/internal/config"
+)
+
+// FilesStrategy implements DatabaseStrategy for files and directory trees
+type FilesStrategy struct {
+	logger  *logrus.Logger
+	options config.FilesConfig
+}
+
+// NewFilesStrategy creates a new filesystem backup strategy
+func NewFilesStrategy(logger *logrus.Logger) *FilesStrategy {
+	return &FilesStrategy{logger: logger}
+}
+
+// GetType returns the database type
+func (fs *FilesStrategy) GetType() string {
+	return "files"
+}
+
+// WithConfig returns a copy of the strategy using the files settings of the strategy
+func (fs *FilesStrategy) WithConfig(strategyConfig config.StrategyConfig) DatabaseStrategy {
+	configured := *fs
+	configured.options = strategyConfig.Files
+	return &configured
+}
+
+// ValidateConnection checks that the configured paths exist. Files strategies
+// do not use the database URL.
+func (fs *FilesStrategy) ValidateConnection(databaseURL string) error {
+	if len(fs.options.Paths) == 0 {
+		return fmt.Errorf("no paths configured for files backup")
+	}
+	for _, root := range fs.options.Paths {
+		if _, err := os.Stat(root); err != nil {
+			return fmt.Errorf("backup path not accessible: %w", err)
+		}
+	}
+	return nil
+}
+
+// Backup archives the configured paths into a tar file at outputPath. Entries are
+// stored under their absolute path without the leading slash.
+func (fs *FilesStrategy) Backup(ctx context.Context, databaseURL, outputPath string, callback ProgressCallback) (*BackupResult, error) {
+	result := &BackupResult{
+		CommandLogs: make([]string, 0),
+	}
+
+	if callback != nil {
+		callback("files", fmt.Sprintf("Starting files backup of %s...", strings.Join(fs.options.Paths, ", ")))
+	}
+
+	output, err := os.Create(outputPath)
+	if err != nil {
+		return result, fmt.Errorf("failed to create backup file: %w", err)
+	}
+	defer output.Close()
+
+	tarWriter := tar.NewWriter(output)
+	progress := &filesProgress{}
+
+	for _, root := range fs.options.Paths {
+		if err := fs.archiveRoot(ctx, tarWriter, root, progress, callback); err != nil {
+			if callback != nil {
+				callback("files", fmt.Sprintf("❌ Files backup failed: %s", err.Error()))
+			}
+			return result, err
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return result, fmt.Errorf("failed to finish tar archive: %w", err)
+	}
+	if err := output.Close(); err != nil {
+		return result, fmt.Errorf("failed to write backup file: %w", err)
+	}
+
+	summary := fmt.Sprintf("Archived %d files (%s) from %d paths", progress.files, formatBytes(progress.bytes), len(fs.options.Paths))
+	result.CommandLogs = append(result.CommandLogs, summary)
+	result.BackupPath = outputPath
+	if callback != nil {
+		callback("files", summary)
+	}
+
+	return result, nil
+}
+
+// archiveRoot adds a single configured path to the archive
+func (fs *FilesStrategy) archiveRoot(ctx context.Context, tarWriter *tar.Writer, root string, progress *filesProgress, callback ProgressCallback) error {
+	// A configured path may itself be a symlink, archive what it points to
+	walkRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", root, err)
+	}
+	archiveRoot := strings.TrimPrefix(filepath.ToSlash(filepath.Clean(root)), "/")
+
+	return filepath.Walk(walkRoot, func(filePath string, info os.FileInfo, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			// Files removed while the tree is walked are skipped
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		relPath, err := filepath.Rel(walkRoot, filePath)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		name := archiveRoot
+		if relPath != "." {
+			name = path.Join(archiveRoot, relPath)
+			if fs.excluded(relPath) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+
+		if info.IsDir() {
+			// With include patterns only matching files and their parents are relevant
+			if len(fs.options.Include) > 0 {
+				return nil
+			}
+			return addTarEntry(tarWriter, filePath, name, info)
+		}
+		if !fs.included(relPath) {
+			return nil
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 && fs.options.FollowSymlinks {
+			// Links to directories and dangling links are kept as links
+			if targetInfo, err := os.Stat(filePath); err == nil && targetInfo.Mode().IsRegular() {
+				info = targetInfo
+			}
+		}
+
+		if err := addTarEntry(tarWriter, filePath, name, info); err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return fmt.Errorf("failed to archive %s: %w", filePath, err)
+		}
+
+		if message, report := progress.add(info, time.Now()); report && callback != nil {
+			callback("files", message)
+		}
+		return nil
+	})
+}
+
+// excluded reports whether a path relative to a configured root matches an exclude pattern
+func (fs *FilesStrategy) excluded(relPath string) bool {
+	return matchesFilePattern(fs.options.Exclude, relPath)
+}
+
+// included reports whether a file is selected by the include patterns
+func (fs *FilesStrategy) included(relPath string) bool {
+	if len(fs.options.Include) == 0 {
+		return true
+	}
+	// A configured path that is a single file is always included
+	if relPath == "." {
+		return true
+	}
+	return matchesFilePattern(fs.options.Include, relPath)
+}
+
+// matchesFilePattern matches glob patterns against the relative path and the base name,
+// so that "*.log" matches log files in any directory and "cache/*" a specific directory
+func matchesFilePattern(patterns []string, relPath string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, relPath); matched {
+			return true
+		}
+		if matched, _ := path.Match(pattern, path.Base(relPath)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// filesProgress tracks the number of files and bytes archived
+type filesProgress struct {
+	files      int
+	bytes      int64
+	lastReport time.Time
+}
+
+// add records an archived entry and returns a progress message when one should be reported
+func (fp *filesProgress) add(info os.FileInfo, now time.Time) (string, bool) {
+	fp.files++
+	if info.Mode().IsRegular() {
+		fp.bytes += info.Size()
+	}
+
+	if now.Sub(fp.lastReport) < progressReportInterval {
+		return "", false
+	}
+	fp.lastReport = now
+	return fmt.Sprintf("Archived %d files (%s)...", fp.files, formatBytes(fp.bytes)), true
+}
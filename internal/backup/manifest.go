@@ -0,0 +1,178 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ManifestSuffix is appended to the backup name to form the manifest file name
+const ManifestSuffix = ".manifest.json"
+
+// Manifest kinds
+const (
+	ManifestKindFull        = "full"
+	ManifestKindIncremental = "incremental"
+)
+
+// BinlogPosition identifies a position in the MySQL binary log
+type BinlogPosition struct {
+	File     string `json:"file"`
+	Position uint64 `json:"position"`
+}
+
+// String returns the position in file:position format
+func (bp BinlogPosition) String() string {
+	return fmt.Sprintf("%s:%d", bp.File, bp.Position)
+}
+
+// Manifest describes a backup run and is stored next to its artifacts
+type Manifest struct {
+	Name         string          `json:"name"`
+	Strategy     string          `json:"strategy"`
+	DatabaseType string          `json:"database_type"`
+	Kind         string          `json:"kind"`
+	CreatedAt    time.Time       `json:"created_at"`
+	Duration     string          `json:"duration"`
+	Size         int64           `json:"size"`
+	Artifacts    []string        `json:"artifacts"`
+	BaseBackup   string          `json:"base_backup,omitempty"`
+	BinlogStart  *BinlogPosition `json:"binlog_start,omitempty"`
+	BinlogEnd    *BinlogPosition `json:"binlog_end,omitempty"`
+	Details      []ResultDetail  `json:"details,omitempty"`
+
+	ReplicationLagSeconds *float64 `json:"replication_lag_seconds,omitempty"`
+	UsedFallback          bool     `json:"used_fallback,omitempty"` // Taken from the fallback URL instead of the replica
+	ServerVersion         string   `json:"server_version,omitempty"`
+	ToolVersion           string   `json:"tool_version,omitempty"`
+}
+
+// NewManifest builds the manifest of a finalized backup result
+func NewManifest(name, databaseType string, result *BackupResult) *Manifest {
+	manifest := &Manifest{
+		Name:          name,
+		Strategy:      result.Strategy,
+		DatabaseType:  databaseType,
+		Kind:          ManifestKindFull,
+		CreatedAt:     result.StartTime.UTC(),
+		Duration:      result.Duration.String(),
+		Size:          result.Size,
+		BaseBackup:    result.BaseBackup,
+		BinlogStart:   result.BinlogStart,
+		BinlogEnd:     result.BinlogEnd,
+		Details:       result.Details,
+		UsedFallback:  result.UsedFallback,
+		ServerVersion: result.ServerVersion,
+		ToolVersion:   result.ToolVersion,
+	}
+	if result.ReplicationLag != nil {
+		lag := result.ReplicationLag.Seconds()
+		manifest.ReplicationLagSeconds = &lag
+	}
+	if result.Incremental {
+		manifest.Kind = ManifestKindIncremental
+	} else {
+		manifest.BaseBackup = name
+	}
+
+	for _, artifactPath := range result.ArtifactPaths() {
+		manifest.Artifacts = append(manifest.Artifacts, filepath.Base(artifactPath))
+	}
+
+	return manifest
+}
+
+// Write stores the manifest as JSON at the given path
+func (m *Manifest) Write(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// ReadManifest loads a manifest from a JSON file
+func ReadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", filepath.Base(path), err)
+	}
+	return &manifest, nil
+}
+
+// IsManifestKey reports whether an object key or file name refers to a manifest
+func IsManifestKey(key string) bool {
+	return strings.HasSuffix(key, ManifestSuffix)
+}
+
+// RestoreChainObjects returns the names of the stored objects of the latest full backup and
+// of the incremental backups based on it: their manifests and artifacts. Retention must keep
+// them, or the latest backup could no longer be restored.
+func RestoreChainObjects(manifests []*Manifest) []string {
+	var full *Manifest
+	for _, manifest := range manifests {
+		if manifest.Kind == ManifestKindFull && (full == nil || manifest.CreatedAt.After(full.CreatedAt)) {
+			full = manifest
+		}
+	}
+	if full == nil {
+		return nil
+	}
+
+	var names []string
+	for _, manifest := range manifests {
+		if manifest == full || (manifest.Kind == ManifestKindIncremental && manifest.BaseBackup == full.Name) {
+			names = append(names, manifest.Name+ManifestSuffix)
+			names = append(names, manifest.Artifacts...)
+		}
+	}
+	return names
+}
+
+// BuildRestoreChain returns the latest full backup followed by the incremental
+// backups chained to it, in the order they have to be restored
+func BuildRestoreChain(manifests []*Manifest) ([]*Manifest, error) {
+	var full *Manifest
+	for _, manifest := range manifests {
+		if manifest.Kind == ManifestKindFull && (full == nil || manifest.CreatedAt.After(full.CreatedAt)) {
+			full = manifest
+		}
+	}
+	if full == nil {
+		return nil, fmt.Errorf("no full backup manifest found")
+	}
+
+	chain := []*Manifest{full}
+	var increments []*Manifest
+	for _, manifest := range manifests {
+		if manifest.Kind == ManifestKindIncremental && manifest.BaseBackup == full.Name {
+			increments = append(increments, manifest)
+		}
+	}
+	sort.Slice(increments, func(i, j int) bool {
+		return increments[i].CreatedAt.Before(increments[j].CreatedAt)
+	})
+
+	previous := full
+	for _, increment := range increments {
+		if previous.BinlogEnd == nil || increment.BinlogStart == nil || *previous.BinlogEnd != *increment.BinlogStart {
+			return nil, fmt.Errorf("incremental backup %s does not continue from %s", increment.Name, previous.Name)
+		}
+		chain = append(chain, increment)
+		previous = increment
+	}
+
+	return chain, nil
+}
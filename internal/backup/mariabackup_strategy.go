@@ -0,0 +1,429 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"easy-backup/internal/config"
+)
+
+// progressReportInterval limits how often streaming progress is reported
+const progressReportInterval = 10 * time.Second
+
+// MariabackupStrategy implements DatabaseStrategy for physical MySQL/MariaDB backups
+// taken with mariabackup or Percona xtrabackup in xbstream format
+type MariabackupStrategy struct {
+	logger  *logrus.Logger
+	tool    string // mariabackup or xtrabackup
+	options config.PhysicalConfig
+	tools   clientTools // mariadb client used by the preflight checks
+}
+
+// NewMariabackupStrategy creates a new physical backup strategy using the given tool
+func NewMariabackupStrategy(logger *logrus.Logger, tool string) *MariabackupStrategy {
+	return &MariabackupStrategy{logger: logger, tool: tool}
+}
+
+// GetType returns the database type
+func (mb *MariabackupStrategy) GetType() string {
+	return mb.tool
+}
+
+// WithConfig returns a copy of the strategy using the physical backup settings of the strategy
+func (mb *MariabackupStrategy) WithConfig(strategyConfig config.StrategyConfig) DatabaseStrategy {
+	configured := *mb
+	configured.options = strategyConfig.Physical
+	configured.tools = newClientTools(strategyConfig.ToolPaths)
+	return &configured
+}
+
+// ValidateConnection validates the MySQL connection
+func (mb *MariabackupStrategy) ValidateConnection(databaseURL string) error {
+	if !strings.HasPrefix(databaseURL, "mysql://") {
+		return fmt.Errorf("invalid MySQL URL format")
+	}
+	return nil
+}
+
+// physicalBackupPrivileges are the global privileges the backup tools need to lock the
+// server and read the redo log position
+var physicalBackupPrivileges = []string{"RELOAD", "PROCESS", "LOCK TABLES", "REPLICATION CLIENT"}
+
+// Preflight checks that the backup tool and the mariadb client are installed, that the user
+// can connect and that it has the global privileges needed for a physical backup. The client
+// is picked from the tool_paths of the strategy like for logical MySQL backups.
+func (mb *MariabackupStrategy) Preflight(ctx context.Context, databaseURL string) error {
+	client := &MySQLStrategy{logger: mb.logger, tools: mb.tools}
+	if err := requireTools(mb.tool, client.tools.path("mariadb")); err != nil {
+		return err
+	}
+
+	params, err := parseMySQLURL(databaseURL, false)
+	if err != nil {
+		return fmt.Errorf("invalid MySQL connection URL: %w", err)
+	}
+	serverVersion, err := client.serverVersion(ctx, params)
+	if err != nil {
+		return err
+	}
+	serverMajor, err := parseMajorVersion(serverVersion)
+	if err != nil {
+		return err
+	}
+	client.tools = mb.tools.forServer(serverMajor)
+
+	// The connection works at this point, so failures are missing privileges
+	grants, err := client.query(ctx, params, "SHOW GRANTS")
+	if err != nil {
+		return preflightError(PreflightPrivileges, "cannot read the grants of %s: %s", params.User, err.Error())
+	}
+	if missing := missingPrivileges(grants, physicalBackupPrivileges); len(missing) > 0 {
+		return preflightError(PreflightPrivileges, "user %s lacks the %s privileges needed by %s", params.User, strings.Join(missing, ", "), mb.tool)
+	}
+	return nil
+}
+
+// missingPrivileges returns the required global privileges that the SHOW GRANTS output does
+// not grant. Privileges granted through roles cannot be read there, so nothing is reported
+// missing for users with roles.
+func missingPrivileges(grants string, required []string) []string {
+	granted := map[string]bool{}
+	for _, line := range strings.Split(grants, "\n") {
+		line = strings.ToUpper(strings.TrimSpace(line))
+		if !strings.HasPrefix(line, "GRANT ") {
+			continue
+		}
+		on := strings.Index(line, " ON ")
+		if on == -1 {
+			return nil // A role is granted to the user
+		}
+		if !strings.HasPrefix(line[on+len(" ON "):], "*.*") {
+			continue
+		}
+		for _, privilege := range strings.Split(line[len("GRANT "):on], ",") {
+			granted[strings.TrimSpace(privilege)] = true
+		}
+	}
+	if granted["ALL PRIVILEGES"] || granted["ALL"] {
+		return nil
+	}
+	// MariaDB 10.5 renamed REPLICATION CLIENT to BINLOG MONITOR
+	if granted["BINLOG MONITOR"] {
+		granted["REPLICATION CLIENT"] = true
+	}
+
+	var missing []string
+	for _, privilege := range required {
+		if !granted[privilege] {
+			missing = append(missing, privilege)
+		}
+	}
+	return missing
+}
+
+// Backup streams a physical backup in xbstream format into outputPath
+func (mb *MariabackupStrategy) Backup(ctx context.Context, databaseURL, outputPath string, callback ProgressCallback) (*BackupResult, error) {
+	result := &BackupResult{
+		CommandLogs: make([]string, 0),
+	}
+
+	if callback != nil {
+		callback(mb.tool, fmt.Sprintf("Starting physical backup with %s...", mb.tool))
+	}
+
+	params, err := parseMySQLURL(databaseURL, false)
+	if err != nil {
+		if callback != nil {
+			callback(mb.tool, fmt.Sprintf("❌ Invalid connection URL: %s", err.Error()))
+		}
+		return result, fmt.Errorf("invalid MySQL connection URL: %w", err)
+	}
+
+	// The target directory only holds metadata files when streaming
+	targetDir, err := os.MkdirTemp(filepath.Dir(outputPath), mb.tool+"-")
+	if err != nil {
+		return result, fmt.Errorf("failed to create target directory: %w", err)
+	}
+	defer os.RemoveAll(targetDir)
+
+	args := []string{
+		"--backup",
+		"--stream=xbstream",
+		"--host=" + params.Host,
+		"--port=" + params.Port,
+		"--user=" + params.User,
+		"--target-dir=" + targetDir,
+	}
+	if mb.options.DataDir != "" {
+		args = append(args, "--datadir="+mb.options.DataDir)
+	}
+	if mb.options.Parallel > 0 {
+		args = append(args, fmt.Sprintf("--parallel=%d", mb.options.Parallel))
+	}
+	args, cleanup, err := mysqlCredentialArgs(params, args)
+	if err != nil {
+		return result, err
+	}
+	defer cleanup()
+
+	output, err := os.Create(outputPath)
+	if err != nil {
+		return result, fmt.Errorf("failed to create backup file: %w", err)
+	}
+	defer output.Close()
+
+	cmd := exec.CommandContext(ctx, mb.tool, args...)
+	cmd.Stdout = output
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return result, fmt.Errorf("failed to get stderr pipe: %w", err)
+	}
+
+	// Start the command
+	if err := cmd.Start(); err != nil {
+		commandLog := fmt.Sprintf("Command failed to start: %s %s - Error: %s", mb.tool, strings.Join(mb.sanitizeArgs(args), " "), err.Error())
+		result.CommandLogs = append(result.CommandLogs, commandLog)
+		if callback != nil {
+			callback(mb.tool, fmt.Sprintf("❌ Command failed to start: %s", err.Error()))
+		}
+		return result, fmt.Errorf("%s failed to start: %w", mb.tool, err)
+	}
+
+	// Log the command execution
+	commandLog := fmt.Sprintf("Command: %s %s", mb.tool, strings.Join(mb.sanitizeArgs(args), " "))
+	result.CommandLogs = append(result.CommandLogs, commandLog)
+
+	// Parse progress from stderr while the stream is written to the output file
+	progress := newStreamProgress(outputPath)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		mb.captureProgress(stderr, progress, result, callback)
+	}()
+
+	waitErr := cmd.Wait()
+	wg.Wait()
+
+	if waitErr != nil {
+		errorLog := fmt.Sprintf("Command failed: %s %s - Error: %s", mb.tool, strings.Join(mb.sanitizeArgs(args), " "), waitErr.Error())
+		result.CommandLogs = append(result.CommandLogs, errorLog)
+		if callback != nil {
+			callback(mb.tool, fmt.Sprintf("❌ Physical backup failed: %s", waitErr.Error()))
+		}
+		return result, fmt.Errorf("%s failed: %w", mb.tool, waitErr)
+	}
+
+	if !progress.completed {
+		errorMsg := fmt.Sprintf("%s did not report successful completion", mb.tool)
+		result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Error: %s", errorMsg))
+		if callback != nil {
+			callback(mb.tool, fmt.Sprintf("❌ %s", errorMsg))
+		}
+		return result, fmt.Errorf("%s", errorMsg)
+	}
+
+	result.BackupPath = outputPath
+	if callback != nil {
+		callback(mb.tool, fmt.Sprintf("Physical backup completed successfully (%d files)", progress.files))
+	}
+
+	return result, nil
+}
+
+// Restore extracts the xbstream artifact into the target directory and prepares it so
+// that it can be used as the data directory of a server
+func (mb *MariabackupStrategy) Restore(ctx context.Context, target string, artifactPaths []string, callback ProgressCallback) error {
+	if len(artifactPaths) != 1 {
+		return fmt.Errorf("physical restore expects exactly one artifact, got %d", len(artifactPaths))
+	}
+
+	entries, err := os.ReadDir(target)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read restore directory: %w", err)
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("restore directory %s is not empty", target)
+	}
+	if err := os.MkdirAll(target, 0750); err != nil {
+		return fmt.Errorf("failed to create restore directory: %w", err)
+	}
+
+	if callback != nil {
+		callback(mb.tool, fmt.Sprintf("Extracting %s into %s...", filepath.Base(artifactPaths[0]), target))
+	}
+
+	input, err := openArtifact(artifactPaths[0])
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	extract := exec.CommandContext(ctx, mb.streamTool(), "-x", "-C", target)
+	extract.Stdin = input
+	if output, err := extract.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %w, output: %s", mb.streamTool(), err, strings.TrimSpace(string(output)))
+	}
+
+	if callback != nil {
+		callback(mb.tool, "Preparing backup (applying redo log)...")
+	}
+
+	prepare := exec.CommandContext(ctx, mb.tool, "--prepare", "--target-dir="+target)
+	if output, err := prepare.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s --prepare failed: %w, output: %s", mb.tool, err, strings.TrimSpace(string(output)))
+	}
+
+	if callback != nil {
+		callback(mb.tool, fmt.Sprintf("Physical restore completed; %s is ready to be used as the server data directory", target))
+	}
+	return nil
+}
+
+// streamTool returns the xbstream extraction tool matching the backup tool
+func (mb *MariabackupStrategy) streamTool() string {
+	if mb.tool == "xtrabackup" {
+		return "xbstream"
+	}
+	return "mbstream"
+}
+
+// captureProgress parses the tool's stderr into progress updates
+func (mb *MariabackupStrategy) captureProgress(pipe io.ReadCloser, progress *streamProgress, result *BackupResult, callback ProgressCallback) {
+	defer pipe.Close()
+
+	scanner := bufio.NewScanner(pipe)
+	var outputBuffer bytes.Buffer
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		outputBuffer.WriteString(line)
+		outputBuffer.WriteString("\n")
+
+		if mb.containsError(line) && callback != nil {
+			callback(mb.tool, fmt.Sprintf("❌ %s ERROR: %s", strings.ToUpper(mb.tool), line))
+		}
+
+		if message, report := progress.handleLine(line, time.Now()); report && callback != nil {
+			callback(mb.tool, message)
+		}
+	}
+
+	// Keep the tail of the output in command logs, the full output can be very long
+	if outputBuffer.Len() > 0 {
+		output := outputBuffer.String()
+		if len(output) > 4000 {
+			output = "..." + output[len(output)-4000:]
+		}
+		result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Output (stderr): %s", output))
+	}
+}
+
+// containsError checks if the output contains mariabackup/xtrabackup error patterns
+func (mb *MariabackupStrategy) containsError(output string) bool {
+	outputLower := strings.ToLower(output)
+
+	errorPatterns := []string{
+		"[error]",
+		"error:",
+		"access denied",
+		"can't connect",
+		"failed to",
+		"permission denied",
+		"no space left",
+	}
+
+	for _, pattern := range errorPatterns {
+		if strings.Contains(outputLower, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sanitizeArgs removes sensitive information from command arguments
+func (mb *MariabackupStrategy) sanitizeArgs(args []string) []string {
+	sanitized := make([]string, len(args))
+	copy(sanitized, args)
+
+	for i, arg := range sanitized {
+		if strings.HasPrefix(arg, "--password=") {
+			sanitized[i] = "--password=***"
+		}
+	}
+	return sanitized
+}
+
+var (
+	// streamingFilePattern matches the per-file lines of mariabackup/xtrabackup
+	streamingFilePattern = regexp.MustCompile(`(?:Streaming|Copying) (\S+)`)
+	// logScannedPattern matches redo log progress lines
+	logScannedPattern = regexp.MustCompile(`log scanned up to \((\d+)\)`)
+)
+
+// streamProgress tracks the progress of an xbstream backup from the tool's stderr
+type streamProgress struct {
+	outputPath string
+	files      int
+	lastFile   string
+	lsn        string
+	completed  bool
+	lastReport time.Time
+}
+
+// newStreamProgress creates a progress tracker for a stream written to outputPath
+func newStreamProgress(outputPath string) *streamProgress {
+	return &streamProgress{outputPath: outputPath}
+}
+
+// handleLine updates the progress from a stderr line and returns a progress message
+// when one should be reported
+func (sp *streamProgress) handleLine(line string, now time.Time) (string, bool) {
+	if strings.Contains(line, "completed OK!") {
+		sp.completed = true
+		return fmt.Sprintf("Backup stream completed: %d files, %s", sp.files, sp.streamedSize()), true
+	}
+
+	if match := streamingFilePattern.FindStringSubmatch(line); match != nil {
+		sp.files++
+		sp.lastFile = match[1]
+	} else if match := logScannedPattern.FindStringSubmatch(line); match != nil {
+		sp.lsn = match[1]
+	} else {
+		return "", false
+	}
+
+	if now.Sub(sp.lastReport) < progressReportInterval {
+		return "", false
+	}
+	sp.lastReport = now
+
+	message := fmt.Sprintf("Streamed %d files (%s), current: %s", sp.files, sp.streamedSize(), sp.lastFile)
+	if sp.lsn != "" {
+		message += fmt.Sprintf(", redo log scanned up to LSN %s", sp.lsn)
+	}
+	return message, true
+}
+
+// streamedSize returns the size written to the output file so far
+func (sp *streamProgress) streamedSize() string {
+	fileInfo, err := os.Stat(sp.outputPath)
+	if err != nil {
+		return "unknown size"
+	}
+	return formatBytes(fileInfo.Size())
+}
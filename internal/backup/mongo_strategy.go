@@ -2,19 +2,29 @@ package backup
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
+
+	"easy-backup/internal/config"
 )
 
 // MongoStrategy implements DatabaseStrategy for MongoDB databases
 type MongoStrategy struct {
-	logger *logrus.Logger
+	logger  *logrus.Logger
+	options config.MongoDBConfig
+	tools   clientTools
 }
 
 // NewMongoStrategy creates a new MongoDB backup strategy
@@ -27,6 +37,14 @@ func (ms *MongoStrategy) GetType() string {
 	return "mongodb"
 }
 
+// WithConfig returns a copy of the strategy using the MongoDB settings of the strategy
+func (ms *MongoStrategy) WithConfig(strategyConfig config.StrategyConfig) DatabaseStrategy {
+	configured := *ms
+	configured.options = strategyConfig.MongoDB
+	configured.tools = newClientTools(strategyConfig.ToolPaths)
+	return &configured
+}
+
 // ValidateConnection validates the MongoDB connection
 func (ms *MongoStrategy) ValidateConnection(databaseURL string) error {
 	if !strings.HasPrefix(databaseURL, "mongodb://") && !strings.HasPrefix(databaseURL, "mongodb+srv://") {
@@ -45,23 +63,127 @@ func (ms *MongoStrategy) Backup(ctx context.Context, databaseURL, outputPath str
 		callback("mongodb", "Starting MongoDB backup...")
 	}
 
-	args := []string{
-		"--uri=" + databaseURL,
-		"--out=" + outputPath,
-		"--verbose",
+	if err := ms.validateOptions(databaseURL); err != nil {
+		if callback != nil {
+			callback("mongodb", fmt.Sprintf("❌ %s", err.Error()))
+		}
+		return result, err
+	}
+
+	// In archive mode mongodump writes a single gzip-compressed archive file,
+	// otherwise it dumps into a directory that is archived afterwards
+	archivePath := outputPath + ".gz"
+	args, cleanup, err := mongoCredentialArgs(databaseURL)
+	if err != nil {
+		return result, err
 	}
+	defer cleanup()
+	if ms.options.Archive {
+		args = append(args, "--archive="+archivePath, "--gzip")
+	} else {
+		args = append(args, "--out="+outputPath)
+	}
+	args = append(args, ms.dumpArgs()...)
+	args = append(args, "--verbose")
+
+	// mongodump accepts a single --collection, so every included collection is dumped
+	// by its own run into the same output directory
+	if len(ms.options.IncludeCollections) > 0 {
+		for _, collection := range ms.options.IncludeCollections {
+			if callback != nil {
+				callback("mongodb", fmt.Sprintf("Dumping collection %s...", collection))
+			}
+			if err := ms.runDump(ctx, append(args, "--collection="+collection), result, callback); err != nil {
+				return result, err
+			}
+		}
+	} else if err := ms.runDump(ctx, args, result, callback); err != nil {
+		return result, err
+	}
+
+	if ms.options.Archive {
+		if _, err := os.Stat(archivePath); err != nil {
+			errorMsg := "backup archive was not created"
+			result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Error: %s", errorMsg))
+			if callback != nil {
+				callback("mongodb", fmt.Sprintf("❌ %s", errorMsg))
+			}
+			return result, fmt.Errorf("%s", errorMsg)
+		}
 
-	cmd := exec.CommandContext(ctx, "mongodump", args...)
+		result.BackupPath = archivePath
+		if callback != nil {
+			callback("mongodb", "MongoDB backup completed successfully")
+		}
+		return result, nil
+	}
+
+	// For directory dumps, we need to create a tar.gz archive from the dump directory
+	tarPath := outputPath + ".tar.gz"
+	if err := writeTarGz(outputPath, tarPath); err != nil {
+		os.Remove(tarPath)
+		if callback != nil {
+			callback("mongodb", fmt.Sprintf("❌ Failed to create archive: %s", err.Error()))
+		}
+		return result, fmt.Errorf("failed to create tar archive: %w", err)
+	}
+
+	// Clean up the dump directory
+	if err := os.RemoveAll(outputPath); err != nil {
+		ms.logger.WithError(err).Warn("Failed to clean up MongoDB dump directory")
+	}
+
+	result.BackupPath = tarPath
+	if callback != nil {
+		callback("mongodb", "MongoDB backup completed successfully")
+	}
+
+	return result, nil
+}
+
+// validateOptions checks the configured options against the connection URL
+func (ms *MongoStrategy) validateOptions(databaseURL string) error {
+	database := mongoURIDatabase(databaseURL)
+	if ms.options.Oplog && database != "" {
+		return fmt.Errorf("oplog dumps require a connection URL without a database, got '%s'", database)
+	}
+	if (len(ms.options.IncludeCollections) > 0 || len(ms.options.ExcludeCollections) > 0) && database == "" {
+		return fmt.Errorf("collection filters require a database in the connection URL")
+	}
+	return nil
+}
+
+// dumpArgs returns the mongodump options shared by every run of a backup
+func (ms *MongoStrategy) dumpArgs() []string {
+	var args []string
+	if ms.options.Oplog {
+		args = append(args, "--oplog")
+	}
+	if ms.options.ReadPreference != "" {
+		args = append(args, "--readPreference="+ms.options.ReadPreference)
+	}
+	for _, collection := range ms.options.ExcludeCollections {
+		args = append(args, "--excludeCollection="+collection)
+	}
+	if ms.options.Query != "" {
+		args = append(args, "--query="+ms.options.Query)
+	}
+	return args
+}
+
+// runDump executes mongodump with the given arguments
+func (ms *MongoStrategy) runDump(ctx context.Context, args []string, result *BackupResult, callback ProgressCallback) error {
+	cmd := exec.CommandContext(ctx, ms.tools.path("mongodump"), args...)
 
 	// Set up pipes for real-time output capture
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return result, fmt.Errorf("failed to get stdout pipe: %w", err)
+		return fmt.Errorf("failed to get stdout pipe: %w", err)
 	}
 
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
-		return result, fmt.Errorf("failed to get stderr pipe: %w", err)
+		return fmt.Errorf("failed to get stderr pipe: %w", err)
 	}
 
 	// Start the command
@@ -71,16 +193,26 @@ func (ms *MongoStrategy) Backup(ctx context.Context, databaseURL, outputPath str
 		if callback != nil {
 			callback("mongodb", fmt.Sprintf("❌ Command failed to start: %s", err.Error()))
 		}
-		return result, fmt.Errorf("mongodump failed to start: %w", err)
+		return fmt.Errorf("mongodump failed to start: %w", err)
 	}
 
 	// Log the command execution (with masked credentials)
 	commandLog := fmt.Sprintf("Command: mongodump %s", strings.Join(ms.sanitizeArgs(args), " "))
 	result.CommandLogs = append(result.CommandLogs, commandLog)
 
-	// Capture output in real-time
-	go ms.captureOutput(stdout, "stdout", result, callback)
-	go ms.captureOutput(stderr, "stderr", result, callback)
+	// Capture output in real-time, until both pipes are drained
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	capture := func(pipe io.ReadCloser, streamType string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ms.captureOutput(pipe, streamType, result, &mu, callback)
+		}()
+	}
+	capture(stdout, "stdout")
+	capture(stderr, "stderr")
+	wg.Wait()
 
 	// Wait for command to complete
 	if err := cmd.Wait(); err != nil {
@@ -89,43 +221,228 @@ func (ms *MongoStrategy) Backup(ctx context.Context, databaseURL, outputPath str
 		if callback != nil {
 			callback("mongodb", fmt.Sprintf("❌ MongoDB backup failed: %s", err.Error()))
 		}
-		return result, fmt.Errorf("mongodump failed: %w", err)
+		return fmt.Errorf("mongodump failed: %w", err)
 	}
 
-	// For MongoDB, we need to create a tar.gz archive from the dump directory
-	tarPath := outputPath + ".tar.gz"
-	if err := ms.createTarArchive(outputPath, tarPath); err != nil {
+	return nil
+}
+
+// Restore loads a MongoDB backup into the target deployment using mongorestore.
+// Oplog-consistent backups are restored with --oplogReplay.
+func (ms *MongoStrategy) Restore(ctx context.Context, target string, artifactPaths []string, callback ProgressCallback) error {
+	if len(artifactPaths) != 1 {
+		return fmt.Errorf("MongoDB restore expects exactly one artifact, got %d", len(artifactPaths))
+	}
+	artifactPath := artifactPaths[0]
+
+	args, cleanup, err := mongoCredentialArgs(target)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if strings.HasSuffix(artifactPath, ".tar.gz") {
+		dumpDir, err := os.MkdirTemp(filepath.Dir(artifactPath), "mongorestore-")
+		if err != nil {
+			return fmt.Errorf("failed to create restore directory: %w", err)
+		}
+		defer os.RemoveAll(dumpDir)
+
 		if callback != nil {
-			callback("mongodb", fmt.Sprintf("❌ Failed to create archive: %s", err.Error()))
+			callback("mongodb", fmt.Sprintf("Extracting %s...", filepath.Base(artifactPath)))
 		}
-		return result, fmt.Errorf("failed to create tar archive: %w", err)
+		if err := extractTarGz(artifactPath, dumpDir); err != nil {
+			return err
+		}
+		args = append(args, "--dir="+dumpDir)
+	} else {
+		args = append(args, "--archive="+artifactPath, "--gzip")
+	}
+	if ms.options.Oplog {
+		args = append(args, "--oplogReplay")
 	}
 
-	// Clean up the dump directory
-	if err := os.RemoveAll(outputPath); err != nil {
-		ms.logger.WithError(err).Warn("Failed to clean up MongoDB dump directory")
+	if callback != nil {
+		callback("mongodb", fmt.Sprintf("Restoring %s...", filepath.Base(artifactPath)))
+	}
+
+	cmd := exec.CommandContext(ctx, ms.tools.path("mongorestore"), args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("mongorestore %s failed: %w, output: %s", strings.Join(ms.sanitizeArgs(args), " "), err, strings.TrimSpace(string(output)))
 	}
 
-	result.BackupPath = tarPath
 	if callback != nil {
-		callback("mongodb", "MongoDB backup completed successfully")
+		callback("mongodb", "MongoDB restore completed successfully")
 	}
+	return nil
+}
 
-	return result, nil
+// preflightScript checks that the user can list the collections of the dumped database, or all
+// databases, and read the oplog when requested. It prints the server version last.
+const preflightScript = `
+const database = %s;
+if (database) {
+	db.getSiblingDB(database).runCommand({ listCollections: 1, nameOnly: true });
+} else {
+	db.adminCommand({ listDatabases: 1, nameOnly: true });
+}
+if (%t) {
+	const local = db.getSiblingDB("local");
+	if (!local.getCollectionNames().includes("oplog.rs")) throw new Error("no oplog found, oplog dumps require a replica set member");
+	local.getCollection("oplog.rs").findOne();
 }
+print(db.version());
+`
+
+// Preflight checks that mongodump is installed and compatible with the server and that the
+// user can read the dumped databases. Connection checks use mongosh and are skipped when
+// it is not installed.
+func (ms *MongoStrategy) Preflight(ctx context.Context, databaseURL string) error {
+	if err := requireTools(ms.tools.path("mongodump")); err != nil {
+		return err
+	}
+	if _, err := exec.LookPath("mongosh"); err != nil {
+		ms.logger.Debug("mongosh is not installed, skipping MongoDB connection preflight")
+		return nil
+	}
+
+	script := fmt.Sprintf(preflightScript, strconv.Quote(mongoURIDatabase(databaseURL)), ms.options.Oplog)
+	output, err := ms.eval(ctx, databaseURL, script)
+	if err != nil {
+		kind := classifyConnectionError(err.Error())
+		if strings.Contains(err.Error(), "no oplog found") {
+			kind = PreflightPrivileges
+		}
+		return preflightError(kind, "MongoDB preflight failed: %s", err.Error())
+	}
 
-// createTarArchive creates a tar.gz archive from a directory
-func (ms *MongoStrategy) createTarArchive(sourceDir, targetPath string) error {
-	cmd := exec.Command("tar", "-czf", targetPath, "-C", sourceDir, ".")
-	output, err := cmd.CombinedOutput()
+	serverMajor, err := parseMajorVersion(output)
 	if err != nil {
-		return fmt.Errorf("tar command failed: %w, output: %s", err, string(output))
+		return err
 	}
+	dumpVersion, err := toolVersion(ctx, ms.tools.path("mongodump"))
+	if err != nil {
+		return err
+	}
+	toolMajor, err := parseMajorVersion(dumpVersion)
+	if err != nil {
+		return err
+	}
+	// The database tools are versioned separately since 100.0 and support MongoDB 4.0 and newer
+	if toolMajor >= 100 && serverMajor < 4 {
+		return preflightError(PreflightVersionMismatch, "mongodump %d.x does not support MongoDB %d servers", toolMajor, serverMajor)
+	}
+
 	return nil
 }
 
+// replicationLagScript prints the replication lag in seconds. Connected secondaries report their
+// own lag; through the primary the most lagging secondary is reported, as mongodump may read
+// from any of them. Servers that are not part of a replica set report zero.
+const replicationLagScript = `
+let status;
+try {
+	status = db.adminCommand({ replSetGetStatus: 1 });
+} catch (e) {
+	if (e.codeName !== "NoReplicationEnabled") throw e;
+}
+if (!status) {
+	print(0);
+} else {
+	const primary = status.members.find((m) => m.stateStr === "PRIMARY");
+	if (!primary) throw new Error("replica set has no primary");
+	const self = status.members.find((m) => m.self);
+	const secondaries = self && self.stateStr === "SECONDARY" ? [self] : status.members.filter((m) => m.stateStr === "SECONDARY");
+	print(Math.max(0, ...secondaries.map((m) => (primary.optimeDate - m.optimeDate) / 1000)));
+}
+`
+
+// ReplicationLag compares the optimes of the replica set members using mongosh
+func (ms *MongoStrategy) ReplicationLag(ctx context.Context, databaseURL string) (time.Duration, error) {
+	value, err := ms.eval(ctx, databaseURL, replicationLagScript)
+	if err != nil {
+		return 0, err
+	}
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected replication lag %q: %w", value, err)
+	}
+	return time.Duration(seconds * float64(time.Second)).Round(time.Millisecond), nil
+}
+
+// SelectTools reads the server version with mongosh and returns a copy of the strategy using
+// the database tools configured for it. Without mongosh the default tools are used.
+func (ms *MongoStrategy) SelectTools(ctx context.Context, databaseURL string) (DatabaseStrategy, *VersionInfo, error) {
+	configured := *ms
+	var serverVersion string
+	if _, err := exec.LookPath("mongosh"); err == nil {
+		output, err := ms.eval(ctx, databaseURL, "print(db.version())")
+		if err != nil {
+			return nil, nil, preflightError(classifyConnectionError(err.Error()), "%s", err.Error())
+		}
+		serverMajor, err := parseMajorVersion(output)
+		if err != nil {
+			return nil, nil, err
+		}
+		configured.tools = ms.tools.forServer(serverMajor)
+		serverVersion = "MongoDB " + output
+	}
+
+	dumpVersion, err := toolVersion(ctx, configured.tools.path("mongodump"))
+	if err != nil {
+		return nil, nil, err
+	}
+	return &configured, &VersionInfo{ServerVersion: serverVersion, ToolVersion: dumpVersion}, nil
+}
+
+// eval runs a mongosh script and returns the last line it printed, which follows any
+// connection warnings
+func (ms *MongoStrategy) eval(ctx context.Context, databaseURL, script string) (string, error) {
+	// The script connects itself so the URL does not appear in the arguments
+	uri, err := json.Marshal(databaseURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode MongoDB URI: %w", err)
+	}
+	scriptPath, cleanup, err := writeCredentialFile("easy-backup-mongosh-*.js", "db = connect("+string(uri)+");\n"+script)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "mongosh", "--nodb", "--quiet", "--norc", scriptPath)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("mongosh failed: %w: %s", err, strings.TrimSpace(stderr.String()+" "+stdout.String()))
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	return strings.TrimSpace(lines[len(lines)-1]), nil
+}
+
+// mongoURIDatabase returns the database name from the path of a MongoDB URL
+func mongoURIDatabase(databaseURL string) string {
+	withoutScheme := databaseURL
+	if idx := strings.Index(withoutScheme, "://"); idx != -1 {
+		withoutScheme = withoutScheme[idx+3:]
+	}
+	if idx := strings.Index(withoutScheme, "?"); idx != -1 {
+		withoutScheme = withoutScheme[:idx]
+	}
+	// Credentials may contain '/', so only look at the part after the host list
+	if idx := strings.LastIndex(withoutScheme, "@"); idx != -1 {
+		withoutScheme = withoutScheme[idx+1:]
+	}
+	idx := strings.Index(withoutScheme, "/")
+	if idx == -1 {
+		return ""
+	}
+	return withoutScheme[idx+1:]
+}
+
 // captureOutput captures command output in real-time
-func (ms *MongoStrategy) captureOutput(pipe io.ReadCloser, streamType string, result *BackupResult, callback ProgressCallback) {
+func (ms *MongoStrategy) captureOutput(pipe io.ReadCloser, streamType string, result *BackupResult, mu *sync.Mutex, callback ProgressCallback) {
 	defer pipe.Close()
 
 	scanner := bufio.NewScanner(pipe)
@@ -150,7 +467,9 @@ func (ms *MongoStrategy) captureOutput(pipe io.ReadCloser, streamType string, re
 	// Store the complete output in command logs
 	if outputBuffer.Len() > 0 {
 		outputLog := fmt.Sprintf("Output (%s): %s", streamType, outputBuffer.String())
+		mu.Lock()
 		result.CommandLogs = append(result.CommandLogs, outputLog)
+		mu.Unlock()
 	}
 }
 
@@ -2,19 +2,25 @@ package backup
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
+
+	"easy-backup/internal/config"
 )
 
 // PostgresStrategy implements DatabaseStrategy for PostgreSQL databases
 type PostgresStrategy struct {
 	logger *logrus.Logger
+	tools  clientTools
 }
 
 // NewPostgresStrategy creates a new PostgreSQL backup strategy
@@ -27,6 +33,13 @@ func (ps *PostgresStrategy) GetType() string {
 	return "postgres"
 }
 
+// WithConfig returns a copy of the strategy using the client tools configured for the strategy
+func (ps *PostgresStrategy) WithConfig(strategyConfig config.StrategyConfig) DatabaseStrategy {
+	configured := *ps
+	configured.tools = newClientTools(strategyConfig.ToolPaths)
+	return &configured
+}
+
 // ValidateConnection validates the PostgreSQL connection
 func (ps *PostgresStrategy) ValidateConnection(databaseURL string) error {
 	// Simple validation - could be enhanced with actual connection test
@@ -36,6 +49,136 @@ func (ps *PostgresStrategy) ValidateConnection(databaseURL string) error {
 	return nil
 }
 
+// replicationLagQuery returns the replay lag of a standby in seconds. Standbys that have
+// replayed everything they received from a streaming primary are not lagging, even when
+// the last replayed transaction is old. Primaries report zero, -1 means nothing was replayed yet.
+const replicationLagQuery = `SELECT CASE
+	WHEN NOT pg_is_in_recovery() THEN 0
+	WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn()
+		AND EXISTS (SELECT 1 FROM pg_stat_wal_receiver WHERE status = 'streaming') THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), -1)
+END`
+
+// ReplicationLag measures how far a standby is behind its primary using psql
+func (ps *PostgresStrategy) ReplicationLag(ctx context.Context, databaseURL string) (time.Duration, error) {
+	output, err := ps.query(ctx, ps.tools.path("psql"), databaseURL, replicationLagQuery)
+	if err != nil {
+		return 0, err
+	}
+
+	seconds, err := strconv.ParseFloat(output, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected replication lag %q: %w", output, err)
+	}
+	if seconds < 0 {
+		return 0, fmt.Errorf("standby has not replayed any transactions yet")
+	}
+	return time.Duration(seconds * float64(time.Second)).Round(time.Millisecond), nil
+}
+
+// SelectTools reads the server version and returns a copy of the strategy using the client
+// tools configured for it
+func (ps *PostgresStrategy) SelectTools(ctx context.Context, databaseURL string) (DatabaseStrategy, *VersionInfo, error) {
+	psql := ps.tools.path("psql")
+	if err := requireTools(psql); err != nil {
+		return nil, nil, err
+	}
+	output, err := ps.query(ctx, psql, databaseURL, "SELECT current_setting('server_version_num'), current_setting('server_version')")
+	if err != nil {
+		return nil, nil, preflightError(classifyConnectionError(err.Error()), "%s", err.Error())
+	}
+
+	versionNum, serverVersion, _ := strings.Cut(output, "\t")
+	num, err := strconv.Atoi(versionNum)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unexpected server version %q", versionNum)
+	}
+
+	configured := *ps
+	configured.tools = ps.tools.forServer(num / 10000)
+	pgDumpVersion, err := toolVersion(ctx, configured.tools.path("pg_dump"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &configured, &VersionInfo{ServerVersion: "PostgreSQL " + serverVersion, ToolVersion: pgDumpVersion}, nil
+}
+
+// query runs a statement with psql and returns its unaligned, tab separated output
+func (ps *PostgresStrategy) query(ctx context.Context, psql, databaseURL, statement string) (string, error) {
+	connURL, env, cleanup, err := postgresCredentials(databaseURL)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	args := []string{
+		connURL,
+		"--no-password",
+		"--tuples-only",
+		"--no-align",
+		"--field-separator=\t",
+		"--command=" + statement,
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, psql, args...)
+	cmd.Env = env
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("psql %s: %w: %s", ps.sanitizeArgs(args)[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// preflightQuery returns the server version and the tables and sequences pg_dump could not read
+const preflightQuery = `SELECT current_setting('server_version_num'),
+	coalesce(string_agg(format('%I.%I', n.nspname, c.relname), ' '), '')
+FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE c.relkind IN ('r', 'S')
+	AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+	AND n.nspname NOT LIKE 'pg_toast%'
+	AND n.nspname NOT LIKE 'pg_temp%'
+	AND NOT (has_schema_privilege(n.oid, 'USAGE') AND has_table_privilege(c.oid, 'SELECT'))`
+
+// Preflight checks that pg_dump is installed and supports the server version and that
+// the user can connect and read every table and sequence of the database
+func (ps *PostgresStrategy) Preflight(ctx context.Context, databaseURL string) error {
+	if err := requireTools(ps.tools.path("pg_dump"), ps.tools.path("psql")); err != nil {
+		return err
+	}
+
+	output, err := ps.query(ctx, ps.tools.path("psql"), databaseURL, preflightQuery)
+	if err != nil {
+		return preflightError(classifyConnectionError(err.Error()), "cannot connect: %s", err.Error())
+	}
+
+	serverVersion, unreadable, _ := strings.Cut(output, "\t")
+	if unreadable != "" {
+		return preflightError(PreflightPrivileges, "user cannot read %s", summarizeNames(strings.Fields(unreadable)))
+	}
+
+	versionNum, err := strconv.Atoi(serverVersion)
+	if err != nil {
+		return fmt.Errorf("unexpected server version %q", serverVersion)
+	}
+	pgDumpVersion, err := toolVersion(ctx, ps.tools.path("pg_dump"))
+	if err != nil {
+		return err
+	}
+	toolMajor, err := parseMajorVersion(pgDumpVersion)
+	if err != nil {
+		return err
+	}
+	// pg_dump can dump servers of its own and older major versions
+	if serverMajor := versionNum / 10000; toolMajor < serverMajor {
+		return preflightError(PreflightVersionMismatch, "pg_dump %d cannot dump PostgreSQL %d servers", toolMajor, serverMajor)
+	}
+
+	return nil
+}
+
 // Backup performs a PostgreSQL backup using pg_dump
 func (ps *PostgresStrategy) Backup(ctx context.Context, databaseURL, outputPath string, callback ProgressCallback) (*BackupResult, error) {
 	result := &BackupResult{
@@ -46,15 +189,23 @@ func (ps *PostgresStrategy) Backup(ctx context.Context, databaseURL, outputPath
 		callback("postgres", "Starting PostgreSQL backup...")
 	}
 
+	// The password is read from a temporary PGPASSFILE so it does not appear in the arguments
+	connURL, env, cleanup, err := postgresCredentials(databaseURL)
+	if err != nil {
+		return result, err
+	}
+	defer cleanup()
+
 	args := []string{
-		databaseURL,
+		connURL,
 		"--no-password",
 		"--verbose",
 		"--format=custom",
 		"--file=" + outputPath,
 	}
 
-	cmd := exec.CommandContext(ctx, "pg_dump", args...)
+	cmd := exec.CommandContext(ctx, ps.tools.path("pg_dump"), args...)
+	cmd.Env = env
 
 	// Set up pipes for real-time output capture
 	stdout, err := cmd.StdoutPipe()
@@ -0,0 +1,147 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// preflightTimeout bounds the time spent on the checks before a backup
+const preflightTimeout = time.Minute
+
+// Preflight error kinds
+const (
+	PreflightToolMissing     = "tool_missing"     // A required client binary is not installed
+	PreflightUnreachable     = "unreachable"      // The server could not be reached
+	PreflightAuthentication  = "authentication"   // The server rejected the credentials or database
+	PreflightPrivileges      = "privileges"       // The user or server lacks privileges or settings needed for the dump
+	PreflightVersionMismatch = "version_mismatch" // The dump tool cannot handle the server version
+)
+
+// PreflightError is a classified failure of the checks run before a backup
+type PreflightError struct {
+	Kind string
+	Err  error
+}
+
+// Error returns the error message including its kind
+func (pe *PreflightError) Error() string {
+	return fmt.Sprintf("preflight check failed (%s): %s", pe.Kind, pe.Err.Error())
+}
+
+// Unwrap returns the underlying error
+func (pe *PreflightError) Unwrap() error {
+	return pe.Err
+}
+
+// Retryable reports whether retrying the backup may succeed. Only unreachable
+// servers can recover on their own.
+func (pe *PreflightError) Retryable() bool {
+	return pe.Kind == PreflightUnreachable
+}
+
+// IsRetryable reports whether a failed backup is worth retrying
+func IsRetryable(err error) bool {
+	var preflightErr *PreflightError
+	if errors.As(err, &preflightErr) {
+		return preflightErr.Retryable()
+	}
+	return true
+}
+
+// PreflightStrategy is implemented by strategies that can check, before dumping, that their
+// tools are installed and the server accepts the connection with sufficient privileges.
+// ValidateConnection remains the cheap syntax check of the URL.
+type PreflightStrategy interface {
+	Preflight(ctx context.Context, databaseURL string) error
+}
+
+// runPreflight runs the preflight checks of strategies that support them
+func (bs *BackupService) runPreflight(ctx context.Context, dbStrategy DatabaseStrategy, databaseURL string, result *BackupResult) error {
+	preflight, ok := dbStrategy.(PreflightStrategy)
+	if !ok {
+		return nil
+	}
+
+	preflightCtx, cancel := context.WithTimeout(ctx, preflightTimeout)
+	defer cancel()
+	if err := preflight.Preflight(preflightCtx, databaseURL); err != nil {
+		result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Error: %s", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// preflightError creates a classified preflight error
+func preflightError(kind string, format string, args ...interface{}) *PreflightError {
+	return &PreflightError{Kind: kind, Err: fmt.Errorf(format, args...)}
+}
+
+// requireTools checks that the given binaries are installed
+func requireTools(tools ...string) error {
+	for _, tool := range tools {
+		if _, err := exec.LookPath(tool); err != nil {
+			return preflightError(PreflightToolMissing, "%s is not installed or not in PATH", tool)
+		}
+	}
+	return nil
+}
+
+// classifyConnectionError classifies the output of a failed client connection
+func classifyConnectionError(output string) string {
+	lower := strings.ToLower(output)
+	switch {
+	case strings.Contains(lower, "' to database '"): // MySQL access denied to a database
+		return PreflightPrivileges
+	case strings.Contains(lower, "authentication failed"),
+		strings.Contains(lower, "requires authentication"),
+		strings.Contains(lower, "no password supplied"),
+		strings.Contains(lower, "noauth"),
+		strings.Contains(lower, "wrongpass"),
+		strings.Contains(lower, "access denied for user"),
+		strings.Contains(lower, "does not exist"),
+		strings.Contains(lower, "unknown database"),
+		strings.Contains(lower, "x509: "), // Rejected TLS certificates, e.g. of etcd clients
+		strings.Contains(lower, "bad certificate"):
+		return PreflightAuthentication
+	case strings.Contains(lower, "permission denied"),
+		strings.Contains(lower, "not authorized"),
+		strings.Contains(lower, "unauthorized"),
+		strings.Contains(lower, "access denied; you need"):
+		return PreflightPrivileges
+	default:
+		return PreflightUnreachable
+	}
+}
+
+// versionPattern matches the first dotted version number in tool or server output
+var versionPattern = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// parseMajorVersion returns the major version of the first version number in the text
+func parseMajorVersion(text string) (int, error) {
+	match := versionPattern.FindStringSubmatch(text)
+	if match == nil {
+		return 0, fmt.Errorf("no version found in %q", strings.TrimSpace(text))
+	}
+	return strconv.Atoi(match[1])
+}
+
+// toolOutput runs a tool and returns its trimmed combined output
+func toolOutput(ctx context.Context, tool string, args ...string) (string, error) {
+	output, err := exec.CommandContext(ctx, tool, args...).CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
+
+// summarizeNames lists the first few names and the number of remaining ones
+func summarizeNames(names []string) string {
+	const maxNames = 5
+	if len(names) <= maxNames {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:maxNames], ", "), len(names)-maxNames)
+}
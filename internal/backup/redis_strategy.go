@@ -0,0 +1,256 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// rdbHeaderPattern matches the magic string and version at the start of an RDB file
+var rdbHeaderPattern = regexp.MustCompile(`^REDIS\d{4}$`)
+
+// RedisStrategy implements DatabaseStrategy for Redis using RDB snapshots
+type RedisStrategy struct {
+	logger *logrus.Logger
+}
+
+// NewRedisStrategy creates a new Redis backup strategy
+func NewRedisStrategy(logger *logrus.Logger) *RedisStrategy {
+	return &RedisStrategy{logger: logger}
+}
+
+// GetType returns the database type
+func (rs *RedisStrategy) GetType() string {
+	return "redis"
+}
+
+// ValidateConnection validates the Redis URL
+func (rs *RedisStrategy) ValidateConnection(databaseURL string) error {
+	if _, err := parseRedisURL(databaseURL); err != nil {
+		return err
+	}
+	return nil
+}
+
+// redisParams holds the connection settings of a Redis URL
+type redisParams struct {
+	Host     string
+	Port     string
+	User     string // ACL user, empty for the default user
+	Password string
+	DB       string // Database index; RDB snapshots always contain every database
+	TLS      bool
+}
+
+// Backup streams an RDB snapshot from the server with redis-cli --rdb. The server
+// forks a background save and sends the result as it would to a replica.
+func (rs *RedisStrategy) Backup(ctx context.Context, databaseURL, outputPath string, callback ProgressCallback) (*BackupResult, error) {
+	result := &BackupResult{
+		CommandLogs: make([]string, 0),
+	}
+
+	if callback != nil {
+		callback("redis", "Starting Redis backup...")
+	}
+
+	params, err := parseRedisURL(databaseURL)
+	if err != nil {
+		if callback != nil {
+			callback("redis", fmt.Sprintf("❌ Invalid connection URL: %s", err.Error()))
+		}
+		return result, fmt.Errorf("invalid Redis connection URL: %w", err)
+	}
+
+	args := rs.connectionArgs(params)
+	args = append(args, "--rdb", outputPath)
+
+	cmd := exec.CommandContext(ctx, "redis-cli", args...)
+	// Pass the password through the environment so it does not show up in process listings
+	cmd.Env = os.Environ()
+	if params.Password != "" {
+		cmd.Env = append(cmd.Env, "REDISCLI_AUTH="+params.Password)
+	}
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	commandLog := fmt.Sprintf("Command: redis-cli %s", strings.Join(args, " "))
+	result.CommandLogs = append(result.CommandLogs, commandLog)
+
+	if err := cmd.Start(); err != nil {
+		result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Command failed to start: %s", err.Error()))
+		if callback != nil {
+			callback("redis", fmt.Sprintf("❌ Command failed to start: %s", err.Error()))
+		}
+		return result, fmt.Errorf("redis-cli failed to start: %w", err)
+	}
+
+	// Report the transferred size while the snapshot is streamed
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		rs.reportProgress(outputPath, done, callback)
+	}()
+
+	waitErr := cmd.Wait()
+	close(done)
+	wg.Wait()
+
+	if output.Len() > 0 {
+		result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Output: %s", strings.TrimSpace(output.String())))
+	}
+
+	if waitErr != nil {
+		errorLog := fmt.Sprintf("Command failed: redis-cli %s - Error: %s", strings.Join(args, " "), waitErr.Error())
+		result.CommandLogs = append(result.CommandLogs, errorLog)
+		if callback != nil {
+			callback("redis", fmt.Sprintf("❌ Redis backup failed: %s", strings.TrimSpace(output.String())))
+		}
+		return result, fmt.Errorf("redis-cli failed: %w", waitErr)
+	}
+
+	if err := validateRDBFile(outputPath); err != nil {
+		result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Error: %s", err.Error()))
+		if callback != nil {
+			callback("redis", fmt.Sprintf("❌ %s", err.Error()))
+		}
+		return result, err
+	}
+
+	result.BackupPath = outputPath
+	if callback != nil {
+		callback("redis", "Redis backup completed successfully")
+	}
+
+	return result, nil
+}
+
+// Preflight checks that redis-cli is installed and the server answers PING with the
+// configured credentials
+func (rs *RedisStrategy) Preflight(ctx context.Context, databaseURL string) error {
+	if err := requireTools("redis-cli"); err != nil {
+		return err
+	}
+	params, err := parseRedisURL(databaseURL)
+	if err != nil {
+		return fmt.Errorf("invalid Redis connection URL: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "redis-cli", append(rs.connectionArgs(params), "PING")...)
+	cmd.Env = os.Environ()
+	if params.Password != "" {
+		cmd.Env = append(cmd.Env, "REDISCLI_AUTH="+params.Password)
+	}
+	output, err := cmd.CombinedOutput()
+	// redis-cli reports error replies on stdout, not always with a failing exit code
+	if reply := strings.TrimSpace(string(output)); err != nil || !strings.HasSuffix(reply, "PONG") {
+		return preflightError(classifyConnectionError(reply), "cannot connect to %s:%s: %s", params.Host, params.Port, reply)
+	}
+	return nil
+}
+
+// connectionArgs returns the redis-cli connection arguments, without the password
+func (rs *RedisStrategy) connectionArgs(params *redisParams) []string {
+	args := []string{"-h", params.Host, "-p", params.Port}
+	if params.User != "" {
+		args = append(args, "--user", params.User)
+	}
+	if params.DB != "" {
+		args = append(args, "-n", params.DB)
+	}
+	if params.TLS {
+		args = append(args, "--tls")
+	}
+	return args
+}
+
+// reportProgress periodically reports the size of the RDB file until done is closed
+func (rs *RedisStrategy) reportProgress(outputPath string, done <-chan struct{}, callback ProgressCallback) {
+	if callback == nil {
+		return
+	}
+
+	ticker := time.NewTicker(progressReportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if fileInfo, err := os.Stat(outputPath); err == nil {
+				callback("redis", fmt.Sprintf("Received %s of RDB snapshot", formatBytes(fileInfo.Size())))
+			} else {
+				callback("redis", "Waiting for the server to finish the background save...")
+			}
+		}
+	}
+}
+
+// validateRDBFile checks that the file starts with a valid RDB header
+func validateRDBFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("RDB snapshot was not created: %w", err)
+	}
+	defer file.Close()
+
+	header := make([]byte, 9)
+	if _, err := io.ReadFull(bufio.NewReader(file), header); err != nil {
+		return fmt.Errorf("RDB snapshot is truncated: %w", err)
+	}
+	if !rdbHeaderPattern.Match(header) {
+		return fmt.Errorf("RDB snapshot has an invalid header %q", header)
+	}
+	return nil
+}
+
+// parseRedisURL parses redis:// and rediss:// (TLS) URLs
+func parseRedisURL(databaseURL string) (*redisParams, error) {
+	parsed, err := url.Parse(databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL format: %w", err)
+	}
+	if parsed.Scheme != "redis" && parsed.Scheme != "rediss" {
+		return nil, fmt.Errorf("invalid Redis URL format")
+	}
+	if parsed.Hostname() == "" {
+		return nil, fmt.Errorf("Redis URL must contain a host")
+	}
+
+	params := &redisParams{
+		Host: parsed.Hostname(),
+		Port: parsed.Port(),
+		DB:   strings.TrimPrefix(parsed.Path, "/"),
+		TLS:  parsed.Scheme == "rediss",
+	}
+	if params.Port == "" {
+		params.Port = "6379"
+	}
+	if parsed.User != nil {
+		params.User = parsed.User.Username()
+		params.Password, _ = parsed.User.Password()
+	}
+	if params.DB != "" {
+		if _, err := strconv.Atoi(params.DB); err != nil {
+			return nil, fmt.Errorf("invalid Redis database index '%s'", params.DB)
+		}
+	}
+
+	return params, nil
+}
@@ -0,0 +1,74 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"easy-backup/internal/config"
+)
+
+// replicationCheckTimeout bounds the time spent measuring the replication lag
+const replicationCheckTimeout = time.Minute
+
+// ReplicaStrategy is implemented by strategies that can measure the replication lag of
+// the server they back up. Servers that are not replicas report a lag of zero.
+type ReplicaStrategy interface {
+	ReplicationLag(ctx context.Context, databaseURL string) (time.Duration, error)
+}
+
+// resolveSourceURL checks the replication lag of the configured database and returns the
+// URL the backup is taken from. A lagging or broken replica fails the backup unless a
+// fallback URL is configured, which is then used instead.
+func (bs *BackupService) resolveSourceURL(ctx context.Context, dbStrategy DatabaseStrategy, strategyConfig config.StrategyConfig, result *BackupResult, progressCallback ProgressCallback) (string, error) {
+	if strategyConfig.MaxReplicationLag == "" {
+		return strategyConfig.DatabaseURL, nil
+	}
+
+	replica, ok := dbStrategy.(ReplicaStrategy)
+	if !ok {
+		return "", fmt.Errorf("database type %s does not support replication lag checks", strategyConfig.DatabaseType)
+	}
+	maxLag, err := config.ParseDuration(strategyConfig.MaxReplicationLag)
+	if err != nil {
+		return "", fmt.Errorf("invalid max_replication_lag: %w", err)
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, replicationCheckTimeout)
+	defer cancel()
+	lag, err := replica.ReplicationLag(checkCtx, strategyConfig.DatabaseURL)
+
+	var problem error
+	if err != nil {
+		problem = fmt.Errorf("failed to check replication lag: %w", err)
+	} else {
+		result.ReplicationLag = &lag
+		result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Replication lag: %s (max %s)", lag, maxLag))
+		if lag > maxLag {
+			problem = fmt.Errorf("replication lag %s exceeds max_replication_lag %s", lag, maxLag)
+		}
+	}
+	if problem == nil {
+		return strategyConfig.DatabaseURL, nil
+	}
+
+	if strategyConfig.FallbackDatabaseURL == "" {
+		result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Error: %s", problem.Error()))
+		return "", problem
+	}
+	if err := dbStrategy.ValidateConnection(strategyConfig.FallbackDatabaseURL); err != nil {
+		return "", fmt.Errorf("%s and the fallback URL is invalid: %w", problem.Error(), err)
+	}
+
+	bs.logger.WithError(problem).WithFields(logrus.Fields{
+		"strategy": strategyConfig.Name,
+	}).Warn("Replica not usable, backing up from the fallback URL")
+	result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("%s, using the fallback URL", problem.Error()))
+	if progressCallback != nil {
+		progressCallback(strategyConfig.Name, fmt.Sprintf("⚠️ %s, using the fallback URL", problem.Error()))
+	}
+	result.UsedFallback = true
+	return strategyConfig.FallbackDatabaseURL, nil
+}
@@ -0,0 +1,198 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SQLiteStrategy implements DatabaseStrategy for SQLite database files
+type SQLiteStrategy struct {
+	logger *logrus.Logger
+}
+
+// NewSQLiteStrategy creates a new SQLite backup strategy
+func NewSQLiteStrategy(logger *logrus.Logger) *SQLiteStrategy {
+	return &SQLiteStrategy{logger: logger}
+}
+
+// GetType returns the database type
+func (ss *SQLiteStrategy) GetType() string {
+	return "sqlite"
+}
+
+// ValidateConnection validates the SQLite URL
+func (ss *SQLiteStrategy) ValidateConnection(databaseURL string) error {
+	if _, err := parseSQLiteURL(databaseURL); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Preflight checks that sqlite3 is installed and the database file exists
+func (ss *SQLiteStrategy) Preflight(ctx context.Context, databaseURL string) error {
+	if err := requireTools("sqlite3"); err != nil {
+		return err
+	}
+	path, err := parseSQLiteURL(databaseURL)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("database file not accessible: %w", err)
+	}
+	return nil
+}
+
+// Backup takes an online-consistent snapshot of the database file with VACUUM INTO
+// and verifies the copy with PRAGMA integrity_check
+func (ss *SQLiteStrategy) Backup(ctx context.Context, databaseURL, outputPath string, callback ProgressCallback) (*BackupResult, error) {
+	result := &BackupResult{
+		CommandLogs: make([]string, 0),
+	}
+
+	if callback != nil {
+		callback("sqlite", "Starting SQLite backup...")
+	}
+
+	databasePath, err := parseSQLiteURL(databaseURL)
+	if err != nil {
+		return result, err
+	}
+	if _, err := os.Stat(databasePath); err != nil {
+		if callback != nil {
+			callback("sqlite", fmt.Sprintf("❌ Database file not accessible: %s", err.Error()))
+		}
+		return result, fmt.Errorf("database file not accessible: %w", err)
+	}
+
+	// VACUUM INTO refuses to overwrite an existing file
+	os.Remove(outputPath)
+
+	// VACUUM INTO reads the database in a single transaction, so the copy is consistent
+	// even while the service keeps writing to it
+	statement := fmt.Sprintf("VACUUM INTO '%s'", strings.ReplaceAll(outputPath, "'", "''"))
+	if _, err := ss.runSQLite(ctx, databasePath, statement, result); err != nil {
+		os.Remove(outputPath)
+		if callback != nil {
+			callback("sqlite", fmt.Sprintf("❌ SQLite snapshot failed: %s", err.Error()))
+		}
+		return result, err
+	}
+
+	if callback != nil {
+		callback("sqlite", "Snapshot created, running integrity check...")
+	}
+
+	output, err := ss.runSQLite(ctx, outputPath, "PRAGMA integrity_check", result)
+	if err != nil {
+		if callback != nil {
+			callback("sqlite", fmt.Sprintf("❌ Integrity check failed: %s", err.Error()))
+		}
+		return result, err
+	}
+	if output != "ok" {
+		errorMsg := fmt.Sprintf("integrity check of the snapshot failed: %s", output)
+		result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Error: %s", errorMsg))
+		if callback != nil {
+			callback("sqlite", fmt.Sprintf("❌ %s", errorMsg))
+		}
+		return result, fmt.Errorf("%s", errorMsg)
+	}
+
+	result.BackupPath = outputPath
+	if callback != nil {
+		callback("sqlite", "SQLite backup completed successfully")
+	}
+
+	return result, nil
+}
+
+// Restore replaces the database file at target with the backup. The file is written
+// next to the target first and renamed into place, so the service must be stopped.
+func (ss *SQLiteStrategy) Restore(ctx context.Context, target string, artifactPaths []string, callback ProgressCallback) error {
+	if len(artifactPaths) != 1 {
+		return fmt.Errorf("SQLite restore expects exactly one artifact, got %d", len(artifactPaths))
+	}
+
+	targetPath := target
+	if strings.HasPrefix(target, "sqlite://") {
+		parsed, err := parseSQLiteURL(target)
+		if err != nil {
+			return err
+		}
+		targetPath = parsed
+	}
+
+	input, err := openArtifact(artifactPaths[0])
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	if callback != nil {
+		callback("sqlite", fmt.Sprintf("Restoring %s into %s...", filepath.Base(artifactPaths[0]), targetPath))
+	}
+
+	tempFile, err := os.CreateTemp(filepath.Dir(targetPath), filepath.Base(targetPath)+".restore-")
+	if err != nil {
+		return fmt.Errorf("failed to create restore file: %w", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	if _, err := io.Copy(tempFile, input); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("failed to write restore file: %w", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return fmt.Errorf("failed to write restore file: %w", err)
+	}
+
+	if err := os.Rename(tempFile.Name(), targetPath); err != nil {
+		return fmt.Errorf("failed to replace database file: %w", err)
+	}
+
+	if callback != nil {
+		callback("sqlite", "SQLite restore completed successfully")
+	}
+	return nil
+}
+
+// runSQLite executes a single statement with the sqlite3 shell and returns its output
+func (ss *SQLiteStrategy) runSQLite(ctx context.Context, databasePath, statement string, result *BackupResult) (string, error) {
+	cmd := exec.CommandContext(ctx, "sqlite3", "-bail", databasePath, statement)
+	commandLog := fmt.Sprintf("Command: sqlite3 -bail %s \"%s\"", databasePath, statement)
+	result.CommandLogs = append(result.CommandLogs, commandLog)
+
+	output, err := cmd.CombinedOutput()
+	trimmed := strings.TrimSpace(string(output))
+	if trimmed != "" {
+		result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Output: %s", trimmed))
+	}
+	if err != nil {
+		return trimmed, fmt.Errorf("sqlite3 failed: %w, output: %s", err, trimmed)
+	}
+	return trimmed, nil
+}
+
+// parseSQLiteURL returns the database file path of a sqlite:// URL
+func parseSQLiteURL(databaseURL string) (string, error) {
+	if !strings.HasPrefix(databaseURL, "sqlite://") {
+		return "", fmt.Errorf("invalid SQLite URL format")
+	}
+
+	path := strings.TrimPrefix(databaseURL, "sqlite://")
+	if idx := strings.Index(path, "?"); idx != -1 {
+		path = path[:idx]
+	}
+	if path == "" {
+		return "", fmt.Errorf("SQLite URL must contain a database file path")
+	}
+	return path, nil
+}
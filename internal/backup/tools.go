@@ -0,0 +1,113 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VersionInfo describes the server a backup was taken from and the dump tool used
+type VersionInfo struct {
+	ServerVersion string
+	ToolVersion   string
+}
+
+// VersionedStrategy is implemented by strategies that pick their client tools based on the
+// server version. SelectTools returns a copy of the strategy using the matching tools.
+type VersionedStrategy interface {
+	SelectTools(ctx context.Context, databaseURL string) (DatabaseStrategy, *VersionInfo, error)
+}
+
+// clientTools resolves client binaries from directories configured per server major version
+type clientTools struct {
+	dirs     map[int]string // Directory of the client binaries per server major version
+	selected string         // Directory used for the current server, empty for PATH
+}
+
+// newClientTools creates the tool set from the configured tool_paths. The keys are
+// validated when the configuration is loaded.
+func newClientTools(toolPaths map[string]string) clientTools {
+	tools := clientTools{dirs: make(map[int]string)}
+	for version, dir := range toolPaths {
+		if major, err := strconv.Atoi(version); err == nil {
+			tools.dirs[major] = dir
+		}
+	}
+	return tools
+}
+
+// path returns the binary to run for a tool name: the one of the selected directory,
+// otherwise the one in PATH, falling back to the newest configured directory
+func (ct clientTools) path(name string) string {
+	if ct.selected != "" {
+		return filepath.Join(ct.selected, name)
+	}
+	if _, err := exec.LookPath(name); err == nil || len(ct.dirs) == 0 {
+		return name
+	}
+	newest := -1
+	for major := range ct.dirs {
+		if major > newest {
+			newest = major
+		}
+	}
+	return filepath.Join(ct.dirs[newest], name)
+}
+
+// forServer selects the directory of the lowest configured major version that is not
+// older than the server. Servers newer than every configured version use the default tools.
+func (ct clientTools) forServer(serverMajor int) clientTools {
+	majors := make([]int, 0, len(ct.dirs))
+	for major := range ct.dirs {
+		majors = append(majors, major)
+	}
+	sort.Ints(majors)
+
+	selected := clientTools{dirs: ct.dirs}
+	for _, major := range majors {
+		if major >= serverMajor {
+			selected.selected = ct.dirs[major]
+			break
+		}
+	}
+	return selected
+}
+
+// selectTools lets strategies that support it pick the client tools matching the server
+// version and records the detected versions
+func (bs *BackupService) selectTools(ctx context.Context, dbStrategy DatabaseStrategy, databaseURL string, result *BackupResult) (DatabaseStrategy, error) {
+	versioned, ok := dbStrategy.(VersionedStrategy)
+	if !ok {
+		return dbStrategy, nil
+	}
+
+	selectCtx, cancel := context.WithTimeout(ctx, preflightTimeout)
+	defer cancel()
+	selected, versions, err := versioned.SelectTools(selectCtx, databaseURL)
+	if err != nil {
+		result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Error: %s", err.Error()))
+		return nil, err
+	}
+
+	result.ServerVersion = versions.ServerVersion
+	result.ToolVersion = versions.ToolVersion
+	if versions.ServerVersion != "" {
+		result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Server version: %s", versions.ServerVersion))
+	}
+	result.CommandLogs = append(result.CommandLogs, fmt.Sprintf("Tool version: %s", versions.ToolVersion))
+	return selected, nil
+}
+
+// toolVersion returns the first line of the --version output of a tool
+func toolVersion(ctx context.Context, tool string) (string, error) {
+	output, err := toolOutput(ctx, tool, "--version")
+	if err != nil {
+		return "", preflightError(PreflightToolMissing, "%s --version failed: %s", tool, output)
+	}
+	firstLine, _, _ := strings.Cut(output, "\n")
+	return firstLine, nil
+}
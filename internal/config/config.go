@@ -2,9 +2,15 @@ package config
 
 import (
 	"fmt"
+	"net/mail"
 	"os"
+	"path"
+	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -18,18 +24,155 @@ type Config struct {
 
 // GlobalConfig contains default configurations for all strategies
 type GlobalConfig struct {
-	Slack            SlackConfig      `yaml:"slack"`
-	LogLevel         string           `yaml:"log_level"`
-	Schedule         string           `yaml:"schedule"`
-	Retention        string           `yaml:"retention"`
-	Timezone         string           `yaml:"timezone"`
-	TempDir          string           `yaml:"temp_dir"`
-	MaxParallel      int              `yaml:"max_parallel_strategies"`
-	ExecuteOnStartup bool             `yaml:"execute_on_startup"`
-	Retry            RetryConfig      `yaml:"retry"`
-	Timeout          TimeoutConfig    `yaml:"timeout"`
-	S3               S3Config         `yaml:"s3"`
-	Monitoring       MonitoringConfig `yaml:"monitoring"`
+	Slack            SlackConfig         `yaml:"slack"`
+	SlackCommands    SlackCommandsConfig `yaml:"slack_commands,omitempty"`
+	Teams            TeamsConfig         `yaml:"teams,omitempty"`
+	Discord          DiscordConfig       `yaml:"discord,omitempty"`
+	LogLevel         string              `yaml:"log_level"`
+	Schedule         string              `yaml:"schedule"`
+	Retention        string              `yaml:"retention"`
+	StaleAfter       string              `yaml:"stale_after,omitempty"` // Notify when a strategy has not succeeded for this long, e.g. 26h
+	Timezone         string              `yaml:"timezone"`
+	TempDir          string              `yaml:"temp_dir"`
+	MaxParallel      int                 `yaml:"max_parallel_strategies"`
+	ExecuteOnStartup bool                `yaml:"execute_on_startup"`
+	Retry            RetryConfig         `yaml:"retry"`
+	Timeout          TimeoutConfig       `yaml:"timeout"`
+	S3               S3Config            `yaml:"s3"`
+	Monitoring       MonitoringConfig    `yaml:"monitoring"`
+	Notifications    NotificationsConfig `yaml:"notifications"`
+}
+
+// NotificationsConfig contains the notification channels used besides Slack
+type NotificationsConfig struct {
+	Webhooks []WebhookConfig `yaml:"webhooks,omitempty"`
+	Email    EmailConfig     `yaml:"email,omitempty"`
+	Alerting AlertingConfig  `yaml:"alerting,omitempty"`
+	Reports  []ReportConfig  `yaml:"reports,omitempty"`
+
+	// Routes select the notifiers of each event type. Without routes every notifier
+	// receives every event.
+	Routes []NotificationRoute `yaml:"routes,omitempty"`
+}
+
+// Notification event types
+const (
+	EventStarted  = "started"  // A backup run started
+	EventProgress = "progress" // Progress of a running backup
+	EventOutput   = "output"   // Errors and warnings printed by the dump tools
+	EventResult   = "result"   // A backup run finished
+	EventError    = "error"    // Detailed information about a failed backup
+	EventRetry    = "retry"    // A failed attempt is retried
+	EventSuccess  = "success"  // A backup run succeeded
+	EventFailure  = "failure"  // A backup run failed after all retries
+	EventStale    = "stale"    // A strategy did not back up successfully within stale_after
+	EventReport   = "report"   // Scheduled report of the backups of every strategy
+)
+
+// Notifier names used by notification routes
+const (
+	NotifierSlack    = "slack"
+	NotifierTeams    = "teams"
+	NotifierDiscord  = "discord"
+	NotifierEmail    = "email"
+	NotifierAlerting = "alerting"
+	NotifierWebhook  = "webhook" // All webhooks, or a single one as webhook:<name>
+)
+
+// NotificationRoute sends the selected event types to a notifier
+type NotificationRoute struct {
+	Events    []string `yaml:"events"`               // started, progress, retry, success, failure, stale, report
+	Notifier  string   `yaml:"notifier"`             // slack, teams, discord, email, alerting, webhook or webhook:<name>
+	ChannelID string   `yaml:"channel_id,omitempty"` // Slack channel of the route, defaults to the strategy's channel
+	Mention   string   `yaml:"mention,omitempty"`    // Prepended to the Slack messages of the route, e.g. @here or <@U012AB3CD>
+}
+
+// ReportConfig schedules a report of the backups of every strategy over a period, e.g. a
+// daily or weekly report for managers
+type ReportConfig struct {
+	Schedule string `yaml:"schedule"`         // Cron schedule of the report
+	Period   string `yaml:"period,omitempty"` // Period covered by the report, defaults to 1d
+}
+
+// WebhookConfig describes an HTTP endpoint receiving backup events as JSON
+type WebhookConfig struct {
+	Name    string            `yaml:"name,omitempty"`
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+	Secret  string            `yaml:"secret,omitempty"`  // Signs the body with HMAC-SHA256 in X-Easy-Backup-Signature
+	Events  []string          `yaml:"events,omitempty"`  // Defaults to started, result and error
+	Timeout string            `yaml:"timeout,omitempty"` // Defaults to 10s
+}
+
+// Email TLS modes
+const (
+	EmailTLSStartTLS = "starttls" // Upgrade the connection with STARTTLS, usually on port 587
+	EmailTLSImplicit = "tls"      // Connect with TLS, usually on port 465
+	EmailTLSNone     = "none"     // Plain connection, e.g. to a local relay
+)
+
+// EmailConfig contains the SMTP server and defaults of email notifications
+type EmailConfig struct {
+	Host      string   `yaml:"host"`
+	Port      int      `yaml:"port,omitempty"` // Defaults to 587, 465 with tls: tls and 25 with tls: none
+	Username  string   `yaml:"username,omitempty"`
+	Password  string   `yaml:"password,omitempty"`
+	From      string   `yaml:"from"`
+	To        []string `yaml:"to,omitempty"`         // Recipients of strategies without their own
+	TLS       string   `yaml:"tls,omitempty"`        // starttls (default), tls or none
+	OnSuccess bool     `yaml:"on_success,omitempty"` // Also send successful results, failures are always sent
+	Digest    string   `yaml:"digest,omitempty"`     // Cron schedule of a digest of the results since the previous one
+	Timeout   string   `yaml:"timeout,omitempty"`    // Defaults to 30s
+}
+
+// Enabled reports whether email notifications are configured
+func (e EmailConfig) Enabled() bool {
+	return e.Host != ""
+}
+
+// Alert severities, mapped to Opsgenie priorities P1, P2, P3 and P5
+const (
+	SeverityCritical = "critical"
+	SeverityError    = "error"
+	SeverityWarning  = "warning"
+	SeverityInfo     = "info"
+)
+
+// AlertingConfig contains the incident management services paged for failed backups
+type AlertingConfig struct {
+	PagerDuty PagerDutyConfig `yaml:"pagerduty,omitempty"`
+	Opsgenie  OpsgenieConfig  `yaml:"opsgenie,omitempty"`
+	Severity  string          `yaml:"severity,omitempty"` // Severity of failed backups, defaults to error
+}
+
+// Enabled reports whether failed backups page through any service
+func (ac AlertingConfig) Enabled() bool {
+	return ac.PagerDuty.RoutingKey != "" || ac.Opsgenie.APIKey != ""
+}
+
+// PagerDutyConfig contains the PagerDuty Events API v2 integration
+type PagerDutyConfig struct {
+	RoutingKey string `yaml:"routing_key"`
+	URL        string `yaml:"url,omitempty"` // Defaults to https://events.pagerduty.com/v2/enqueue
+}
+
+// OpsgenieConfig contains the Opsgenie Alert API integration
+type OpsgenieConfig struct {
+	APIKey string `yaml:"api_key"`
+	URL    string `yaml:"url,omitempty"` // Defaults to https://api.opsgenie.com, use https://api.eu.opsgenie.com for the EU instance
+}
+
+// StrategyAlertingConfig overrides the alerting settings of a strategy
+type StrategyAlertingConfig struct {
+	Disabled   bool   `yaml:"disabled,omitempty"`
+	Severity   string `yaml:"severity,omitempty"`
+	RoutingKey string `yaml:"routing_key,omitempty"` // PagerDuty routing key of the strategy's service
+	DedupKey   string `yaml:"dedup_key,omitempty"`   // Defaults to easy-backup/<strategy name>
+}
+
+// StrategyEmailConfig contains the email recipients of a strategy
+type StrategyEmailConfig struct {
+	To []string `yaml:"to,omitempty"`
 }
 
 // SlackConfig contains Slack notification settings
@@ -38,6 +181,30 @@ type SlackConfig struct {
 	ChannelID string `yaml:"channel_id"`
 }
 
+// SlackCommandsConfig enables the slash command and the "Retry now" buttons of Slack failure
+// messages, served by the monitoring HTTP server
+type SlackCommandsConfig struct {
+	SigningSecret string   `yaml:"signing_secret"`           // Signing secret of the Slack app, enables the endpoint
+	Path          string   `yaml:"path,omitempty"`           // Request URL path of commands and interactivity, defaults to /slack/commands
+	AllowedUsers  []string `yaml:"allowed_users,omitempty"`  // Slack user IDs allowed to run backups, e.g. U012AB3CD
+	AllowedGroups []string `yaml:"allowed_groups,omitempty"` // Slack user group IDs whose members may run backups, e.g. S0614TZR7
+}
+
+// Enabled reports whether Slack commands are configured
+func (c SlackCommandsConfig) Enabled() bool {
+	return c.SigningSecret != ""
+}
+
+// TeamsConfig contains Microsoft Teams notification settings
+type TeamsConfig struct {
+	WebhookURL string `yaml:"webhook_url"` // Incoming webhook or workflow URL of the channel
+}
+
+// DiscordConfig contains Discord notification settings
+type DiscordConfig struct {
+	WebhookURL string `yaml:"webhook_url"` // Webhook URL of the channel
+}
+
 // RetryConfig contains retry settings
 type RetryConfig struct {
 	MaxAttempts int `yaml:"max_attempts"`
@@ -86,27 +253,167 @@ type HealthCheckConfig struct {
 
 // StrategyConfig contains configuration for a specific backup strategy
 type StrategyConfig struct {
-	Name         string      `yaml:"name"`
-	DatabaseType string      `yaml:"database_type"` // postgres, mysql, mongodb
-	DatabaseURL  string      `yaml:"database_url"`
-	Schedule     string      `yaml:"schedule,omitempty"`
-	Retention    string      `yaml:"retention,omitempty"`
-	Slack        SlackConfig `yaml:"slack,omitempty"`
+	Name                string                   `yaml:"name"`
+	DatabaseType        string                   `yaml:"database_type"` // postgres, mysql, mariadb, mongodb, mariabackup, xtrabackup, sqlite, redis, files, command, etcd
+	DatabaseURL         string                   `yaml:"database_url"`
+	Schedule            string                   `yaml:"schedule,omitempty"`
+	Retention           string                   `yaml:"retention,omitempty"`
+	StaleAfter          string                   `yaml:"stale_after,omitempty"`
+	Slack               SlackConfig              `yaml:"slack,omitempty"`
+	Teams               TeamsConfig              `yaml:"teams,omitempty"`
+	Discord             DiscordConfig            `yaml:"discord,omitempty"`
+	Email               StrategyEmailConfig      `yaml:"email,omitempty"`
+	Alerting            StrategyAlertingConfig   `yaml:"alerting,omitempty"`
+	NotificationRoutes  []NotificationRoute      `yaml:"notification_routes,omitempty"` // Replace the global routes
+	MySQL               MySQLConfig              `yaml:"mysql,omitempty"`
+	Physical            PhysicalConfig           `yaml:"physical,omitempty"`
+	MongoDB             MongoDBConfig            `yaml:"mongodb,omitempty"`
+	Files               FilesConfig              `yaml:"files,omitempty"`
+	Command             CommandConfig            `yaml:"command,omitempty"`
+	Etcd                EtcdConfig               `yaml:"etcd,omitempty"`
+	Hooks               HooksConfig              `yaml:"hooks,omitempty"`
+	MaxReplicationLag   string                   `yaml:"max_replication_lag,omitempty"`   // Checked before the backup when set, e.g. 5m
+	FallbackDatabaseURL string                   `yaml:"fallback_database_url,omitempty"` // Used instead of a lagging or broken replica
+	ToolPaths           map[string]string        `yaml:"tool_paths,omitempty"`            // Client binary directories per server major version
+	DynamicCredentials  DynamicCredentialsConfig `yaml:"dynamic_credentials,omitempty"`
+}
+
+// DynamicCredentialsConfig describes where short-lived database credentials are fetched
+// from before each run. They replace the user and password of the database URLs.
+type DynamicCredentialsConfig struct {
+	VaultPath string   `yaml:"vault_path,omitempty"` // Vault database secrets engine path, e.g. database/creds/backup
+	Command   string   `yaml:"command,omitempty"`    // Credential helper printing {"username": "...", "password": "..."}
+	Args      []string `yaml:"args,omitempty"`
+	Timeout   string   `yaml:"timeout,omitempty"` // Defaults to 30s
+}
+
+// Enabled reports whether dynamic credentials are configured
+func (dc DynamicCredentialsConfig) Enabled() bool {
+	return dc.VaultPath != "" || dc.Command != ""
+}
+
+// HooksConfig contains the hooks run around the backups of a strategy
+type HooksConfig struct {
+	PreBackup  []HookConfig `yaml:"pre_backup,omitempty"`  // Run before the backup, a failure aborts the backup
+	PostBackup []HookConfig `yaml:"post_backup,omitempty"` // Run after every backup, successful or not
+	OnSuccess  []HookConfig `yaml:"on_success,omitempty"`  // Run after a successful backup and upload
+	OnFailure  []HookConfig `yaml:"on_failure,omitempty"`  // Run after a failed backup
+}
+
+// HookConfig describes a shell command or HTTP call run as a hook
+type HookConfig struct {
+	Name            string            `yaml:"name,omitempty"`
+	Command         string            `yaml:"command,omitempty"` // Shell command run with sh -c
+	URL             string            `yaml:"url,omitempty"`     // HTTP endpoint receiving the run details as JSON
+	Method          string            `yaml:"method,omitempty"`  // HTTP method, defaults to POST
+	Headers         map[string]string `yaml:"headers,omitempty"`
+	Timeout         string            `yaml:"timeout,omitempty"`           // Defaults to 5m
+	ContinueOnError bool              `yaml:"continue_on_error,omitempty"` // Do not abort the backup when a pre_backup hook fails
+}
+
+// EtcdConfig contains the connection settings of etcd snapshot strategies
+type EtcdConfig struct {
+	Endpoints []string `yaml:"endpoints,omitempty"` // Tried in order until a snapshot succeeds
+	CACert    string   `yaml:"cacert,omitempty"`    // CA bundle to verify the server certificates
+	Cert      string   `yaml:"cert,omitempty"`      // Client certificate
+	Key       string   `yaml:"key,omitempty"`       // Client certificate key
+}
+
+// CommandConfig describes an external dump tool run by command strategies.
+// Args are Go templates with {{.OutputPath}}, {{.DatabaseURL}} and {{.StrategyName}}
+// available. Secrets are read by the command from its environment, never from its args.
+type CommandConfig struct {
+	Command       string   `yaml:"command,omitempty"`        // Executable name or path
+	Args          []string `yaml:"args,omitempty"`           // Templated arguments
+	Stdout        bool     `yaml:"stdout,omitempty"`         // Write the command's stdout to the output file
+	ExitCodes     []int    `yaml:"exit_codes,omitempty"`     // Exit codes treated as success, defaults to 0
+	ErrorPatterns []string `yaml:"error_patterns,omitempty"` // Regular expressions of output lines reported as errors
+	Extension     string   `yaml:"extension,omitempty"`      // Output file extension, e.g. .snapshot
+}
+
+// FilesConfig contains settings for filesystem backups
+type FilesConfig struct {
+	Paths          []string `yaml:"paths,omitempty"`           // Files or directories to archive
+	Include        []string `yaml:"include,omitempty"`         // Glob patterns of files to archive; all files when empty
+	Exclude        []string `yaml:"exclude,omitempty"`         // Glob patterns of files and directories to skip
+	FollowSymlinks bool     `yaml:"follow_symlinks,omitempty"` // Archive the files symlinks point to instead of the links
+}
+
+// MongoDBConfig contains MongoDB specific strategy settings
+type MongoDBConfig struct {
+	Archive            bool     `yaml:"archive,omitempty"`             // Write a single gzip-compressed archive instead of a directory dump
+	Oplog              bool     `yaml:"oplog,omitempty"`               // Capture the oplog for a point-in-time consistent dump (replica sets only)
+	IncludeCollections []string `yaml:"include_collections,omitempty"` // Collections of the URL database to dump
+	ExcludeCollections []string `yaml:"exclude_collections,omitempty"` // Collections of the URL database to skip
+	Query              string   `yaml:"query,omitempty"`               // Extended JSON filter applied to the included collections
+	ReadPreference     string   `yaml:"read_preference,omitempty"`     // e.g. secondary, secondaryPreferred, nearest
 }
 
-// LoadConfig loads configuration from a YAML file
+// PhysicalConfig contains settings for physical MySQL/MariaDB backups (mariabackup/xtrabackup)
+type PhysicalConfig struct {
+	DataDir  string `yaml:"datadir,omitempty"`  // Server data directory, if it cannot be read from the server
+	Parallel int    `yaml:"parallel,omitempty"` // Number of threads copying data files
+}
+
+// MySQLConfig contains MySQL/MariaDB specific strategy settings
+type MySQLConfig struct {
+	AllDatabases           bool         `yaml:"all_databases,omitempty"`            // Dump every database on the server
+	Databases              []string     `yaml:"databases,omitempty"`                // Database names or glob patterns (e.g. tenant_*)
+	ExcludeDatabases       []string     `yaml:"exclude_databases,omitempty"`        // Database names or glob patterns to skip
+	IncludeSystemDatabases bool         `yaml:"include_system_databases,omitempty"` // Include mysql, sys, information_schema, performance_schema
+	SplitArtifacts         bool         `yaml:"split_artifacts,omitempty"`          // Store one artifact per database instead of a combined dump
+	Binlog                 BinlogConfig `yaml:"binlog,omitempty"`
+}
+
+// BinlogConfig contains settings for binlog-based incremental MySQL backups. Increments only
+// keep the statements whose default database is the database of the URL.
+type BinlogConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Schedule string `yaml:"schedule"` // Cron expression for fetching binary logs since the last backup
+}
+
+// MultiDatabase reports whether the strategy dumps several databases instead of the one in the URL.
+// Every database is dumped in its own transaction, so the dumps are not consistent with each other.
+func (mc MySQLConfig) MultiDatabase() bool {
+	return mc.AllDatabases || len(mc.Databases) > 0
+}
+
+// LoadConfig loads configuration from a YAML file and resolves the ${...} references
+// in its values
 func LoadConfig(filepath string) (*Config, error) {
+	return loadConfig(filepath, true)
+}
+
+// LoadConfigUnresolved loads configuration from a YAML file, keeping ${...} references
+// as written. It allows validating a config outside the environment it is deployed to.
+func LoadConfigUnresolved(filepath string) (*Config, error) {
+	return loadConfig(filepath, false)
+}
+
+// loadConfig loads configuration from a YAML file
+func loadConfig(filepath string, resolveReferences bool) (*Config, error) {
 	data, err := os.ReadFile(filepath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	// Substitute environment variables
-	configData := substituteEnvVars(string(data))
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	// Substitute environment variables, files and secret store values
+	if resolveReferences {
+		if err := substituteReferences(&document); err != nil {
+			return nil, fmt.Errorf("failed to resolve config file: %w", err)
+		}
+	}
 
 	var config Config
-	if err := yaml.Unmarshal([]byte(configData), &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	if document.Kind != 0 {
+		if err := document.Decode(&config); err != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
 	}
 
 	// Set defaults
@@ -186,6 +493,18 @@ func setDefaults(config *Config) error {
 	if config.Global.Monitoring.HealthCheck.Path == "" {
 		config.Global.Monitoring.HealthCheck.Path = "/health"
 	}
+	if err := validateNotificationsConfig(&config.Global.Notifications); err != nil {
+		return err
+	}
+	if err := validateSlackCommandsConfig(&config.Global); err != nil {
+		return fmt.Errorf("slack_commands: %w", err)
+	}
+	if err := validateChatWebhookURL("teams", config.Global.Teams.WebhookURL); err != nil {
+		return fmt.Errorf("global: %w", err)
+	}
+	if err := validateChatWebhookURL("discord", config.Global.Discord.WebhookURL); err != nil {
+		return fmt.Errorf("global: %w", err)
+	}
 
 	// Apply global defaults to strategies
 	for i := range config.Strategies {
@@ -195,10 +514,60 @@ func setDefaults(config *Config) error {
 		}
 		// Validate database type
 		switch strategy.DatabaseType {
-		case "postgres", "mysql", "mariadb", "mongodb":
+		case "postgres", "mysql", "mariadb", "mongodb", "mariabackup", "xtrabackup", "sqlite", "redis", "files", "command", "etcd":
 			// Valid database types
 		default:
-			return fmt.Errorf("unsupported database type '%s' for strategy '%s'. Supported types: postgres, mysql, mariadb, mongodb", strategy.DatabaseType, strategy.Name)
+			return fmt.Errorf("unsupported database type '%s' for strategy '%s'. Supported types: postgres, mysql, mariadb, mongodb, mariabackup, xtrabackup, sqlite, redis, files, command, etcd", strategy.DatabaseType, strategy.Name)
+		}
+		if err := validateMySQLConfig(strategy); err != nil {
+			return err
+		}
+		if err := validateMongoDBConfig(strategy); err != nil {
+			return err
+		}
+		if err := validateFilesConfig(strategy); err != nil {
+			return err
+		}
+		if err := validateCommandConfig(strategy); err != nil {
+			return err
+		}
+		if err := validateEtcdConfig(strategy); err != nil {
+			return err
+		}
+		if err := validateHooksConfig(strategy); err != nil {
+			return err
+		}
+		if err := validateReplicationConfig(strategy); err != nil {
+			return err
+		}
+		if err := validateToolPaths(strategy); err != nil {
+			return err
+		}
+		if err := validateDynamicCredentials(strategy); err != nil {
+			return err
+		}
+		if err := validateStrategyEmail(strategy, config.Global.Notifications.Email); err != nil {
+			return err
+		}
+		if err := validateStrategyAlerting(strategy, config.Global.Notifications.Alerting); err != nil {
+			return err
+		}
+		if err := validateNotificationRoutes(strategy.NotificationRoutes, config.Global.Notifications.Webhooks); err != nil {
+			return fmt.Errorf("strategy '%s': notification_routes: %w", strategy.Name, err)
+		}
+		if strategy.StaleAfter == "" {
+			strategy.StaleAfter = config.Global.StaleAfter
+		}
+		if strategy.StaleAfter != "" {
+			if staleAfter, err := ParseDuration(strategy.StaleAfter); err != nil || staleAfter <= 0 {
+				return fmt.Errorf("strategy '%s': invalid stale_after '%s'", strategy.Name, strategy.StaleAfter)
+			}
+		}
+		if err := validateChatWebhookURL("teams", strategy.Teams.WebhookURL); err != nil {
+			return fmt.Errorf("strategy '%s': %w", strategy.Name, err)
+		}
+		if err := validateChatWebhookURL("discord", strategy.Discord.WebhookURL); err != nil {
+			return fmt.Errorf("strategy '%s': %w", strategy.Name, err)
 		}
 		if strategy.Schedule == "" {
 			strategy.Schedule = config.Global.Schedule
@@ -212,6 +581,523 @@ func setDefaults(config *Config) error {
 		if strategy.Slack.ChannelID == "" {
 			strategy.Slack.ChannelID = config.Global.Slack.ChannelID
 		}
+		if strategy.Teams.WebhookURL == "" {
+			strategy.Teams.WebhookURL = config.Global.Teams.WebhookURL
+		}
+		if strategy.Discord.WebhookURL == "" {
+			strategy.Discord.WebhookURL = config.Global.Discord.WebhookURL
+		}
+	}
+
+	return nil
+}
+
+// validateNotificationsConfig validates the notification channels and sets their defaults
+func validateNotificationsConfig(notifications *NotificationsConfig) error {
+	for i := range notifications.Webhooks {
+		webhook := &notifications.Webhooks[i]
+		if webhook.Name == "" {
+			webhook.Name = fmt.Sprintf("webhook-%d", i+1)
+		}
+		if !strings.HasPrefix(webhook.URL, "http://") && !strings.HasPrefix(webhook.URL, "https://") {
+			return fmt.Errorf("webhook '%s': url must be an http:// or https:// URL", webhook.Name)
+		}
+		if len(webhook.Events) == 0 {
+			webhook.Events = []string{EventStarted, EventResult, EventError}
+		}
+		for _, event := range webhook.Events {
+			switch event {
+			case EventStarted, EventProgress, EventOutput, EventRetry, EventResult, EventError, EventStale, EventReport:
+			default:
+				return fmt.Errorf("webhook '%s': unsupported event '%s'. Supported events: started, progress, output, retry, result, error, stale, report", webhook.Name, event)
+			}
+		}
+		if webhook.Timeout == "" {
+			webhook.Timeout = "10s"
+		}
+		if timeout, err := time.ParseDuration(webhook.Timeout); err != nil || timeout <= 0 {
+			return fmt.Errorf("webhook '%s': invalid timeout '%s'", webhook.Name, webhook.Timeout)
+		}
+	}
+	if err := validateEmailConfig(&notifications.Email); err != nil {
+		return err
+	}
+	if err := validateAlertingConfig(&notifications.Alerting); err != nil {
+		return err
+	}
+	for i := range notifications.Reports {
+		report := &notifications.Reports[i]
+		if report.Schedule == "" {
+			return fmt.Errorf("report %d: schedule is required", i+1)
+		}
+		if report.Period == "" {
+			report.Period = "1d"
+		}
+		if period, err := ParseDuration(report.Period); err != nil || period <= 0 {
+			return fmt.Errorf("report %d: invalid period '%s'", i+1, report.Period)
+		}
+	}
+	if err := validateNotificationRoutes(notifications.Routes, notifications.Webhooks); err != nil {
+		return fmt.Errorf("notifications: %w", err)
+	}
+	return nil
+}
+
+// validateSlackCommandsConfig sets the default path of Slack commands and checks that only
+// allowed users can run backups
+func validateSlackCommandsConfig(global *GlobalConfig) error {
+	commands := &global.SlackCommands
+	if !commands.Enabled() {
+		if commands.Path != "" || len(commands.AllowedUsers) > 0 || len(commands.AllowedGroups) > 0 {
+			return fmt.Errorf("signing_secret is required")
+		}
+		return nil
+	}
+
+	if commands.Path == "" {
+		commands.Path = "/slack/commands"
+	}
+	if !strings.HasPrefix(commands.Path, "/") {
+		return fmt.Errorf("path must start with '/'")
+	}
+	if commands.Path == global.Monitoring.HealthCheck.Path || commands.Path == global.Monitoring.Metrics.Path {
+		return fmt.Errorf("path '%s' is already used by the monitoring server", commands.Path)
+	}
+	if len(commands.AllowedUsers) == 0 && len(commands.AllowedGroups) == 0 {
+		return fmt.Errorf("allowed_users or allowed_groups is required")
+	}
+	return nil
+}
+
+// validateNotificationRoutes checks the event types and notifiers of routes
+func validateNotificationRoutes(routes []NotificationRoute, webhooks []WebhookConfig) error {
+	for i, route := range routes {
+		if len(route.Events) == 0 {
+			return fmt.Errorf("route %d: events are required", i+1)
+		}
+		for _, event := range route.Events {
+			switch event {
+			case EventStarted, EventProgress, EventRetry, EventSuccess, EventFailure, EventStale, EventReport:
+			default:
+				return fmt.Errorf("route %d: unsupported event '%s'. Supported events: started, progress, retry, success, failure, stale, report", i+1, event)
+			}
+		}
+
+		switch name, webhook, _ := strings.Cut(route.Notifier, ":"); name {
+		case NotifierSlack, NotifierTeams, NotifierDiscord, NotifierEmail, NotifierAlerting:
+			if webhook != "" {
+				return fmt.Errorf("route %d: unsupported notifier '%s'", i+1, route.Notifier)
+			}
+		case NotifierWebhook:
+			if webhook != "" && !slices.ContainsFunc(webhooks, func(w WebhookConfig) bool { return w.Name == webhook }) {
+				return fmt.Errorf("route %d: unknown webhook '%s'", i+1, webhook)
+			}
+		default:
+			return fmt.Errorf("route %d: unsupported notifier '%s'. Supported notifiers: slack, teams, discord, email, alerting, webhook, webhook:<name>", i+1, route.Notifier)
+		}
+		if route.Notifier != NotifierSlack && (route.ChannelID != "" || route.Mention != "") {
+			return fmt.Errorf("route %d: channel_id and mention are only supported by slack routes", i+1)
+		}
+	}
+	return nil
+}
+
+// validateAlertingConfig validates the incident management services and sets their defaults
+func validateAlertingConfig(alerting *AlertingConfig) error {
+	if alerting.Severity == "" {
+		alerting.Severity = SeverityError
+	}
+	if err := validateSeverity(alerting.Severity); err != nil {
+		return fmt.Errorf("alerting: %w", err)
+	}
+	if alerting.PagerDuty.URL == "" {
+		alerting.PagerDuty.URL = "https://events.pagerduty.com/v2/enqueue"
+	}
+	if alerting.Opsgenie.URL == "" {
+		alerting.Opsgenie.URL = "https://api.opsgenie.com"
+	}
+	if !strings.HasPrefix(alerting.PagerDuty.URL, "https://") && !strings.HasPrefix(alerting.PagerDuty.URL, "http://") {
+		return fmt.Errorf("alerting: pagerduty.url must be an http:// or https:// URL")
+	}
+	if !strings.HasPrefix(alerting.Opsgenie.URL, "https://") && !strings.HasPrefix(alerting.Opsgenie.URL, "http://") {
+		return fmt.Errorf("alerting: opsgenie.url must be an http:// or https:// URL")
+	}
+	return nil
+}
+
+// validateStrategyAlerting validates the alerting overrides of a strategy
+func validateStrategyAlerting(strategy *StrategyConfig, alerting AlertingConfig) error {
+	overrides := strategy.Alerting
+	if overrides.Severity == "" && overrides.DedupKey == "" && overrides.RoutingKey == "" {
+		return nil
+	}
+	if !alerting.Enabled() && overrides.RoutingKey == "" {
+		return fmt.Errorf("strategy '%s': alerting requires global.notifications.alerting or alerting.routing_key", strategy.Name)
+	}
+	if overrides.Severity != "" {
+		if err := validateSeverity(overrides.Severity); err != nil {
+			return fmt.Errorf("strategy '%s': alerting: %w", strategy.Name, err)
+		}
+	}
+	return nil
+}
+
+// validateSeverity checks an alert severity
+func validateSeverity(severity string) error {
+	switch severity {
+	case SeverityCritical, SeverityError, SeverityWarning, SeverityInfo:
+		return nil
+	default:
+		return fmt.Errorf("unsupported severity '%s'. Supported severities: critical, error, warning, info", severity)
+	}
+}
+
+// validateChatWebhookURL checks the webhook URL of a Teams or Discord channel
+func validateChatWebhookURL(field, webhookURL string) error {
+	if webhookURL != "" && !strings.HasPrefix(webhookURL, "https://") && !strings.HasPrefix(webhookURL, "http://") {
+		return fmt.Errorf("%s.webhook_url must be an http:// or https:// URL", field)
+	}
+	return nil
+}
+
+// validateEmailConfig validates the SMTP settings of email notifications and sets their defaults
+func validateEmailConfig(email *EmailConfig) error {
+	if !email.Enabled() {
+		if email.From != "" || len(email.To) > 0 || email.Digest != "" {
+			return fmt.Errorf("email notifications require a host")
+		}
+		return nil
+	}
+
+	if email.TLS == "" {
+		email.TLS = EmailTLSStartTLS
+	}
+	switch email.TLS {
+	case EmailTLSStartTLS:
+		if email.Port == 0 {
+			email.Port = 587
+		}
+	case EmailTLSImplicit:
+		if email.Port == 0 {
+			email.Port = 465
+		}
+	case EmailTLSNone:
+		if email.Port == 0 {
+			email.Port = 25
+		}
+	default:
+		return fmt.Errorf("email: unsupported tls mode '%s'. Supported modes: starttls, tls, none", email.TLS)
+	}
+
+	if email.From == "" {
+		return fmt.Errorf("email: from is required")
+	}
+	if _, err := mail.ParseAddress(email.From); err != nil {
+		return fmt.Errorf("email: invalid from address '%s': %w", email.From, err)
+	}
+	if err := validateEmailAddresses(email.To); err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+	if email.Digest != "" && len(email.To) == 0 {
+		return fmt.Errorf("email: digest requires to")
+	}
+	if email.Password != "" && email.Username == "" {
+		return fmt.Errorf("email: password requires a username")
+	}
+
+	if email.Timeout == "" {
+		email.Timeout = "30s"
+	}
+	if timeout, err := time.ParseDuration(email.Timeout); err != nil || timeout <= 0 {
+		return fmt.Errorf("email: invalid timeout '%s'", email.Timeout)
+	}
+	return nil
+}
+
+// validateStrategyEmail validates the email recipients of a strategy
+func validateStrategyEmail(strategy *StrategyConfig, email EmailConfig) error {
+	if len(strategy.Email.To) == 0 {
+		return nil
+	}
+	if !email.Enabled() {
+		return fmt.Errorf("strategy '%s': email.to requires global.notifications.email", strategy.Name)
+	}
+	if err := validateEmailAddresses(strategy.Email.To); err != nil {
+		return fmt.Errorf("strategy '%s': email: %w", strategy.Name, err)
+	}
+	return nil
+}
+
+// validateEmailAddresses checks that every recipient is a valid address
+func validateEmailAddresses(addresses []string) error {
+	for _, address := range addresses {
+		if _, err := mail.ParseAddress(address); err != nil {
+			return fmt.Errorf("invalid recipient '%s': %w", address, err)
+		}
+	}
+	return nil
+}
+
+// validateMySQLConfig validates the MySQL specific settings of a strategy
+func validateMySQLConfig(strategy *StrategyConfig) error {
+	mysqlConfig := strategy.MySQL
+	if !mysqlConfig.MultiDatabase() && (len(mysqlConfig.ExcludeDatabases) > 0 || mysqlConfig.SplitArtifacts) {
+		return fmt.Errorf("strategy '%s': mysql.exclude_databases and mysql.split_artifacts require mysql.all_databases or mysql.databases", strategy.Name)
+	}
+	if mysqlConfig.MultiDatabase() && strategy.DatabaseType != "mysql" && strategy.DatabaseType != "mariadb" {
+		return fmt.Errorf("strategy '%s': mysql settings are only supported for mysql and mariadb strategies", strategy.Name)
+	}
+	if mysqlConfig.AllDatabases && len(mysqlConfig.Databases) > 0 {
+		return fmt.Errorf("strategy '%s': mysql.all_databases and mysql.databases are mutually exclusive", strategy.Name)
+	}
+	if mysqlConfig.Binlog.Enabled {
+		if strategy.DatabaseType != "mysql" && strategy.DatabaseType != "mariadb" {
+			return fmt.Errorf("strategy '%s': mysql.binlog is only supported for mysql and mariadb strategies", strategy.Name)
+		}
+		if mysqlConfig.MultiDatabase() {
+			return fmt.Errorf("strategy '%s': mysql.binlog cannot be combined with multi-database dumps", strategy.Name)
+		}
+		if mysqlConfig.Binlog.Schedule == "" {
+			return fmt.Errorf("strategy '%s': mysql.binlog.schedule is required", strategy.Name)
+		}
+	}
+
+	patterns := append(append([]string{}, mysqlConfig.Databases...), mysqlConfig.ExcludeDatabases...)
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("strategy '%s': invalid database pattern '%s': %w", strategy.Name, pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// validateMongoDBConfig validates the MongoDB specific settings of a strategy
+func validateMongoDBConfig(strategy *StrategyConfig) error {
+	mongoConfig := strategy.MongoDB
+	filtered := len(mongoConfig.IncludeCollections) > 0 || len(mongoConfig.ExcludeCollections) > 0 || mongoConfig.Query != ""
+	if (filtered || mongoConfig.Oplog || mongoConfig.Archive || mongoConfig.ReadPreference != "") && strategy.DatabaseType != "mongodb" {
+		return fmt.Errorf("strategy '%s': mongodb settings are only supported for mongodb strategies", strategy.Name)
+	}
+	if mongoConfig.Oplog && filtered {
+		return fmt.Errorf("strategy '%s': mongodb.oplog cannot be combined with collection filters or queries", strategy.Name)
+	}
+	if len(mongoConfig.IncludeCollections) > 0 && len(mongoConfig.ExcludeCollections) > 0 {
+		return fmt.Errorf("strategy '%s': mongodb.include_collections and mongodb.exclude_collections are mutually exclusive", strategy.Name)
+	}
+	if mongoConfig.Query != "" && len(mongoConfig.IncludeCollections) == 0 {
+		return fmt.Errorf("strategy '%s': mongodb.query requires mongodb.include_collections", strategy.Name)
+	}
+	if mongoConfig.Archive && len(mongoConfig.IncludeCollections) > 1 {
+		return fmt.Errorf("strategy '%s': mongodb.archive supports at most one included collection", strategy.Name)
+	}
+
+	switch mongoConfig.ReadPreference {
+	case "", "primary", "primaryPreferred", "secondary", "secondaryPreferred", "nearest":
+	default:
+		return fmt.Errorf("strategy '%s': invalid mongodb.read_preference '%s'", strategy.Name, mongoConfig.ReadPreference)
+	}
+
+	return nil
+}
+
+// validateFilesConfig validates the settings of filesystem backup strategies
+func validateFilesConfig(strategy *StrategyConfig) error {
+	filesConfig := strategy.Files
+	if strategy.DatabaseType != "files" {
+		if len(filesConfig.Paths) > 0 || len(filesConfig.Include) > 0 || len(filesConfig.Exclude) > 0 {
+			return fmt.Errorf("strategy '%s': files settings are only supported for files strategies", strategy.Name)
+		}
+		return nil
+	}
+
+	if len(filesConfig.Paths) == 0 {
+		return fmt.Errorf("strategy '%s': files.paths is required for files strategies", strategy.Name)
+	}
+	for _, filePath := range filesConfig.Paths {
+		if !filepath.IsAbs(filePath) {
+			return fmt.Errorf("strategy '%s': files.paths must be absolute, got '%s'", strategy.Name, filePath)
+		}
+	}
+	for _, pattern := range append(append([]string{}, filesConfig.Include...), filesConfig.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("strategy '%s': invalid files pattern '%s': %w", strategy.Name, pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// validateCommandConfig validates the settings of command strategies
+func validateCommandConfig(strategy *StrategyConfig) error {
+	commandConfig := &strategy.Command
+	if strategy.DatabaseType != "command" {
+		if commandConfig.Command != "" || len(commandConfig.Args) > 0 {
+			return fmt.Errorf("strategy '%s': command settings are only supported for command strategies", strategy.Name)
+		}
+		return nil
+	}
+
+	if commandConfig.Command == "" {
+		return fmt.Errorf("strategy '%s': command.command is required for command strategies", strategy.Name)
+	}
+	for _, arg := range commandConfig.Args {
+		if _, err := template.New("arg").Parse(arg); err != nil {
+			if strings.Contains(err.Error(), `function "env" not defined`) {
+				return fmt.Errorf("strategy '%s': {{env}} is not supported in command arguments, which any user of the host can read; the command inherits the environment and can read the variable itself", strategy.Name)
+			}
+			return fmt.Errorf("strategy '%s': invalid command argument template '%s': %w", strategy.Name, arg, err)
+		}
+	}
+	for _, pattern := range commandConfig.ErrorPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("strategy '%s': invalid command error pattern '%s': %w", strategy.Name, pattern, err)
+		}
+	}
+
+	if len(commandConfig.ExitCodes) == 0 {
+		commandConfig.ExitCodes = []int{0}
+	}
+	if commandConfig.Extension == "" {
+		commandConfig.Extension = ".backup"
+	} else if !strings.HasPrefix(commandConfig.Extension, ".") {
+		commandConfig.Extension = "." + commandConfig.Extension
+	}
+
+	return nil
+}
+
+// validateEtcdConfig validates the settings of etcd snapshot strategies
+func validateEtcdConfig(strategy *StrategyConfig) error {
+	etcdConfig := strategy.Etcd
+	if strategy.DatabaseType != "etcd" {
+		if len(etcdConfig.Endpoints) > 0 || etcdConfig.Cert != "" || etcdConfig.Key != "" || etcdConfig.CACert != "" {
+			return fmt.Errorf("strategy '%s': etcd settings are only supported for etcd strategies", strategy.Name)
+		}
+		return nil
+	}
+
+	if len(etcdConfig.Endpoints) == 0 {
+		return fmt.Errorf("strategy '%s': etcd.endpoints is required for etcd strategies", strategy.Name)
+	}
+	if (etcdConfig.Cert == "") != (etcdConfig.Key == "") {
+		return fmt.Errorf("strategy '%s': etcd.cert and etcd.key must be set together", strategy.Name)
+	}
+
+	return nil
+}
+
+// validateReplicationConfig validates the replication lag guard of a strategy
+func validateReplicationConfig(strategy *StrategyConfig) error {
+	if strategy.MaxReplicationLag == "" {
+		if strategy.FallbackDatabaseURL != "" {
+			return fmt.Errorf("strategy '%s': fallback_database_url requires max_replication_lag", strategy.Name)
+		}
+		return nil
+	}
+
+	switch strategy.DatabaseType {
+	case "postgres", "mysql", "mariadb", "mongodb":
+	default:
+		return fmt.Errorf("strategy '%s': max_replication_lag is only supported for postgres, mysql, mariadb and mongodb strategies", strategy.Name)
+	}
+	maxLag, err := ParseDuration(strategy.MaxReplicationLag)
+	if err != nil {
+		return fmt.Errorf("strategy '%s': invalid max_replication_lag: %w", strategy.Name, err)
+	}
+	if maxLag <= 0 {
+		return fmt.Errorf("strategy '%s': max_replication_lag must be positive", strategy.Name)
+	}
+
+	return nil
+}
+
+// validateDynamicCredentials validates the source of per-run database credentials
+func validateDynamicCredentials(strategy *StrategyConfig) error {
+	credentials := strategy.DynamicCredentials
+	if !credentials.Enabled() {
+		if len(credentials.Args) > 0 || credentials.Timeout != "" {
+			return fmt.Errorf("strategy '%s': dynamic_credentials requires vault_path or command", strategy.Name)
+		}
+		return nil
+	}
+
+	switch strategy.DatabaseType {
+	case "postgres", "mysql", "mariadb", "mongodb", "mariabackup", "xtrabackup", "redis", "command":
+	default:
+		return fmt.Errorf("strategy '%s': dynamic_credentials are not supported for %s strategies", strategy.Name, strategy.DatabaseType)
+	}
+	if credentials.VaultPath != "" && credentials.Command != "" {
+		return fmt.Errorf("strategy '%s': dynamic_credentials.vault_path and dynamic_credentials.command are mutually exclusive", strategy.Name)
+	}
+	if len(credentials.Args) > 0 && credentials.Command == "" {
+		return fmt.Errorf("strategy '%s': dynamic_credentials.args requires dynamic_credentials.command", strategy.Name)
+	}
+	if credentials.Timeout != "" {
+		if timeout, err := time.ParseDuration(credentials.Timeout); err != nil || timeout <= 0 {
+			return fmt.Errorf("strategy '%s': invalid dynamic_credentials.timeout '%s'", strategy.Name, credentials.Timeout)
+		}
+	}
+
+	return nil
+}
+
+// validateToolPaths validates the client binary directories configured per server major version
+func validateToolPaths(strategy *StrategyConfig) error {
+	if len(strategy.ToolPaths) == 0 {
+		return nil
+	}
+
+	switch strategy.DatabaseType {
+	case "postgres", "mysql", "mariadb", "mariabackup", "xtrabackup", "mongodb":
+	default:
+		return fmt.Errorf("strategy '%s': tool_paths is only supported for postgres, mysql, mariadb, mariabackup, xtrabackup and mongodb strategies", strategy.Name)
+	}
+	for version, dir := range strategy.ToolPaths {
+		if major, err := strconv.Atoi(version); err != nil || major <= 0 {
+			return fmt.Errorf("strategy '%s': tool_paths key '%s' must be a major version number", strategy.Name, version)
+		}
+		if !filepath.IsAbs(dir) {
+			return fmt.Errorf("strategy '%s': tool_paths directory '%s' must be absolute", strategy.Name, dir)
+		}
+	}
+
+	return nil
+}
+
+// validateHooksConfig validates the hooks of a strategy and applies their defaults
+func validateHooksConfig(strategy *StrategyConfig) error {
+	// Stages are validated in the order they run so the first error reported is stable
+	hookLists := []struct {
+		stage string
+		hooks *[]HookConfig
+	}{
+		{"pre_backup", &strategy.Hooks.PreBackup},
+		{"post_backup", &strategy.Hooks.PostBackup},
+		{"on_success", &strategy.Hooks.OnSuccess},
+		{"on_failure", &strategy.Hooks.OnFailure},
+	}
+
+	for _, hookList := range hookLists {
+		stage := hookList.stage
+		for i := range *hookList.hooks {
+			hook := &(*hookList.hooks)[i]
+			if (hook.Command == "") == (hook.URL == "") {
+				return fmt.Errorf("strategy '%s': %s hook %d must set exactly one of command or url", strategy.Name, stage, i+1)
+			}
+			if hook.Name == "" {
+				hook.Name = fmt.Sprintf("%s-%d", stage, i+1)
+			}
+			if hook.URL != "" && hook.Method == "" {
+				hook.Method = "POST"
+			}
+			if hook.Timeout == "" {
+				hook.Timeout = "5m"
+			}
+			if _, err := time.ParseDuration(hook.Timeout); err != nil {
+				return fmt.Errorf("strategy '%s': invalid timeout for hook '%s': %w", strategy.Name, hook.Name, err)
+			}
+		}
 	}
 
 	return nil
@@ -247,22 +1133,3 @@ func ParseDuration(duration string) (time.Duration, error) {
 
 	return time.Duration(count) * multiplier, nil
 }
-
-// substituteEnvVars replaces ${VAR} patterns with environment variable values
-func substituteEnvVars(input string) string {
-	// Regular expression to match ${VAR} patterns
-	re := regexp.MustCompile(`\$\{([^}]+)\}`)
-
-	return re.ReplaceAllStringFunc(input, func(match string) string {
-		// Extract variable name (remove ${ and })
-		varName := strings.TrimSuffix(strings.TrimPrefix(match, "${"), "}")
-
-		// Get environment variable value
-		if value := os.Getenv(varName); value != "" {
-			return value
-		}
-
-		// Return original if not found (keep ${VAR} format)
-		return match
-	})
-}
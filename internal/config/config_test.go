@@ -1,6 +1,11 @@
 package config
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -60,3 +65,913 @@ func TestSetDefaults(t *testing.T) {
 	assert.Equal(t, "1d", config.Strategies[0].Schedule)
 	assert.Equal(t, "30d", config.Strategies[0].Retention)
 }
+
+func TestSetDefaults_MySQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy StrategyConfig
+		hasError bool
+	}{
+		{
+			name:     "all databases",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "mysql", MySQL: MySQLConfig{AllDatabases: true, SplitArtifacts: true}},
+		},
+		{
+			name:     "database globs",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "mariadb", MySQL: MySQLConfig{Databases: []string{"tenant_*"}, ExcludeDatabases: []string{"tenant_test"}}},
+		},
+		{
+			name:     "exclusions without selection",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "mysql", MySQL: MySQLConfig{ExcludeDatabases: []string{"tmp"}}},
+			hasError: true,
+		},
+		{
+			name:     "all databases and list",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "mysql", MySQL: MySQLConfig{AllDatabases: true, Databases: []string{"app"}}},
+			hasError: true,
+		},
+		{
+			name:     "invalid pattern",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "mysql", MySQL: MySQLConfig{Databases: []string{"tenant_["}}},
+			hasError: true,
+		},
+		{
+			name:     "binlog incrementals",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "mysql", MySQL: MySQLConfig{Binlog: BinlogConfig{Enabled: true, Schedule: "*/15 * * * *"}}},
+		},
+		{
+			name:     "binlog without schedule",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "mysql", MySQL: MySQLConfig{Binlog: BinlogConfig{Enabled: true}}},
+			hasError: true,
+		},
+		{
+			name:     "binlog with multi-database dumps",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "mysql", MySQL: MySQLConfig{AllDatabases: true, Binlog: BinlogConfig{Enabled: true, Schedule: "*/15 * * * *"}}},
+			hasError: true,
+		},
+		{
+			name:     "wrong database type",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "postgres", MySQL: MySQLConfig{AllDatabases: true}},
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := setDefaults(&Config{Strategies: []StrategyConfig{tt.strategy}})
+			if tt.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSetDefaults_Files(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy StrategyConfig
+		hasError bool
+	}{
+		{
+			name:     "paths with patterns",
+			strategy: StrategyConfig{Name: "uploads", DatabaseType: "files", Files: FilesConfig{Paths: []string{"/srv/uploads"}, Exclude: []string{"*.tmp"}}},
+		},
+		{
+			name:     "missing paths",
+			strategy: StrategyConfig{Name: "uploads", DatabaseType: "files"},
+			hasError: true,
+		},
+		{
+			name:     "relative path",
+			strategy: StrategyConfig{Name: "uploads", DatabaseType: "files", Files: FilesConfig{Paths: []string{"uploads"}}},
+			hasError: true,
+		},
+		{
+			name:     "invalid pattern",
+			strategy: StrategyConfig{Name: "uploads", DatabaseType: "files", Files: FilesConfig{Paths: []string{"/srv/uploads"}, Include: []string{"[a-"}}},
+			hasError: true,
+		},
+		{
+			name:     "wrong database type",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "postgres", Files: FilesConfig{Paths: []string{"/srv/uploads"}}},
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := setDefaults(&Config{Strategies: []StrategyConfig{tt.strategy}})
+			if tt.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSetDefaults_Command(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		cfg := &Config{Strategies: []StrategyConfig{{
+			Name:         "clickhouse",
+			DatabaseType: "command",
+			Command:      CommandConfig{Command: "clickhouse-backup", Args: []string{"create", "{{.StrategyName}}"}, Extension: "tar"},
+		}}}
+		require.NoError(t, setDefaults(cfg))
+		assert.Equal(t, []int{0}, cfg.Strategies[0].Command.ExitCodes)
+		assert.Equal(t, ".tar", cfg.Strategies[0].Command.Extension)
+	})
+
+	tests := []struct {
+		name    string
+		command CommandConfig
+	}{
+		{name: "missing command", command: CommandConfig{Args: []string{"--out={{.OutputPath}}"}}},
+		{name: "invalid template", command: CommandConfig{Command: "dump", Args: []string{"--out={{.OutputPath"}}},
+		{name: "invalid error pattern", command: CommandConfig{Command: "dump", ErrorPatterns: []string{"(unclosed"}}},
+		{name: "environment in arguments", command: CommandConfig{Command: "dump", Args: []string{`--password={{env "DUMP_PASSWORD"}}`}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := setDefaults(&Config{Strategies: []StrategyConfig{{Name: "vendor", DatabaseType: "command", Command: tt.command}}})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSetDefaults_Etcd(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy StrategyConfig
+		hasError bool
+	}{
+		{
+			name:     "endpoints with client certificate",
+			strategy: StrategyConfig{Name: "etcd", DatabaseType: "etcd", Etcd: EtcdConfig{Endpoints: []string{"https://10.0.0.10:2379"}, Cert: "client.crt", Key: "client.key"}},
+		},
+		{
+			name:     "missing endpoints",
+			strategy: StrategyConfig{Name: "etcd", DatabaseType: "etcd"},
+			hasError: true,
+		},
+		{
+			name:     "certificate without key",
+			strategy: StrategyConfig{Name: "etcd", DatabaseType: "etcd", Etcd: EtcdConfig{Endpoints: []string{"https://10.0.0.10:2379"}, Cert: "client.crt"}},
+			hasError: true,
+		},
+		{
+			name:     "wrong database type",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "postgres", Etcd: EtcdConfig{Endpoints: []string{"https://10.0.0.10:2379"}}},
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := setDefaults(&Config{Strategies: []StrategyConfig{tt.strategy}})
+			if tt.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSetDefaults_Replication(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy StrategyConfig
+		hasError bool
+	}{
+		{
+			name:     "max lag with fallback",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "postgres", MaxReplicationLag: "5m", FallbackDatabaseURL: "postgres://primary/db"},
+		},
+		{
+			name:     "max lag in hours",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "mongodb", MaxReplicationLag: "1h"},
+		},
+		{
+			name:     "invalid max lag",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "mysql", MaxReplicationLag: "soon"},
+			hasError: true,
+		},
+		{
+			name:     "fallback without max lag",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "postgres", FallbackDatabaseURL: "postgres://primary/db"},
+			hasError: true,
+		},
+		{
+			name:     "unsupported database type",
+			strategy: StrategyConfig{Name: "cache", DatabaseType: "redis", DatabaseURL: "redis://replica:6379", MaxReplicationLag: "5m"},
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := setDefaults(&Config{Strategies: []StrategyConfig{tt.strategy}})
+			if tt.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSetDefaults_ToolPaths(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy StrategyConfig
+		hasError bool
+	}{
+		{
+			name:     "directories per major version",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "postgres", ToolPaths: map[string]string{"15": "/usr/libexec/postgresql15", "17": "/usr/libexec/postgresql17"}},
+		},
+		{
+			name:     "key is not a major version",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "postgres", ToolPaths: map[string]string{"15.4": "/usr/libexec/postgresql15"}},
+			hasError: true,
+		},
+		{
+			name:     "relative directory",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "mariadb", ToolPaths: map[string]string{"11": "bin/mariadb11"}},
+			hasError: true,
+		},
+		{
+			name:     "unsupported database type",
+			strategy: StrategyConfig{Name: "cache", DatabaseType: "redis", DatabaseURL: "redis://localhost:6379", ToolPaths: map[string]string{"7": "/opt/redis7/bin"}},
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := setDefaults(&Config{Strategies: []StrategyConfig{tt.strategy}})
+			if tt.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSetDefaults_DynamicCredentials(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy StrategyConfig
+		hasError bool
+	}{
+		{
+			name:     "vault database secrets engine",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "postgres", DynamicCredentials: DynamicCredentialsConfig{VaultPath: "database/creds/backup", Timeout: "10s"}},
+		},
+		{
+			name:     "credential helper",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "mysql", DynamicCredentials: DynamicCredentialsConfig{Command: "/usr/local/bin/db-creds", Args: []string{"--role=backup"}}},
+		},
+		{
+			name:     "vault path and command",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "postgres", DynamicCredentials: DynamicCredentialsConfig{VaultPath: "database/creds/backup", Command: "db-creds"}},
+			hasError: true,
+		},
+		{
+			name:     "args without command",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "postgres", DynamicCredentials: DynamicCredentialsConfig{VaultPath: "database/creds/backup", Args: []string{"--role=backup"}}},
+			hasError: true,
+		},
+		{
+			name:     "invalid timeout",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "postgres", DynamicCredentials: DynamicCredentialsConfig{VaultPath: "database/creds/backup", Timeout: "1d"}},
+			hasError: true,
+		},
+		{
+			name:     "unsupported database type",
+			strategy: StrategyConfig{Name: "local", DatabaseType: "sqlite", DatabaseURL: "/data/app.db", DynamicCredentials: DynamicCredentialsConfig{VaultPath: "database/creds/backup"}},
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := setDefaults(&Config{Strategies: []StrategyConfig{tt.strategy}})
+			if tt.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSetDefaults_Notifications(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		cfg := &Config{Global: GlobalConfig{Notifications: NotificationsConfig{
+			Webhooks: []WebhookConfig{{URL: "https://incidents.example.com/hooks/backups"}},
+		}}}
+		require.NoError(t, setDefaults(cfg))
+
+		webhook := cfg.Global.Notifications.Webhooks[0]
+		assert.Equal(t, "webhook-1", webhook.Name)
+		assert.Equal(t, []string{EventStarted, EventResult, EventError}, webhook.Events)
+		assert.Equal(t, "10s", webhook.Timeout)
+	})
+
+	tests := []struct {
+		name     string
+		webhook  WebhookConfig
+		hasError bool
+	}{
+		{
+			name:    "all events",
+			webhook: WebhookConfig{Name: "incidents", URL: "http://localhost:8080/events", Events: []string{"started", "progress", "output", "result", "error"}},
+		},
+		{
+			name:     "missing url",
+			webhook:  WebhookConfig{Name: "incidents"},
+			hasError: true,
+		},
+		{
+			name:     "unsupported scheme",
+			webhook:  WebhookConfig{Name: "incidents", URL: "ftp://incidents.example.com"},
+			hasError: true,
+		},
+		{
+			name:     "unsupported event",
+			webhook:  WebhookConfig{Name: "incidents", URL: "https://incidents.example.com", Events: []string{"finished"}},
+			hasError: true,
+		},
+		{
+			name:     "invalid timeout",
+			webhook:  WebhookConfig{Name: "incidents", URL: "https://incidents.example.com", Timeout: "soon"},
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := setDefaults(&Config{Global: GlobalConfig{Notifications: NotificationsConfig{Webhooks: []WebhookConfig{tt.webhook}}}})
+			if tt.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSetDefaults_Reports(t *testing.T) {
+	t.Run("default period", func(t *testing.T) {
+		cfg := &Config{Global: GlobalConfig{Notifications: NotificationsConfig{
+			Reports: []ReportConfig{{Schedule: "0 8 * * *"}, {Schedule: "0 8 * * 1", Period: "7d"}},
+		}}}
+		require.NoError(t, setDefaults(cfg))
+		assert.Equal(t, "1d", cfg.Global.Notifications.Reports[0].Period)
+		assert.Equal(t, "7d", cfg.Global.Notifications.Reports[1].Period)
+	})
+
+	tests := []struct {
+		name   string
+		report ReportConfig
+	}{
+		{name: "missing schedule", report: ReportConfig{Period: "7d"}},
+		{name: "invalid period", report: ReportConfig{Schedule: "0 8 * * 1", Period: "weekly"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := setDefaults(&Config{Global: GlobalConfig{Notifications: NotificationsConfig{Reports: []ReportConfig{tt.report}}}})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSetDefaults_Email(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		cfg := &Config{Global: GlobalConfig{Notifications: NotificationsConfig{Email: EmailConfig{
+			Host: "smtp.example.com",
+			From: "Easy Backup <backups@example.com>",
+			To:   []string{"dba@example.com"},
+		}}}}
+		require.NoError(t, setDefaults(cfg))
+
+		email := cfg.Global.Notifications.Email
+		assert.Equal(t, EmailTLSStartTLS, email.TLS)
+		assert.Equal(t, 587, email.Port)
+		assert.Equal(t, "30s", email.Timeout)
+	})
+
+	t.Run("implicit tls port", func(t *testing.T) {
+		cfg := &Config{Global: GlobalConfig{Notifications: NotificationsConfig{Email: EmailConfig{Host: "smtp.example.com", From: "backups@example.com", TLS: EmailTLSImplicit}}}}
+		require.NoError(t, setDefaults(cfg))
+		assert.Equal(t, 465, cfg.Global.Notifications.Email.Port)
+	})
+
+	tests := []struct {
+		name     string
+		email    EmailConfig
+		strategy StrategyConfig
+		hasError bool
+	}{
+		{
+			name:     "strategy recipients",
+			email:    EmailConfig{Host: "smtp.example.com", From: "backups@example.com", Digest: "0 8 * * *", To: []string{"dba@example.com"}},
+			strategy: StrategyConfig{Name: "db", Email: StrategyEmailConfig{To: []string{"Team <team@example.com>"}}},
+		},
+		{
+			name:     "missing from",
+			email:    EmailConfig{Host: "smtp.example.com"},
+			hasError: true,
+		},
+		{
+			name:     "missing host",
+			email:    EmailConfig{From: "backups@example.com", To: []string{"dba@example.com"}},
+			hasError: true,
+		},
+		{
+			name:     "unsupported tls mode",
+			email:    EmailConfig{Host: "smtp.example.com", From: "backups@example.com", TLS: "ssl"},
+			hasError: true,
+		},
+		{
+			name:     "invalid recipient",
+			email:    EmailConfig{Host: "smtp.example.com", From: "backups@example.com", To: []string{"dba"}},
+			hasError: true,
+		},
+		{
+			name:     "digest without recipients",
+			email:    EmailConfig{Host: "smtp.example.com", From: "backups@example.com", Digest: "0 8 * * *"},
+			hasError: true,
+		},
+		{
+			name:     "password without username",
+			email:    EmailConfig{Host: "smtp.example.com", From: "backups@example.com", Password: "secret"},
+			hasError: true,
+		},
+		{
+			name:     "strategy recipients without email",
+			strategy: StrategyConfig{Name: "db", Email: StrategyEmailConfig{To: []string{"team@example.com"}}},
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Global: GlobalConfig{Notifications: NotificationsConfig{Email: tt.email}}}
+			if tt.strategy.Name != "" {
+				cfg.Strategies = []StrategyConfig{tt.strategy}
+			}
+			err := setDefaults(cfg)
+			if tt.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSetDefaults_ChatWebhooks(t *testing.T) {
+	cfg := &Config{
+		Global: GlobalConfig{Teams: TeamsConfig{WebhookURL: "https://example.webhook.office.com/webhookb2/ops"}},
+		Strategies: []StrategyConfig{
+			{Name: "db", Discord: DiscordConfig{WebhookURL: "https://discord.com/api/webhooks/1/token"}},
+			{Name: "files", Teams: TeamsConfig{WebhookURL: "https://example.webhook.office.com/webhookb2/files"}},
+		},
+	}
+	require.NoError(t, setDefaults(cfg))
+	assert.Equal(t, "https://example.webhook.office.com/webhookb2/ops", cfg.Strategies[0].Teams.WebhookURL)
+	assert.Equal(t, "https://example.webhook.office.com/webhookb2/files", cfg.Strategies[1].Teams.WebhookURL)
+	assert.Empty(t, cfg.Strategies[1].Discord.WebhookURL)
+
+	err := setDefaults(&Config{Strategies: []StrategyConfig{{Name: "db", Discord: DiscordConfig{WebhookURL: "discord.com/api/webhooks/1/token"}}}})
+	assert.EqualError(t, err, "strategy 'db': discord.webhook_url must be an http:// or https:// URL")
+
+	err = setDefaults(&Config{Global: GlobalConfig{Teams: TeamsConfig{WebhookURL: "teams"}}})
+	assert.EqualError(t, err, "global: teams.webhook_url must be an http:// or https:// URL")
+}
+
+func TestSetDefaults_Alerting(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		cfg := &Config{Global: GlobalConfig{Notifications: NotificationsConfig{Alerting: AlertingConfig{
+			PagerDuty: PagerDutyConfig{RoutingKey: "routing-key"},
+		}}}}
+		require.NoError(t, setDefaults(cfg))
+
+		alerting := cfg.Global.Notifications.Alerting
+		assert.Equal(t, SeverityError, alerting.Severity)
+		assert.Equal(t, "https://events.pagerduty.com/v2/enqueue", alerting.PagerDuty.URL)
+		assert.Equal(t, "https://api.opsgenie.com", alerting.Opsgenie.URL)
+	})
+
+	tests := []struct {
+		name     string
+		alerting AlertingConfig
+		strategy StrategyConfig
+		hasError bool
+	}{
+		{
+			name:     "strategy overrides",
+			alerting: AlertingConfig{Opsgenie: OpsgenieConfig{APIKey: "genie-key", URL: "https://api.eu.opsgenie.com"}},
+			strategy: StrategyConfig{Name: "db", Alerting: StrategyAlertingConfig{Severity: SeverityCritical, DedupKey: "db-backups"}},
+		},
+		{
+			name:     "strategy routing key without global alerting",
+			strategy: StrategyConfig{Name: "db", Alerting: StrategyAlertingConfig{RoutingKey: "dba-key"}},
+		},
+		{
+			name:     "unsupported severity",
+			alerting: AlertingConfig{PagerDuty: PagerDutyConfig{RoutingKey: "routing-key"}, Severity: "high"},
+			hasError: true,
+		},
+		{
+			name:     "unsupported strategy severity",
+			alerting: AlertingConfig{PagerDuty: PagerDutyConfig{RoutingKey: "routing-key"}},
+			strategy: StrategyConfig{Name: "db", Alerting: StrategyAlertingConfig{Severity: "page"}},
+			hasError: true,
+		},
+		{
+			name:     "strategy overrides without alerting",
+			strategy: StrategyConfig{Name: "db", Alerting: StrategyAlertingConfig{Severity: SeverityCritical}},
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Global: GlobalConfig{Notifications: NotificationsConfig{Alerting: tt.alerting}}}
+			if tt.strategy.Name != "" {
+				cfg.Strategies = []StrategyConfig{tt.strategy}
+			}
+			err := setDefaults(cfg)
+			if tt.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSetDefaults_NotificationRoutes(t *testing.T) {
+	tests := []struct {
+		name     string
+		routes   []NotificationRoute
+		strategy StrategyConfig
+		hasError bool
+	}{
+		{
+			name: "global routes",
+			routes: []NotificationRoute{
+				{Events: []string{EventSuccess}, Notifier: NotifierSlack, ChannelID: "C-QUIET"},
+				{Events: []string{EventFailure, EventStale}, Notifier: NotifierSlack, ChannelID: "C-CRITICAL", Mention: "@here"},
+				{Events: []string{EventFailure}, Notifier: "webhook:incidents"},
+				{Events: []string{EventStarted, EventFailure}, Notifier: NotifierWebhook},
+			},
+		},
+		{
+			name: "strategy routes",
+			strategy: StrategyConfig{Name: "db", NotificationRoutes: []NotificationRoute{
+				{Events: []string{EventFailure}, Notifier: NotifierEmail},
+			}},
+		},
+		{
+			name:     "stale after",
+			strategy: StrategyConfig{Name: "db", StaleAfter: "26h"},
+		},
+		{
+			name:     "invalid stale after",
+			strategy: StrategyConfig{Name: "db", StaleAfter: "daily"},
+			hasError: true,
+		},
+		{
+			name:     "missing events",
+			routes:   []NotificationRoute{{Notifier: NotifierSlack}},
+			hasError: true,
+		},
+		{
+			name:     "unsupported event",
+			routes:   []NotificationRoute{{Events: []string{EventResult}, Notifier: NotifierSlack}},
+			hasError: true,
+		},
+		{
+			name:     "unsupported notifier",
+			routes:   []NotificationRoute{{Events: []string{EventFailure}, Notifier: "sms"}},
+			hasError: true,
+		},
+		{
+			name:     "unknown webhook",
+			routes:   []NotificationRoute{{Events: []string{EventFailure}, Notifier: "webhook:audit"}},
+			hasError: true,
+		},
+		{
+			name:     "mention without slack",
+			routes:   []NotificationRoute{{Events: []string{EventFailure}, Notifier: NotifierEmail, Mention: "@here"}},
+			hasError: true,
+		},
+		{
+			name: "invalid strategy route",
+			strategy: StrategyConfig{Name: "db", NotificationRoutes: []NotificationRoute{
+				{Events: []string{"finished"}, Notifier: NotifierSlack},
+			}},
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Global: GlobalConfig{Notifications: NotificationsConfig{
+				Webhooks: []WebhookConfig{{Name: "incidents", URL: "https://hooks.example.com/backups"}},
+				Routes:   tt.routes,
+			}}}
+			if tt.strategy.Name != "" {
+				cfg.Strategies = []StrategyConfig{tt.strategy}
+			}
+			err := setDefaults(cfg)
+			if tt.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("strategies inherit stale after", func(t *testing.T) {
+		cfg := &Config{
+			Global:     GlobalConfig{StaleAfter: "2d"},
+			Strategies: []StrategyConfig{{Name: "db"}, {Name: "hourly", StaleAfter: "3h"}},
+		}
+		require.NoError(t, setDefaults(cfg))
+		assert.Equal(t, "2d", cfg.Strategies[0].StaleAfter)
+		assert.Equal(t, "3h", cfg.Strategies[1].StaleAfter)
+	})
+}
+
+func TestSetDefaults_SlackCommands(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands SlackCommandsConfig
+		hasError bool
+	}{
+		{name: "disabled"},
+		{name: "allowed users", commands: SlackCommandsConfig{SigningSecret: "secret", AllowedUsers: []string{"U012AB3CD"}}},
+		{name: "allowed groups", commands: SlackCommandsConfig{SigningSecret: "secret", Path: "/slack", AllowedGroups: []string{"S0614TZR7"}}},
+		{name: "missing signing secret", commands: SlackCommandsConfig{AllowedUsers: []string{"U012AB3CD"}}, hasError: true},
+		{name: "missing allow-list", commands: SlackCommandsConfig{SigningSecret: "secret"}, hasError: true},
+		{name: "relative path", commands: SlackCommandsConfig{SigningSecret: "secret", Path: "slack", AllowedUsers: []string{"U012AB3CD"}}, hasError: true},
+		{name: "health check path", commands: SlackCommandsConfig{SigningSecret: "secret", Path: "/health", AllowedUsers: []string{"U012AB3CD"}}, hasError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Global: GlobalConfig{SlackCommands: tt.commands}}
+			err := setDefaults(cfg)
+			if tt.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("default path", func(t *testing.T) {
+		cfg := &Config{Global: GlobalConfig{SlackCommands: SlackCommandsConfig{SigningSecret: "secret", AllowedUsers: []string{"U012AB3CD"}}}}
+		require.NoError(t, setDefaults(cfg))
+		assert.Equal(t, "/slack/commands", cfg.Global.SlackCommands.Path)
+	})
+}
+
+func TestSetDefaults_Hooks(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		cfg := &Config{Strategies: []StrategyConfig{{
+			Name: "db",
+			Hooks: HooksConfig{
+				PreBackup:  []HookConfig{{Command: "psql -c CHECKPOINT"}},
+				PostBackup: []HookConfig{{Name: "notify", URL: "https://internal.example.com/backups", Timeout: "30s"}},
+			},
+		}}}
+		require.NoError(t, setDefaults(cfg))
+
+		hooks := cfg.Strategies[0].Hooks
+		assert.Equal(t, "pre_backup-1", hooks.PreBackup[0].Name)
+		assert.Equal(t, "5m", hooks.PreBackup[0].Timeout)
+		assert.Equal(t, "POST", hooks.PostBackup[0].Method)
+		assert.Equal(t, "30s", hooks.PostBackup[0].Timeout)
+	})
+
+	tests := []struct {
+		name string
+		hook HookConfig
+	}{
+		{name: "neither command nor url", hook: HookConfig{Name: "empty"}},
+		{name: "command and url", hook: HookConfig{Command: "true", URL: "https://example.com"}},
+		{name: "invalid timeout", hook: HookConfig{Command: "true", Timeout: "soon"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := setDefaults(&Config{Strategies: []StrategyConfig{{Name: "db", Hooks: HooksConfig{OnFailure: []HookConfig{tt.hook}}}}})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSetDefaults_MongoDB(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy StrategyConfig
+		hasError bool
+	}{
+		{
+			name:     "oplog from secondary",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "mongodb", MongoDB: MongoDBConfig{Oplog: true, ReadPreference: "secondary"}},
+		},
+		{
+			name:     "included collections with query",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "mongodb", MongoDB: MongoDBConfig{IncludeCollections: []string{"orders", "users"}, Query: `{"archived": false}`}},
+		},
+		{
+			name:     "oplog with filters",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "mongodb", MongoDB: MongoDBConfig{Oplog: true, ExcludeCollections: []string{"sessions"}}},
+			hasError: true,
+		},
+		{
+			name:     "include and exclude",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "mongodb", MongoDB: MongoDBConfig{IncludeCollections: []string{"orders"}, ExcludeCollections: []string{"sessions"}}},
+			hasError: true,
+		},
+		{
+			name:     "query without collections",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "mongodb", MongoDB: MongoDBConfig{Query: `{"archived": false}`}},
+			hasError: true,
+		},
+		{
+			name:     "archive with several collections",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "mongodb", MongoDB: MongoDBConfig{Archive: true, IncludeCollections: []string{"orders", "users"}}},
+			hasError: true,
+		},
+		{
+			name:     "invalid read preference",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "mongodb", MongoDB: MongoDBConfig{ReadPreference: "replica"}},
+			hasError: true,
+		},
+		{
+			name:     "wrong database type",
+			strategy: StrategyConfig{Name: "db", DatabaseType: "postgres", MongoDB: MongoDBConfig{Oplog: true}},
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := setDefaults(&Config{Strategies: []StrategyConfig{tt.strategy}})
+			if tt.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_References(t *testing.T) {
+	writeConfig := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	t.Run("Resolved", func(t *testing.T) {
+		secretPath := filepath.Join(t.TempDir(), "pg_url")
+		require.NoError(t, os.WriteFile(secretPath, []byte("postgresql://backup:p#ss: word@db/app\n"), 0600))
+		t.Setenv("EASY_BACKUP_TEST_BUCKET", "backups")
+		t.Setenv("EASY_BACKUP_TEST_PARALLEL", "4")
+
+		path := writeConfig(t, `
+global:
+  s3:
+    bucket: ${EASY_BACKUP_TEST_BUCKET}
+    credentials:
+      region: "${env:EASY_BACKUP_TEST_REGION:-eu-west-1}"
+strategies:
+  - name: "app"
+    database_type: "postgres"
+    database_url: ${file:`+secretPath+`}
+  - name: "physical"
+    database_type: "mariabackup"
+    database_url: "mysql://backup@db:3306"
+    physical:
+      parallel: ${EASY_BACKUP_TEST_PARALLEL}
+    # database_url: ${EASY_BACKUP_TEST_UNSET} is a comment and not resolved
+`)
+		config, err := LoadConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, "backups", config.Global.S3.Bucket)
+		assert.Equal(t, "eu-west-1", config.Global.S3.Credentials.Region)
+		assert.Equal(t, "postgresql://backup:p#ss: word@db/app", config.Strategies[0].DatabaseURL)
+		assert.Equal(t, 4, config.Strategies[1].Physical.Parallel)
+	})
+
+	t.Run("Escaped", func(t *testing.T) {
+		t.Setenv("EASY_BACKUP_TEST_BUCKET", "backups")
+		path := writeConfig(t, `
+global:
+  s3:
+    bucket: ${EASY_BACKUP_TEST_BUCKET}
+strategies:
+  - name: "app"
+    database_type: "postgres"
+    database_url: "postgresql://backup:pa$${ss}@db/app"
+    hooks:
+      on_failure:
+        - command: 'echo "$${EASY_BACKUP_STRATEGY} failed in $$HOME"'
+`)
+		config, err := LoadConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, "backups", config.Global.S3.Bucket)
+		assert.Equal(t, "postgresql://backup:pa${ss}@db/app", config.Strategies[0].DatabaseURL)
+		assert.Equal(t, `echo "${EASY_BACKUP_STRATEGY} failed in $$HOME"`, config.Strategies[0].Hooks.OnFailure[0].Command)
+	})
+
+	t.Run("Unresolved", func(t *testing.T) {
+		path := writeConfig(t, `
+strategies:
+  - name: "app"
+    database_type: "postgres"
+    database_url: "${EASY_BACKUP_TEST_UNSET}"
+    slack:
+      bot_token: "${file:/nonexistent/slack_token}"
+      channel_id: "${unknown:value}"
+`)
+		_, err := LoadConfig(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "line 5: ${EASY_BACKUP_TEST_UNSET}: environment variable EASY_BACKUP_TEST_UNSET is not set")
+		assert.Contains(t, err.Error(), "line 7: ${file:/nonexistent/slack_token}")
+		assert.Contains(t, err.Error(), `line 8: ${unknown:value}: unknown reference type "unknown"`)
+
+		config, err := LoadConfigUnresolved(path)
+		require.NoError(t, err)
+		assert.Equal(t, "${EASY_BACKUP_TEST_UNSET}", config.Strategies[0].DatabaseURL)
+	})
+
+	t.Run("Vault", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Vault-Token") != "test-token" || r.URL.Path != "/v1/secret/data/easy-backup" {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"errors":["permission denied"]}`))
+				return
+			}
+			w.Write([]byte(`{"data":{"data":{"database_url":"postgresql://backup:secret@db/app"},"metadata":{"version":3}}}`))
+		}))
+		defer server.Close()
+		t.Setenv("VAULT_ADDR", server.URL)
+		t.Setenv("VAULT_TOKEN", "test-token")
+
+		path := writeConfig(t, `
+strategies:
+  - name: "app"
+    database_type: "postgres"
+    database_url: "${vault:secret/data/easy-backup#database_url}"
+`)
+		config, err := LoadConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, "postgresql://backup:secret@db/app", config.Strategies[0].DatabaseURL)
+
+		path = writeConfig(t, `
+strategies:
+  - name: "app"
+    database_type: "postgres"
+    database_url: "${vault:secret/data/easy-backup#missing}"
+    fallback_database_url: "${vault:secret/data/other#database_url}"
+`)
+		_, err = LoadConfig(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "key missing not found in secret/data/easy-backup")
+		assert.Contains(t, err.Error(), "permission denied")
+	})
+
+	t.Run("CustomResolver", func(t *testing.T) {
+		RegisterSecretResolver("test", SecretResolverFunc(func(reference string) (string, error) {
+			return strings.ToUpper(reference), nil
+		}))
+		defer func() {
+			secretResolversMu.Lock()
+			delete(secretResolvers, "test")
+			secretResolversMu.Unlock()
+		}()
+
+		path := writeConfig(t, `
+strategies:
+  - name: "app"
+    database_type: "postgres"
+    database_url: "postgresql://${test:backup}@db/app"
+`)
+		config, err := LoadConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, "postgresql://BACKUP@db/app", config.Strategies[0].DatabaseURL)
+	})
+}
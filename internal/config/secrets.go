@@ -0,0 +1,161 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"easy-backup/internal/vault"
+)
+
+// SecretResolver resolves the references of one type in configuration values, e.g. the
+// path of ${file:/run/secrets/pg_url}
+type SecretResolver interface {
+	Resolve(reference string) (string, error)
+}
+
+// SecretResolverFunc adapts a function to a SecretResolver
+type SecretResolverFunc func(reference string) (string, error)
+
+// Resolve calls the function
+func (f SecretResolverFunc) Resolve(reference string) (string, error) {
+	return f(reference)
+}
+
+var (
+	secretResolversMu sync.RWMutex
+	secretResolvers   = map[string]SecretResolver{
+		"env":   SecretResolverFunc(resolveEnv),
+		"file":  SecretResolverFunc(resolveFile),
+		"vault": SecretResolverFunc(resolveVault),
+	}
+)
+
+// RegisterSecretResolver makes references of the form ${scheme:reference} resolve through
+// the given resolver, replacing any resolver registered for the scheme
+func RegisterSecretResolver(scheme string, resolver SecretResolver) {
+	secretResolversMu.Lock()
+	defer secretResolversMu.Unlock()
+	secretResolvers[scheme] = resolver
+}
+
+// referencePattern matches ${...} references in configuration values, and the escaped
+// $${...} form that stands for a literal ${...}
+var referencePattern = regexp.MustCompile(`\$?\$\{[^}]+\}`)
+
+// vaultTimeout bounds the time spent reading a secret from Vault
+const vaultTimeout = 30 * time.Second
+
+// substituteReferences resolves the ${...} references in the values of a parsed
+// configuration. Every reference that cannot be resolved is reported in the error.
+func substituteReferences(node *yaml.Node) error {
+	var unresolved []string
+	substituteNode(node, &unresolved)
+	if len(unresolved) > 0 {
+		return fmt.Errorf("unresolved references:\n  %s", strings.Join(unresolved, "\n  "))
+	}
+	return nil
+}
+
+// substituteNode resolves the references in the scalars of a node and its children
+func substituteNode(node *yaml.Node, unresolved *[]string) {
+	for _, child := range node.Content {
+		substituteNode(child, unresolved)
+	}
+	if node.Kind != yaml.ScalarNode || !strings.Contains(node.Value, "${") {
+		return
+	}
+
+	node.Value = referencePattern.ReplaceAllStringFunc(node.Value, func(match string) string {
+		if strings.HasPrefix(match, "$$") {
+			return match[1:]
+		}
+		value, err := resolveReference(match[2 : len(match)-1])
+		if err != nil {
+			*unresolved = append(*unresolved, fmt.Sprintf("line %d: %s: %s", node.Line, match, err.Error()))
+			return match
+		}
+		return value
+	})
+	// Let plain values resolve to numbers or booleans after the substitution
+	if node.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+		node.Tag = ""
+	}
+}
+
+// resolveReference resolves the expression of a reference. Expressions without a
+// registered scheme, like ${VAR} and ${VAR:-default}, are environment variables.
+func resolveReference(expression string) (string, error) {
+	scheme, reference, found := strings.Cut(expression, ":")
+	if !found || strings.HasPrefix(reference, "-") {
+		return resolveEnv(expression)
+	}
+
+	secretResolversMu.RLock()
+	resolver, ok := secretResolvers[scheme]
+	secretResolversMu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("unknown reference type %q", scheme)
+	}
+	return resolver.Resolve(reference)
+}
+
+// resolveEnv resolves NAME or NAME:-default from the environment. The default is used
+// when the variable is unset or empty.
+func resolveEnv(reference string) (string, error) {
+	name, defaultValue, hasDefault := strings.Cut(reference, ":-")
+	if value := os.Getenv(name); value != "" {
+		return value, nil
+	}
+	if hasDefault {
+		return defaultValue, nil
+	}
+	if _, set := os.LookupEnv(name); set {
+		return "", nil
+	}
+	return "", fmt.Errorf("environment variable %s is not set", name)
+}
+
+// resolveFile returns the content of a file without its trailing newline, as written
+// by Docker and Kubernetes secrets
+func resolveFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// resolveVault reads a key of a Vault KV secret, written as path#key (e.g.
+// secret/data/easy-backup#database_url for KV version 2)
+func resolveVault(reference string) (string, error) {
+	path, key, found := strings.Cut(reference, "#")
+	if !found || path == "" || key == "" {
+		return "", fmt.Errorf("vault references must have the form path#key")
+	}
+	client, err := vault.NewClientFromEnv()
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), vaultTimeout)
+	defer cancel()
+	secret, err := client.Read(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	value, ok := secret.KVData()[key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in %s", key, path)
+	}
+	if text, ok := value.(string); ok {
+		return text, nil
+	}
+	return fmt.Sprint(value), nil
+}
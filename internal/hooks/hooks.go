@@ -0,0 +1,202 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"easy-backup/internal/config"
+	"easy-backup/internal/logger"
+)
+
+// Hook stages
+const (
+	StagePreBackup  = "pre_backup"
+	StagePostBackup = "post_backup"
+	StageOnSuccess  = "on_success"
+	StageOnFailure  = "on_failure"
+)
+
+// maxOutputLength limits the hook output kept in command logs
+const maxOutputLength = 4000
+
+// Run describes the backup run a hook is executed for
+type Run struct {
+	Strategy      string        `json:"strategy"`
+	DatabaseType  string        `json:"database_type"`
+	Stage         string        `json:"stage"`
+	Status        string        `json:"status"` // running, success or failed
+	ArtifactPaths []string      `json:"artifact_paths,omitempty"`
+	S3Keys        []string      `json:"s3_keys,omitempty"`
+	Size          int64         `json:"size,omitempty"`
+	Duration      time.Duration `json:"-"`
+	Error         string        `json:"error,omitempty"`
+}
+
+// Env returns the environment variables describing the run to shell hooks
+func (r Run) Env() []string {
+	env := []string{
+		"EASY_BACKUP_STRATEGY=" + r.Strategy,
+		"EASY_BACKUP_DATABASE_TYPE=" + r.DatabaseType,
+		"EASY_BACKUP_STAGE=" + r.Stage,
+		"EASY_BACKUP_STATUS=" + r.Status,
+		"EASY_BACKUP_ARTIFACT_PATH=" + first(r.ArtifactPaths),
+		"EASY_BACKUP_ARTIFACT_PATHS=" + strings.Join(r.ArtifactPaths, " "),
+		"EASY_BACKUP_S3_KEY=" + first(r.S3Keys),
+		"EASY_BACKUP_S3_KEYS=" + strings.Join(r.S3Keys, " "),
+		"EASY_BACKUP_SIZE=" + strconv.FormatInt(r.Size, 10),
+		"EASY_BACKUP_DURATION_SECONDS=" + strconv.FormatFloat(r.Duration.Seconds(), 'f', 0, 64),
+		"EASY_BACKUP_ERROR=" + r.Error,
+	}
+	return env
+}
+
+// MarshalJSON adds the duration in seconds to the JSON sent to HTTP hooks
+func (r Run) MarshalJSON() ([]byte, error) {
+	type run Run
+	return json.Marshal(struct {
+		run
+		DurationSeconds float64 `json:"duration_seconds"`
+	}{run(r), r.Duration.Seconds()})
+}
+
+// first returns the first value of a list or an empty string
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// HookService runs the shell commands and HTTP calls configured as strategy hooks
+type HookService struct {
+	logger     *logrus.Logger
+	httpClient *http.Client
+}
+
+// NewHookService creates a new hook service
+func NewHookService() *HookService {
+	return &HookService{
+		logger:     logger.GetLogger(),
+		httpClient: &http.Client{},
+	}
+}
+
+// RunHooks runs the hooks of a stage in order and returns their logs. Execution stops at the
+// first failing hook unless it allows continuing; the error of that hook is returned.
+func (hs *HookService) RunHooks(ctx context.Context, stage string, hookList []config.HookConfig, run Run) ([]string, error) {
+	run.Stage = stage
+	var logs []string
+
+	for _, hook := range hookList {
+		output, err := hs.runHook(ctx, hook, run)
+		entry := fmt.Sprintf("Hook %s (%s): ", hook.Name, stage)
+		if err != nil {
+			entry += fmt.Sprintf("failed: %s", err.Error())
+		} else {
+			entry += "succeeded"
+		}
+		if output != "" {
+			entry += "\n" + truncate(output)
+		}
+		logs = append(logs, entry)
+
+		if err != nil {
+			hs.logger.WithError(err).WithFields(logrus.Fields{
+				"strategy": run.Strategy,
+				"hook":     hook.Name,
+				"stage":    stage,
+			}).Warn("Hook failed")
+			if !hook.ContinueOnError {
+				return logs, fmt.Errorf("hook %s failed: %w", hook.Name, err)
+			}
+		}
+	}
+
+	return logs, nil
+}
+
+// runHook runs a single hook within its timeout and returns its output
+func (hs *HookService) runHook(ctx context.Context, hook config.HookConfig, run Run) (string, error) {
+	timeout, err := time.ParseDuration(hook.Timeout)
+	if err != nil || timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+	hookCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if hook.URL != "" {
+		return hs.callURL(hookCtx, hook, run)
+	}
+	return hs.runCommand(hookCtx, hook, run)
+}
+
+// runCommand runs a shell hook with the run described in its environment
+func (hs *HookService) runCommand(ctx context.Context, hook config.HookConfig, run Run) (string, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", hook.Command)
+	cmd.Env = append(os.Environ(), run.Env()...)
+	// Processes started by the command may keep the output open after it was killed
+	cmd.WaitDelay = time.Second
+
+	output, err := cmd.CombinedOutput()
+	trimmed := strings.TrimSpace(string(output))
+	if ctx.Err() == context.DeadlineExceeded {
+		return trimmed, fmt.Errorf("timed out")
+	}
+	if err != nil {
+		return trimmed, err
+	}
+	return trimmed, nil
+}
+
+// callURL sends the run as JSON to an HTTP hook; non-2xx responses are failures
+func (hs *HookService) callURL(ctx context.Context, hook config.HookConfig, run Run) (string, error) {
+	body, err := json.Marshal(run)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode hook payload: %w", err)
+	}
+
+	method := hook.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequestWithContext(ctx, method, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range hook.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := hs.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxOutputLength))
+	output := strings.TrimSpace(string(responseBody))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return output, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return output, nil
+}
+
+// truncate keeps the tail of long hook output
+func truncate(output string) string {
+	if len(output) > maxOutputLength {
+		return "..." + output[len(output)-maxOutputLength:]
+	}
+	return output
+}
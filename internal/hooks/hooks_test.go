@@ -0,0 +1,123 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"easy-backup/internal/config"
+)
+
+func testRun() Run {
+	return Run{
+		Strategy:      "postgres-prod",
+		DatabaseType:  "postgres",
+		Status:        "success",
+		ArtifactPaths: []string{"/tmp/db-backup/postgres-prod-20230101-120000.dump.gz"},
+		S3Keys:        []string{"backups/postgres-prod/postgres-prod-20230101-120000.dump.gz"},
+		Size:          2048,
+		Duration:      90 * time.Second,
+	}
+}
+
+func TestRunEnv(t *testing.T) {
+	env := testRun().Env()
+
+	assert.Contains(t, env, "EASY_BACKUP_STRATEGY=postgres-prod")
+	assert.Contains(t, env, "EASY_BACKUP_STATUS=success")
+	assert.Contains(t, env, "EASY_BACKUP_ARTIFACT_PATH=/tmp/db-backup/postgres-prod-20230101-120000.dump.gz")
+	assert.Contains(t, env, "EASY_BACKUP_S3_KEY=backups/postgres-prod/postgres-prod-20230101-120000.dump.gz")
+	assert.Contains(t, env, "EASY_BACKUP_SIZE=2048")
+	assert.Contains(t, env, "EASY_BACKUP_DURATION_SECONDS=90")
+	assert.Contains(t, env, "EASY_BACKUP_ERROR=")
+}
+
+func TestHookService_RunHooks(t *testing.T) {
+	service := NewHookService()
+
+	t.Run("CommandReceivesEnvironment", func(t *testing.T) {
+		logs, err := service.RunHooks(context.Background(), StagePostBackup, []config.HookConfig{
+			{Name: "echo", Command: "echo \"$EASY_BACKUP_STAGE $EASY_BACKUP_STRATEGY $EASY_BACKUP_S3_KEY\"", Timeout: "5s"},
+		}, testRun())
+
+		require.NoError(t, err)
+		require.Len(t, logs, 1)
+		assert.Contains(t, logs[0], "Hook echo (post_backup): succeeded")
+		assert.Contains(t, logs[0], "post_backup postgres-prod backups/postgres-prod/postgres-prod-20230101-120000.dump.gz")
+	})
+
+	t.Run("FailureStopsExecution", func(t *testing.T) {
+		logs, err := service.RunHooks(context.Background(), StagePreBackup, []config.HookConfig{
+			{Name: "pause", Command: "echo cannot pause; exit 3", Timeout: "5s"},
+			{Name: "flush", Command: "echo flushed", Timeout: "5s"},
+		}, testRun())
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "hook pause failed")
+		require.Len(t, logs, 1)
+		assert.Contains(t, logs[0], "cannot pause")
+	})
+
+	t.Run("ContinueOnError", func(t *testing.T) {
+		logs, err := service.RunHooks(context.Background(), StagePreBackup, []config.HookConfig{
+			{Name: "pause", Command: "exit 1", Timeout: "5s", ContinueOnError: true},
+			{Name: "flush", Command: "echo flushed", Timeout: "5s"},
+		}, testRun())
+
+		require.NoError(t, err)
+		assert.Len(t, logs, 2)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		start := time.Now()
+		_, err := service.RunHooks(context.Background(), StagePreBackup, []config.HookConfig{
+			{Name: "slow", Command: "sleep 5", Timeout: "100ms"},
+		}, testRun())
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "timed out")
+		assert.Less(t, time.Since(start), 4*time.Second)
+	})
+
+	t.Run("HTTP", func(t *testing.T) {
+		var received map[string]interface{}
+		var authorization string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			w.Write([]byte("accepted"))
+		}))
+		defer server.Close()
+
+		logs, err := service.RunHooks(context.Background(), StageOnSuccess, []config.HookConfig{
+			{Name: "notify", URL: server.URL, Method: "POST", Headers: map[string]string{"Authorization": "Bearer token"}, Timeout: "5s"},
+		}, testRun())
+
+		require.NoError(t, err)
+		assert.Contains(t, logs[0], "accepted")
+		assert.Equal(t, "Bearer token", authorization)
+		assert.Equal(t, "on_success", received["stage"])
+		assert.Equal(t, "postgres-prod", received["strategy"])
+		assert.Equal(t, float64(90), received["duration_seconds"])
+	})
+
+	t.Run("HTTPErrorStatus", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "maintenance", http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		_, err := service.RunHooks(context.Background(), StageOnFailure, []config.HookConfig{
+			{Name: "notify", URL: server.URL, Timeout: "5s"},
+		}, testRun())
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "503")
+	})
+}
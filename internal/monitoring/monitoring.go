@@ -50,6 +50,7 @@ type MonitoringService struct {
 	slackService   *notification.SlackService
 	strategyStatus map[string]StrategyStatus
 	statusMutex    sync.RWMutex
+	handlers       map[string]http.Handler // Additional endpoints, e.g. Slack commands
 
 	// Prometheus metrics
 	backupDuration *prometheus.HistogramVec
@@ -111,6 +112,7 @@ func NewMonitoringService(cfg *config.Config, s3Service *storage.S3Service, slac
 		s3Service:      s3Service,
 		slackService:   slackService,
 		strategyStatus: make(map[string]StrategyStatus),
+		handlers:       make(map[string]http.Handler),
 		backupDuration: backupDuration,
 		backupSize:     backupSize,
 		backupSuccess:  backupSuccess,
@@ -119,6 +121,11 @@ func NewMonitoringService(cfg *config.Config, s3Service *storage.S3Service, slac
 	}
 }
 
+// Handle registers an additional endpoint, before the HTTP server is started
+func (ms *MonitoringService) Handle(path string, handler http.Handler) {
+	ms.handlers[path] = handler
+}
+
 // StartHTTPServer starts the HTTP server for health checks and metrics
 func (ms *MonitoringService) StartHTTPServer() error {
 	mux := http.NewServeMux()
@@ -131,6 +138,10 @@ func (ms *MonitoringService) StartHTTPServer() error {
 		mux.Handle(ms.config.Global.Monitoring.Metrics.Path, promhttp.Handler())
 	}
 
+	for path, handler := range ms.handlers {
+		mux.Handle(path, handler)
+	}
+
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", ms.config.Global.Monitoring.HealthCheck.Port),
 		Handler: mux,
@@ -0,0 +1,333 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"easy-backup/internal/backup"
+	"easy-backup/internal/config"
+	"easy-backup/internal/logger"
+)
+
+// alertRequestTimeout bounds a single request to PagerDuty or Opsgenie
+const alertRequestTimeout = 10 * time.Second
+
+// opsgeniePriorities maps alert severities to Opsgenie priorities
+var opsgeniePriorities = map[string]string{
+	config.SeverityCritical: "P1",
+	config.SeverityError:    "P2",
+	config.SeverityWarning:  "P3",
+	config.SeverityInfo:     "P5",
+}
+
+// AlertNotifier pages on-call through PagerDuty and Opsgenie for backups that failed after
+// all retries, and resolves the incident with the next successful backup of the strategy.
+// Failed attempts that are retried successfully never reach it.
+type AlertNotifier struct {
+	config     *config.Config
+	logger     *logrus.Logger
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	triggered map[string]bool // Open incidents by dedup key, absent when unknown since the start
+}
+
+// alert is an incident of a strategy
+type alert struct {
+	DedupKey     string
+	Strategy     string
+	DatabaseType string
+	Severity     string
+	RoutingKey   string // PagerDuty routing key, empty to skip PagerDuty
+	Summary      string
+	Details      map[string]interface{}
+}
+
+// NewAlertNotifier creates a notifier for the configured incident management services
+func NewAlertNotifier(cfg *config.Config) *AlertNotifier {
+	return &AlertNotifier{
+		config:     cfg,
+		logger:     logger.GetLogger(),
+		httpClient: &http.Client{Timeout: alertRequestTimeout},
+		triggered:  map[string]bool{},
+	}
+}
+
+// NotifyStarted does nothing, only final results page
+func (an *AlertNotifier) NotifyStarted(ctx context.Context, run *Run) error {
+	return nil
+}
+
+// NotifyProgress does nothing, failed attempts that are retried do not page
+func (an *AlertNotifier) NotifyProgress(ctx context.Context, run *Run, strategy, message string) error {
+	return nil
+}
+
+// NotifyOutput does nothing, the command logs are part of the incident details
+func (an *AlertNotifier) NotifyOutput(ctx context.Context, run *Run, strategy, output string) error {
+	return nil
+}
+
+// NotifyRetry does nothing, failed attempts that are retried do not page
+func (an *AlertNotifier) NotifyRetry(ctx context.Context, run *Run, strategy, message string) error {
+	return nil
+}
+
+// NotifyDetailedError does nothing, the incident is triggered with the result
+func (an *AlertNotifier) NotifyDetailedError(ctx context.Context, run *Run, strategy string, result *backup.BackupResult) error {
+	return nil
+}
+
+// NotifyResult triggers an incident for every failed strategy of the run and resolves the
+// incidents of successful ones. Incremental backups have their own incidents, resolved by
+// the next successful incremental backup.
+func (an *AlertNotifier) NotifyResult(ctx context.Context, run *Run, results []*backup.BackupResult, overallSuccess bool) error {
+	var errs []error
+	for _, result := range results {
+		incident, ok := an.newAlert(result, run.incremental())
+		if !ok {
+			continue
+		}
+
+		if !result.Success {
+			if err := an.trigger(ctx, incident); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			an.setTriggered(incident.DedupKey, true)
+			continue
+		}
+
+		// Resolve incidents opened by this process, and once after a restart when the
+		// state of the incident is unknown
+		an.mu.Lock()
+		open, known := an.triggered[incident.DedupKey]
+		an.mu.Unlock()
+		if known && !open {
+			continue
+		}
+		if err := an.resolve(ctx, incident); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		an.setTriggered(incident.DedupKey, false)
+	}
+	return errors.Join(errs...)
+}
+
+// NotifyStale triggers the incident of a strategy without a recent successful backup. It is
+// resolved by the next successful backup.
+func (an *AlertNotifier) NotifyStale(ctx context.Context, run *Run, strategy string, lastSuccess time.Time) error {
+	incident, ok := an.newAlert(&backup.BackupResult{Strategy: strategy, Success: true}, false)
+	if !ok {
+		return nil
+	}
+	incident.Summary = fmt.Sprintf("No successful backup of %s, last successful backup: %s", strategy, formatLastSuccess(lastSuccess))
+	incident.Details = map[string]interface{}{"last_success": formatLastSuccess(lastSuccess)}
+
+	if err := an.trigger(ctx, incident); err != nil {
+		return err
+	}
+	an.setTriggered(incident.DedupKey, true)
+	return nil
+}
+
+// NotifyReport does nothing, reports do not page
+func (an *AlertNotifier) NotifyReport(ctx context.Context, run *Run, report *Report) error {
+	return nil
+}
+
+func (an *AlertNotifier) setTriggered(dedupKey string, open bool) {
+	an.mu.Lock()
+	defer an.mu.Unlock()
+	an.triggered[dedupKey] = open
+}
+
+// newAlert describes the incident of a result, false when the strategy does not page. The
+// incidents of incremental backups use the dedup key of the strategy with an
+// "/incremental" suffix.
+func (an *AlertNotifier) newAlert(result *backup.BackupResult, incremental bool) (alert, bool) {
+	alerting := an.config.Global.Notifications.Alerting
+	incident := alert{
+		DedupKey:   "easy-backup/" + result.Strategy,
+		Strategy:   result.Strategy,
+		Severity:   alerting.Severity,
+		RoutingKey: alerting.PagerDuty.RoutingKey,
+	}
+	for _, strategy := range an.config.Strategies {
+		if strategy.Name != result.Strategy {
+			continue
+		}
+		if strategy.Alerting.Disabled {
+			return alert{}, false
+		}
+		incident.DatabaseType = strategy.DatabaseType
+		if strategy.Alerting.Severity != "" {
+			incident.Severity = strategy.Alerting.Severity
+		}
+		if strategy.Alerting.RoutingKey != "" {
+			incident.RoutingKey = strategy.Alerting.RoutingKey
+		}
+		if strategy.Alerting.DedupKey != "" {
+			incident.DedupKey = strategy.Alerting.DedupKey
+		}
+	}
+	if incident.Severity == "" {
+		incident.Severity = config.SeverityError
+	}
+	kind := "Backup"
+	if incremental {
+		incident.DedupKey += "/incremental"
+		kind = "Incremental backup"
+	}
+
+	if !result.Success {
+		message := "unknown error"
+		if result.Error != nil {
+			message = result.Error.Error()
+		}
+		incident.Summary = fmt.Sprintf("%s of %s failed: %s", kind, result.Strategy, message)
+		if len(incident.Summary) > 1024 {
+			incident.Summary = incident.Summary[:1021] + "..."
+		}
+		incident.Details = map[string]interface{}{
+			"error":            message,
+			"duration":         result.Duration.Round(time.Second).String(),
+			"command_log_tail": strings.Join(commandLogTail(result.CommandLogs), "\n"),
+		}
+		if !result.StartTime.IsZero() {
+			incident.Details["started_at"] = result.StartTime.UTC().Format(time.RFC3339)
+		}
+	}
+	return incident, true
+}
+
+// trigger opens or updates the incident in every configured service
+func (an *AlertNotifier) trigger(ctx context.Context, incident alert) error {
+	var errs []error
+	if incident.RoutingKey != "" {
+		if err := an.sendPagerDuty(ctx, incident, "trigger"); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if an.config.Global.Notifications.Alerting.Opsgenie.APIKey != "" {
+		if err := an.createOpsgenieAlert(ctx, incident); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		an.logger.WithFields(logrus.Fields{
+			"strategy":  incident.Strategy,
+			"dedup_key": incident.DedupKey,
+			"severity":  incident.Severity,
+		}).Info("Triggered incident for failed backup")
+	}
+	return errors.Join(errs...)
+}
+
+// resolve closes the incident in every configured service
+func (an *AlertNotifier) resolve(ctx context.Context, incident alert) error {
+	var errs []error
+	if incident.RoutingKey != "" {
+		if err := an.sendPagerDuty(ctx, incident, "resolve"); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if an.config.Global.Notifications.Alerting.Opsgenie.APIKey != "" {
+		if err := an.closeOpsgenieAlert(ctx, incident); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sendPagerDuty sends a trigger or resolve event to the PagerDuty Events API v2
+func (an *AlertNotifier) sendPagerDuty(ctx context.Context, incident alert, action string) error {
+	event := map[string]interface{}{
+		"routing_key":  incident.RoutingKey,
+		"event_action": action,
+		"dedup_key":    incident.DedupKey,
+	}
+	if action == "trigger" {
+		event["client"] = "easy-backup"
+		event["payload"] = map[string]interface{}{
+			"summary":        incident.Summary,
+			"source":         "easy-backup",
+			"severity":       incident.Severity,
+			"component":      incident.Strategy,
+			"group":          incident.DatabaseType,
+			"class":          "backup",
+			"custom_details": incident.Details,
+		}
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("pagerduty: failed to encode event: %w", err)
+	}
+	if err := postJSON(ctx, an.httpClient, an.config.Global.Notifications.Alerting.PagerDuty.URL, body, nil); err != nil {
+		return fmt.Errorf("pagerduty: failed to %s %s: %w", action, incident.DedupKey, err)
+	}
+	return nil
+}
+
+// createOpsgenieAlert creates an Opsgenie alert, deduplicated by its alias
+func (an *AlertNotifier) createOpsgenieAlert(ctx context.Context, incident alert) error {
+	message := incident.Summary
+	if len(message) > 130 {
+		message = message[:127] + "..."
+	}
+	details := map[string]string{}
+	for key, value := range incident.Details {
+		details[key] = fmt.Sprint(value)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"message":     message,
+		"alias":       incident.DedupKey,
+		"description": incident.Summary,
+		"priority":    opsgeniePriorities[incident.Severity],
+		"entity":      incident.Strategy,
+		"source":      "easy-backup",
+		"tags":        []string{"easy-backup", incident.Strategy},
+		"details":     details,
+	})
+	if err != nil {
+		return fmt.Errorf("opsgenie: failed to encode alert: %w", err)
+	}
+	if err := an.postOpsgenie(ctx, "/v2/alerts", body); err != nil {
+		return fmt.Errorf("opsgenie: failed to create alert %s: %w", incident.DedupKey, err)
+	}
+	return nil
+}
+
+// closeOpsgenieAlert closes the Opsgenie alert of an incident
+func (an *AlertNotifier) closeOpsgenieAlert(ctx context.Context, incident alert) error {
+	body, err := json.Marshal(map[string]string{
+		"source": "easy-backup",
+		"note":   fmt.Sprintf("Backup of %s succeeded", incident.Strategy),
+	})
+	if err != nil {
+		return fmt.Errorf("opsgenie: failed to encode request: %w", err)
+	}
+	path := "/v2/alerts/" + url.PathEscape(incident.DedupKey) + "/close?identifierType=alias"
+	if err := an.postOpsgenie(ctx, path, body); err != nil {
+		return fmt.Errorf("opsgenie: failed to close alert %s: %w", incident.DedupKey, err)
+	}
+	return nil
+}
+
+func (an *AlertNotifier) postOpsgenie(ctx context.Context, path string, body []byte) error {
+	opsgenie := an.config.Global.Notifications.Alerting.Opsgenie
+	headers := map[string]string{"Authorization": "GenieKey " + opsgenie.APIKey}
+	return postJSON(ctx, an.httpClient, strings.TrimSuffix(opsgenie.URL, "/")+path, body, headers)
+}
@@ -0,0 +1,150 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"easy-backup/internal/backup"
+	"easy-backup/internal/config"
+)
+
+// alertRequest is a request received by the fake PagerDuty and Opsgenie APIs
+type alertRequest struct {
+	path          string
+	authorization string
+	body          map[string]interface{}
+}
+
+func newAlertServer(t *testing.T) (*httptest.Server, func() []alertRequest) {
+	var mu sync.Mutex
+	var requests []alertRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		mu.Lock()
+		requests = append(requests, alertRequest{path: r.URL.RequestURI(), authorization: r.Header.Get("Authorization"), body: body})
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []alertRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]alertRequest(nil), requests...)
+	}
+}
+
+func TestAlertNotifier_PagerDuty(t *testing.T) {
+	server, received := newAlertServer(t)
+	cfg := &config.Config{
+		Global: config.GlobalConfig{Notifications: config.NotificationsConfig{Alerting: config.AlertingConfig{
+			PagerDuty: config.PagerDutyConfig{RoutingKey: "global-key", URL: server.URL + "/v2/enqueue"},
+			Severity:  config.SeverityError,
+		}}},
+		Strategies: []config.StrategyConfig{
+			{Name: "postgres-main", DatabaseType: "postgres", Alerting: config.StrategyAlertingConfig{Severity: config.SeverityCritical, RoutingKey: "dba-key"}},
+			{Name: "redis-cache", DatabaseType: "redis", Alerting: config.StrategyAlertingConfig{Disabled: true}},
+			{Name: "mysql-main", DatabaseType: "mysql"},
+		},
+	}
+	notifier := NewAlertNotifier(cfg)
+	ctx := context.Background()
+	run := NewRun([]string{"postgres-main"}, config.SlackConfig{})
+
+	failed := &backup.BackupResult{Strategy: "postgres-main", Error: errors.New("connection refused"), CommandLogs: []string{"pg_dump: error: connection refused"}}
+	require.NoError(t, notifier.NotifyResult(ctx, run, []*backup.BackupResult{failed}, false))
+
+	requests := received()
+	require.Len(t, requests, 1)
+	event := requests[0].body
+	assert.Equal(t, "/v2/enqueue", requests[0].path)
+	assert.Equal(t, "dba-key", event["routing_key"])
+	assert.Equal(t, "trigger", event["event_action"])
+	assert.Equal(t, "easy-backup/postgres-main", event["dedup_key"])
+	payload := event["payload"].(map[string]interface{})
+	assert.Equal(t, "Backup of postgres-main failed: connection refused", payload["summary"])
+	assert.Equal(t, "critical", payload["severity"])
+	assert.Equal(t, "postgres", payload["group"])
+	assert.Equal(t, "pg_dump: error: connection refused", payload["custom_details"].(map[string]interface{})["command_log_tail"])
+
+	// The next success resolves the incident, later successes do not send anything
+	succeeded := &backup.BackupResult{Strategy: "postgres-main", Success: true}
+	require.NoError(t, notifier.NotifyResult(ctx, run, []*backup.BackupResult{succeeded}, true))
+	require.NoError(t, notifier.NotifyResult(ctx, run, []*backup.BackupResult{succeeded}, true))
+	requests = received()
+	require.Len(t, requests, 2)
+	assert.Equal(t, map[string]interface{}{"routing_key": "dba-key", "event_action": "resolve", "dedup_key": "easy-backup/postgres-main"}, requests[1].body)
+
+	// Disabled strategies never page, others use the global routing key and severity
+	results := []*backup.BackupResult{
+		{Strategy: "redis-cache", Error: errors.New("timeout")},
+		{Strategy: "mysql-main", Error: errors.New("access denied")},
+	}
+	require.NoError(t, notifier.NotifyResult(ctx, NewRun([]string{"redis-cache", "mysql-main"}, config.SlackConfig{}), results, false))
+	requests = received()
+	require.Len(t, requests, 3)
+	assert.Equal(t, "global-key", requests[2].body["routing_key"])
+	assert.Equal(t, "error", requests[2].body["payload"].(map[string]interface{})["severity"])
+}
+
+func TestAlertNotifier_Opsgenie(t *testing.T) {
+	server, received := newAlertServer(t)
+	cfg := &config.Config{
+		Global: config.GlobalConfig{Notifications: config.NotificationsConfig{Alerting: config.AlertingConfig{
+			Opsgenie: config.OpsgenieConfig{APIKey: "genie-key", URL: server.URL},
+			Severity: config.SeverityWarning,
+		}}},
+		Strategies: []config.StrategyConfig{{Name: "mongo-main", Alerting: config.StrategyAlertingConfig{DedupKey: "mongo-backups"}}},
+	}
+	notifier := NewAlertNotifier(cfg)
+	ctx := context.Background()
+	run := NewRun([]string{"mongo-main"}, config.SlackConfig{})
+
+	failed := &backup.BackupResult{Strategy: "mongo-main", Error: errors.New("mongodump exited with status 1")}
+	require.NoError(t, notifier.NotifyResult(ctx, run, []*backup.BackupResult{failed}, false))
+	require.NoError(t, notifier.NotifyResult(ctx, run, []*backup.BackupResult{{Strategy: "mongo-main", Success: true}}, true))
+
+	requests := received()
+	require.Len(t, requests, 2)
+	assert.Equal(t, "/v2/alerts", requests[0].path)
+	assert.Equal(t, "GenieKey genie-key", requests[0].authorization)
+	assert.Equal(t, "mongo-backups", requests[0].body["alias"])
+	assert.Equal(t, "P3", requests[0].body["priority"])
+	assert.Equal(t, "Backup of mongo-main failed: mongodump exited with status 1", requests[0].body["message"])
+	assert.Equal(t, "/v2/alerts/mongo-backups/close?identifierType=alias", requests[1].path)
+}
+
+func TestAlertNotifier_Incremental(t *testing.T) {
+	server, received := newAlertServer(t)
+	cfg := &config.Config{
+		Global: config.GlobalConfig{Notifications: config.NotificationsConfig{Alerting: config.AlertingConfig{
+			PagerDuty: config.PagerDutyConfig{RoutingKey: "global-key", URL: server.URL + "/v2/enqueue"},
+		}}},
+		Strategies: []config.StrategyConfig{{Name: "mysql-main", DatabaseType: "mysql"}},
+	}
+	notifier := NewAlertNotifier(cfg)
+	ctx := context.Background()
+	full := NewRun([]string{"mysql-main"}, config.SlackConfig{})
+	incremental := NewRun([]string{"mysql-main" + IncrementalSuffix}, config.SlackConfig{})
+
+	// A failed increment opens its own incident, a successful full backup does not resolve it
+	require.NoError(t, notifier.NotifyResult(ctx, incremental, []*backup.BackupResult{{Strategy: "mysql-main", Error: errors.New("binlog purged")}}, false))
+	require.NoError(t, notifier.NotifyResult(ctx, full, []*backup.BackupResult{{Strategy: "mysql-main", Success: true}}, true))
+	require.NoError(t, notifier.NotifyResult(ctx, incremental, []*backup.BackupResult{{Strategy: "mysql-main", Success: true}}, true))
+
+	requests := received()
+	require.Len(t, requests, 3)
+	assert.Equal(t, "trigger", requests[0].body["event_action"])
+	assert.Equal(t, "easy-backup/mysql-main/incremental", requests[0].body["dedup_key"])
+	assert.Equal(t, "Incremental backup of mysql-main failed: binlog purged", requests[0].body["payload"].(map[string]interface{})["summary"])
+	assert.Equal(t, map[string]interface{}{"routing_key": "global-key", "event_action": "resolve", "dedup_key": "easy-backup/mysql-main"}, requests[1].body)
+	assert.Equal(t, map[string]interface{}{"routing_key": "global-key", "event_action": "resolve", "dedup_key": "easy-backup/mysql-main/incremental"}, requests[2].body)
+}
@@ -0,0 +1,226 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"easy-backup/internal/backup"
+	"easy-backup/internal/config"
+	"easy-backup/internal/logger"
+)
+
+// chatRequestTimeout bounds a single request to a Teams or Discord webhook
+const chatRequestTimeout = 10 * time.Second
+
+// cardStatus selects the color of a card
+type cardStatus int
+
+const (
+	cardRunning cardStatus = iota
+	cardSuccess
+	cardFailure
+	cardWarning
+)
+
+// card is a started, result or error notification, rendered as an Adaptive Card for
+// Teams and as embeds for Discord
+type card struct {
+	Title    string
+	Status   cardStatus
+	Facts    []cardFact
+	Sections []cardSection // One per strategy of a result
+	Log      []string      // Tail of the command log of a failed backup
+}
+
+// cardSection groups the facts of one strategy
+type cardSection struct {
+	Heading string
+	Facts   []cardFact
+}
+
+// cardFact is a labelled value
+type cardFact struct {
+	Name  string
+	Value string
+}
+
+// ChatNotifier posts backup start, result and error cards to the incoming webhooks of
+// chat channels, configured globally or per strategy
+type ChatNotifier struct {
+	config     *config.Config
+	logger     *logrus.Logger
+	httpClient *http.Client
+	service    string                             // Name of the chat service, for logs and errors
+	webhookURL func(config.StrategyConfig) string // Webhook URL of a strategy
+	globalURL  string                             // Webhook URL of runs without a configured strategy
+	render     func(card) interface{}             // Builds the request body of a card
+}
+
+func newChatNotifier(cfg *config.Config, service string, webhookURL func(config.StrategyConfig) string, globalURL string, render func(card) interface{}) *ChatNotifier {
+	return &ChatNotifier{
+		config:     cfg,
+		logger:     logger.GetLogger(),
+		httpClient: &http.Client{Timeout: chatRequestTimeout},
+		service:    service,
+		webhookURL: webhookURL,
+		globalURL:  globalURL,
+		render:     render,
+	}
+}
+
+// NotifyStarted posts a card announcing the run
+func (cn *ChatNotifier) NotifyStarted(ctx context.Context, run *Run) error {
+	return cn.send(ctx, run.Strategies, card{
+		Title:  "🔄 Database Backup Started",
+		Status: cardRunning,
+		Facts: []cardFact{
+			{Name: "Strategies", Value: strings.Join(run.Strategies, ", ")},
+			{Name: "Started at", Value: run.StartedAt.UTC().Format("2006-01-02 15:04:05 UTC")},
+		},
+	})
+}
+
+// NotifyProgress does nothing, chat channels only get start, result and error cards
+func (cn *ChatNotifier) NotifyProgress(ctx context.Context, run *Run, strategy, message string) error {
+	return nil
+}
+
+// NotifyOutput does nothing, the command logs of failed backups are part of the error card
+func (cn *ChatNotifier) NotifyOutput(ctx context.Context, run *Run, strategy, output string) error {
+	return nil
+}
+
+// NotifyRetry does nothing, chat channels only get start, result and error cards
+func (cn *ChatNotifier) NotifyRetry(ctx context.Context, run *Run, strategy, message string) error {
+	return nil
+}
+
+// NotifyResult posts a card with the result of every strategy of the run
+func (cn *ChatNotifier) NotifyResult(ctx context.Context, run *Run, results []*backup.BackupResult, overallSuccess bool) error {
+	resultCard := card{Title: "✅ Database Backup Completed Successfully", Status: cardSuccess}
+	if !overallSuccess {
+		resultCard = card{Title: "❌ Database Backup Failed", Status: cardFailure}
+	}
+
+	strategies := run.Strategies
+	if len(results) > 0 {
+		strategies = nil
+	}
+	for _, result := range results {
+		strategies = append(strategies, result.Strategy)
+		resultCard.Sections = append(resultCard.Sections, resultSection(result))
+	}
+	return cn.send(ctx, strategies, resultCard)
+}
+
+// NotifyDetailedError posts a card with the error and command log of a failed backup
+func (cn *ChatNotifier) NotifyDetailedError(ctx context.Context, run *Run, strategy string, result *backup.BackupResult) error {
+	if result == nil {
+		return nil
+	}
+
+	errorCard := card{
+		Title:  fmt.Sprintf("❌ Backup of %s failed", strategy),
+		Status: cardFailure,
+		Log:    commandLogTail(result.CommandLogs),
+	}
+	if result.Error != nil {
+		errorCard.Facts = append(errorCard.Facts, cardFact{Name: "Error", Value: result.Error.Error()})
+	}
+	if !result.StartTime.IsZero() {
+		errorCard.Facts = append(errorCard.Facts, cardFact{Name: "Started at", Value: result.StartTime.UTC().Format("2006-01-02 15:04:05 UTC")})
+	}
+	if result.Duration > 0 {
+		errorCard.Facts = append(errorCard.Facts, cardFact{Name: "Duration before failure", Value: result.Duration.Round(time.Second).String()})
+	}
+	return cn.send(ctx, []string{strategy}, errorCard)
+}
+
+// NotifyStale posts a warning card for a strategy without a recent successful backup
+func (cn *ChatNotifier) NotifyStale(ctx context.Context, run *Run, strategy string, lastSuccess time.Time) error {
+	return cn.send(ctx, []string{strategy}, card{
+		Title:  fmt.Sprintf("⚠️ Backup of %s is overdue", strategy),
+		Status: cardWarning,
+		Facts: []cardFact{
+			{Name: "Strategy", Value: strategy},
+			{Name: "Last successful backup", Value: formatLastSuccess(lastSuccess)},
+		},
+	})
+}
+
+// NotifyReport posts the report card to the webhooks of every strategy of the run, once per
+// webhook
+func (cn *ChatNotifier) NotifyReport(ctx context.Context, run *Run, report *Report) error {
+	return cn.send(ctx, run.Strategies, reportCard(report))
+}
+
+// resultSection lists the facts of a backup result
+func resultSection(result *backup.BackupResult) cardSection {
+	section := cardSection{Heading: fmt.Sprintf("✅ %s: Success", result.Strategy)}
+	if !result.Success {
+		section.Heading = fmt.Sprintf("❌ %s: Failed", result.Strategy)
+		if result.Error != nil {
+			section.Facts = append(section.Facts, cardFact{Name: "Error", Value: result.Error.Error()})
+		}
+	} else {
+		section.Facts = append(section.Facts, cardFact{Name: "Size", Value: formatBytes(result.Size)})
+	}
+	section.Facts = append(section.Facts, cardFact{Name: "Duration", Value: result.Duration.Round(time.Second).String()})
+	if len(result.S3Locations) > 0 {
+		section.Facts = append(section.Facts, cardFact{Name: "S3 location", Value: strings.Join(result.S3Locations, "\n")})
+	}
+	for _, detail := range result.Details {
+		section.Facts = append(section.Facts, cardFact{Name: detail.Label, Value: detail.Value})
+	}
+	return section
+}
+
+// send posts a card to the webhooks of the given strategies
+func (cn *ChatNotifier) send(ctx context.Context, strategies []string, c card) error {
+	body, err := json.Marshal(cn.render(c))
+	if err != nil {
+		return fmt.Errorf("%s: failed to encode message: %w", cn.service, err)
+	}
+
+	for _, url := range cn.webhookURLs(strategies) {
+		if err := postJSON(ctx, cn.httpClient, url, body, nil); err != nil {
+			return fmt.Errorf("%s: %w", cn.service, err)
+		}
+	}
+
+	cn.logger.WithFields(logrus.Fields{
+		"service":    cn.service,
+		"strategies": strategies,
+	}).Debug("Chat notification sent")
+	return nil
+}
+
+// webhookURLs returns the distinct webhook URLs of the given strategies. Runs that do not
+// match a configured strategy, e.g. incremental backups, use the global webhook.
+func (cn *ChatNotifier) webhookURLs(strategies []string) []string {
+	var urls []string
+	for _, name := range strategies {
+		url := cn.globalURL
+		for _, strategy := range cn.config.Strategies {
+			if strategy.Name == name {
+				url = cn.webhookURL(strategy)
+			}
+		}
+		if url != "" && !slices.Contains(urls, url) {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// anyStrategy reports whether a strategy of the config matches
+func anyStrategy(cfg *config.Config, match func(config.StrategyConfig) bool) bool {
+	return slices.ContainsFunc(cfg.Strategies, match)
+}
@@ -0,0 +1,124 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"easy-backup/internal/backup"
+	"easy-backup/internal/config"
+)
+
+// chatSink records the JSON bodies posted to each path
+type chatSink struct {
+	server *httptest.Server
+	mu     sync.Mutex
+	bodies map[string][]map[string]interface{}
+}
+
+func newChatSink(t *testing.T) *chatSink {
+	sink := &chatSink{bodies: map[string][]map[string]interface{}{}}
+	sink.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		sink.mu.Lock()
+		sink.bodies[r.URL.Path] = append(sink.bodies[r.URL.Path], body)
+		sink.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(sink.server.Close)
+	return sink
+}
+
+func (s *chatSink) received(path string) []map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bodies[path]
+}
+
+func TestTeamsNotifier(t *testing.T) {
+	sink := newChatSink(t)
+	cfg := &config.Config{
+		Global: config.GlobalConfig{Teams: config.TeamsConfig{WebhookURL: sink.server.URL + "/ops"}},
+		Strategies: []config.StrategyConfig{
+			{Name: "postgres-main", Teams: config.TeamsConfig{WebhookURL: sink.server.URL + "/dba"}},
+			{Name: "mysql-main", Teams: config.TeamsConfig{WebhookURL: sink.server.URL + "/ops"}},
+		},
+	}
+	notifier := NewTeamsNotifier(cfg)
+	ctx := context.Background()
+
+	run := NewRun([]string{"postgres-main"}, config.SlackConfig{})
+	require.NoError(t, notifier.NotifyStarted(ctx, run))
+	require.NoError(t, notifier.NotifyProgress(ctx, run, "postgres-main", "Uploading to S3..."))
+
+	result := &backup.BackupResult{
+		Strategy:    "postgres-main",
+		Success:     true,
+		Size:        2048,
+		Duration:    90 * time.Second,
+		S3Locations: []string{"s3://backups/postgres-main/postgres-main-20240101-020000.sql.gz"},
+	}
+	require.NoError(t, notifier.NotifyResult(ctx, run, []*backup.BackupResult{result}, true))
+
+	messages := sink.received("/dba")
+	require.Len(t, messages, 2, "progress updates are not posted")
+	assert.Empty(t, sink.received("/ops"))
+
+	attachment := messages[1]["attachments"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "application/vnd.microsoft.card.adaptive", attachment["contentType"])
+	content := attachment["content"].(map[string]interface{})
+	assert.Equal(t, "AdaptiveCard", content["type"])
+	body := content["body"].([]interface{})
+	title := body[0].(map[string]interface{})
+	assert.Equal(t, "✅ Database Backup Completed Successfully", title["text"])
+	assert.Equal(t, "Good", title["color"])
+	assert.Equal(t, "✅ postgres-main: Success", body[1].(map[string]interface{})["text"])
+	facts := body[2].(map[string]interface{})["facts"].([]interface{})
+	assert.Contains(t, facts, map[string]interface{}{"title": "Size", "value": "2.0 KB"})
+	assert.Contains(t, facts, map[string]interface{}{"title": "S3 location", "value": result.S3Locations[0]})
+
+	// Runs of unknown strategies, e.g. incremental backups, use the global webhook
+	require.NoError(t, notifier.NotifyStarted(ctx, NewRun([]string{"postgres-main (incremental)"}, config.SlackConfig{})))
+	assert.Len(t, sink.received("/ops"), 1)
+}
+
+func TestDiscordNotifier(t *testing.T) {
+	sink := newChatSink(t)
+	cfg := &config.Config{
+		Strategies: []config.StrategyConfig{
+			{Name: "mongo-main", Discord: config.DiscordConfig{WebhookURL: sink.server.URL + "/backups"}},
+			{Name: "redis-cache"},
+		},
+	}
+	notifier := NewDiscordNotifier(cfg)
+	ctx := context.Background()
+
+	result := &backup.BackupResult{
+		Strategy:    "mongo-main",
+		Error:       errors.New("mongodump exited with status 1"),
+		CommandLogs: []string{"connecting to mongodb://db:27017", "Failed: connection refused"},
+	}
+	run := NewRun([]string{"mongo-main", "redis-cache"}, config.SlackConfig{})
+	require.NoError(t, notifier.NotifyDetailedError(ctx, run, "mongo-main", result))
+	require.NoError(t, notifier.NotifyDetailedError(ctx, run, "redis-cache", result))
+
+	messages := sink.received("/backups")
+	require.Len(t, messages, 1, "strategies without a webhook are not posted")
+	embeds := messages[0]["embeds"].([]interface{})
+	require.Len(t, embeds, 1)
+	embed := embeds[0].(map[string]interface{})
+	assert.Equal(t, "❌ Backup of mongo-main failed", embed["title"])
+	assert.Equal(t, float64(0xe74c3c), embed["color"])
+	assert.Equal(t, "```\nconnecting to mongodb://db:27017\nFailed: connection refused\n```", embed["description"])
+	fields := embed["fields"].([]interface{})
+	assert.Equal(t, "mongodump exited with status 1", fields[0].(map[string]interface{})["value"])
+}
@@ -0,0 +1,72 @@
+package notification
+
+import (
+	"strings"
+
+	"easy-backup/internal/config"
+)
+
+// Discord embed limits
+const (
+	discordMaxEmbeds           = 10
+	discordMaxFieldValue       = 1024
+	discordMaxEmbedDescription = 4096
+)
+
+// NewDiscordNotifier creates a notifier posting embeds to Discord webhooks
+func NewDiscordNotifier(cfg *config.Config) *ChatNotifier {
+	return newChatNotifier(cfg, "discord", func(strategy config.StrategyConfig) string {
+		return strategy.Discord.WebhookURL
+	}, cfg.Global.Discord.WebhookURL, renderDiscordEmbeds)
+}
+
+// discordEmbed is a Discord message embed
+type discordEmbed struct {
+	Title       string              `json:"title"`
+	Description string              `json:"description,omitempty"`
+	Color       int                 `json:"color"`
+	Fields      []discordEmbedField `json:"fields,omitempty"`
+}
+
+type discordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// renderDiscordEmbeds builds a Discord message with the card as the first embed and one
+// embed per strategy
+func renderDiscordEmbeds(c card) interface{} {
+	color := map[cardStatus]int{cardRunning: 0x3498db, cardSuccess: 0x2ecc71, cardFailure: 0xe74c3c, cardWarning: 0xf1c40f}[c.Status]
+	embeds := []discordEmbed{{Title: c.Title, Color: color, Fields: discordFields(c.Facts)}}
+	if len(c.Log) > 0 {
+		log := strings.ReplaceAll(strings.Join(c.Log, "\n"), "```", "'''")
+		if len(log) > discordMaxEmbedDescription-8 {
+			log = log[len(log)-(discordMaxEmbedDescription-8):]
+		}
+		embeds[0].Description = "```\n" + log + "\n```"
+	}
+	for _, section := range c.Sections {
+		if len(embeds) == discordMaxEmbeds {
+			break
+		}
+		embeds = append(embeds, discordEmbed{Title: section.Heading, Color: color, Fields: discordFields(section.Facts)})
+	}
+
+	return map[string]interface{}{
+		"username": "easy-backup",
+		"embeds":   embeds,
+	}
+}
+
+func discordFields(facts []cardFact) []discordEmbedField {
+	var fields []discordEmbedField
+	for _, fact := range facts {
+		value := fact.Value
+		if len(value) > discordMaxFieldValue {
+			value = value[:discordMaxFieldValue-3] + "..."
+		}
+		fields = append(fields, discordEmbedField{Name: fact.Name, Value: value, Inline: len(value) < 40})
+	}
+	return fields
+}
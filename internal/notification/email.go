@@ -0,0 +1,396 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"easy-backup/internal/backup"
+	"easy-backup/internal/config"
+	"easy-backup/internal/logger"
+)
+
+// EmailNotifier sends backup results over SMTP. Failures are always sent, successes only
+// with on_success, and with a digest schedule every result is also collected for the
+// next digest.
+type EmailNotifier struct {
+	config *config.Config
+	email  config.EmailConfig
+	logger *logrus.Logger
+
+	mu          sync.Mutex
+	digest      []digestEntry // Results since the previous digest
+	digestSince time.Time
+}
+
+// digestEntry is a backup result recorded for the next digest
+type digestEntry struct {
+	strategy string
+	success  bool
+	size     int64
+	duration time.Duration
+	finished time.Time
+	err      string
+}
+
+// NewEmailNotifier creates a notifier for the configured SMTP server
+func NewEmailNotifier(cfg *config.Config) *EmailNotifier {
+	return &EmailNotifier{
+		config:      cfg,
+		email:       cfg.Global.Notifications.Email,
+		logger:      logger.GetLogger(),
+		digestSince: time.Now(),
+	}
+}
+
+// NotifyStarted does nothing, emails are only sent once a run finished
+func (en *EmailNotifier) NotifyStarted(ctx context.Context, run *Run) error {
+	return nil
+}
+
+// NotifyProgress does nothing, emails are only sent once a run finished
+func (en *EmailNotifier) NotifyProgress(ctx context.Context, run *Run, strategy, message string) error {
+	return nil
+}
+
+// NotifyOutput does nothing, the command logs of failed backups are part of the result
+func (en *EmailNotifier) NotifyOutput(ctx context.Context, run *Run, strategy, output string) error {
+	return nil
+}
+
+// NotifyRetry does nothing, failed attempts that are retried are not emailed
+func (en *EmailNotifier) NotifyRetry(ctx context.Context, run *Run, strategy, message string) error {
+	return nil
+}
+
+// NotifyDetailedError does nothing, the command logs of failed backups are part of the result
+func (en *EmailNotifier) NotifyDetailedError(ctx context.Context, run *Run, strategy string, result *backup.BackupResult) error {
+	return nil
+}
+
+// NotifyResult emails the summary of a run to the recipients of its strategies
+func (en *EmailNotifier) NotifyResult(ctx context.Context, run *Run, results []*backup.BackupResult, overallSuccess bool) error {
+	en.record(results)
+	if overallSuccess && !en.email.OnSuccess {
+		return nil
+	}
+
+	strategies := run.Strategies
+	if len(results) > 0 {
+		strategies = nil
+		for _, result := range results {
+			strategies = append(strategies, result.Strategy)
+		}
+	}
+	recipients := en.recipients(strategies)
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	subject := fmt.Sprintf("[easy-backup] Backup succeeded: %s", strings.Join(strategies, ", "))
+	if !overallSuccess {
+		subject = fmt.Sprintf("[easy-backup] Backup failed: %s", strings.Join(strategies, ", "))
+	}
+	return en.send(ctx, recipients, subject, formatBackupResult(results, overallSuccess))
+}
+
+// NotifyStale emails a warning about a strategy without a recent successful backup to its
+// recipients
+func (en *EmailNotifier) NotifyStale(ctx context.Context, run *Run, strategy string, lastSuccess time.Time) error {
+	recipients := en.recipients([]string{strategy})
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	subject := fmt.Sprintf("[easy-backup] Backup overdue: %s", strategy)
+	body := fmt.Sprintf("⚠️ Backup Overdue\n\nStrategy: %s\nLast successful backup: %s\n", strategy, formatLastSuccess(lastSuccess))
+	return en.send(ctx, recipients, subject, body)
+}
+
+// NotifyReport emails a scheduled report to the recipients of every strategy of the run
+func (en *EmailNotifier) NotifyReport(ctx context.Context, run *Run, report *Report) error {
+	recipients := en.recipients(run.Strategies)
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	subject := fmt.Sprintf("[easy-backup] %s", strings.TrimPrefix(report.Title(), "📊 "))
+	if notRun := report.NotRun(); len(notRun) > 0 {
+		subject += fmt.Sprintf(": %d did not run", len(notRun))
+	}
+	return en.send(ctx, recipients, subject, formatReport(report))
+}
+
+// DigestSchedule returns the cron schedule of the digest, empty when it is disabled
+func (en *EmailNotifier) DigestSchedule() string {
+	return en.email.Digest
+}
+
+// SendDigest emails a summary of every result since the previous digest to the global
+// recipients
+func (en *EmailNotifier) SendDigest(ctx context.Context) error {
+	en.mu.Lock()
+	entries := en.digest
+	since := en.digestSince
+	en.digest = nil
+	en.digestSince = time.Now()
+	en.mu.Unlock()
+
+	if len(en.email.To) == 0 {
+		return nil
+	}
+
+	successful := 0
+	for _, entry := range entries {
+		if entry.success {
+			successful++
+		}
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Backup digest since %s\n\n", since.UTC().Format("2006-01-02 15:04:05 UTC"))
+	if len(entries) == 0 {
+		body.WriteString("No backups ran in this period.\n")
+	}
+	for _, entry := range entries {
+		if entry.success {
+			fmt.Fprintf(&body, "✅ %s: Success at %s (%s in %v)\n", entry.strategy, entry.finished.UTC().Format("2006-01-02 15:04 UTC"), formatBytes(entry.size), entry.duration.Round(time.Second))
+		} else {
+			fmt.Fprintf(&body, "❌ %s: Failed at %s: %s\n", entry.strategy, entry.finished.UTC().Format("2006-01-02 15:04 UTC"), entry.err)
+		}
+	}
+
+	subject := fmt.Sprintf("[easy-backup] Backup digest: %d successful, %d failed", successful, len(entries)-successful)
+	if err := en.send(ctx, en.email.To, subject, body.String()); err != nil {
+		// Keep the entries for the next digest
+		en.mu.Lock()
+		en.digest = append(entries, en.digest...)
+		en.digestSince = since
+		en.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// record collects results for the next digest
+func (en *EmailNotifier) record(results []*backup.BackupResult) {
+	if en.email.Digest == "" {
+		return
+	}
+
+	en.mu.Lock()
+	defer en.mu.Unlock()
+	for _, result := range results {
+		entry := digestEntry{
+			strategy: result.Strategy,
+			success:  result.Success,
+			size:     result.Size,
+			duration: result.Duration,
+			finished: result.EndTime,
+		}
+		if entry.finished.IsZero() {
+			entry.finished = time.Now()
+		}
+		if result.Error != nil {
+			entry.err = result.Error.Error()
+		}
+		en.digest = append(en.digest, entry)
+	}
+}
+
+// recipients returns the recipients of the given strategies, falling back to the global
+// recipients for strategies without their own
+func (en *EmailNotifier) recipients(strategies []string) []string {
+	var recipients []string
+	for _, name := range strategies {
+		addresses := en.email.To
+		for _, strategy := range en.config.Strategies {
+			if strategy.Name == name && len(strategy.Email.To) > 0 {
+				addresses = strategy.Email.To
+			}
+		}
+		for _, address := range addresses {
+			if !slices.Contains(recipients, address) {
+				recipients = append(recipients, address)
+			}
+		}
+	}
+	return recipients
+}
+
+// send delivers a plain text email to the SMTP server
+func (en *EmailNotifier) send(ctx context.Context, recipients []string, subject, body string) error {
+	timeout, err := time.ParseDuration(en.email.Timeout)
+	if err != nil || timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	from, err := mail.ParseAddress(en.email.From)
+	if err != nil {
+		return fmt.Errorf("email: invalid from address: %w", err)
+	}
+	message, err := buildEmail(en.email.From, recipients, subject, body, time.Now())
+	if err != nil {
+		return fmt.Errorf("email: failed to build message: %w", err)
+	}
+
+	address := net.JoinHostPort(en.email.Host, strconv.Itoa(en.email.Port))
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	if en.email.TLS == config.EmailTLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: en.email.Host}}).DialContext(ctx, "tcp", address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return fmt.Errorf("email: failed to connect to %s: %w", address, err)
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	client, err := smtp.NewClient(conn, en.email.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("email: %w", err)
+	}
+	defer client.Close()
+
+	if en.email.TLS == config.EmailTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("email: %s does not support STARTTLS", address)
+		}
+		if err := client.StartTLS(&tls.Config{ServerName: en.email.Host}); err != nil {
+			return fmt.Errorf("email: STARTTLS failed: %w", err)
+		}
+	}
+	if en.email.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", en.email.Username, en.email.Password, en.email.Host)); err != nil {
+			return fmt.Errorf("email: authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("email: MAIL FROM failed: %w", err)
+	}
+	for _, recipient := range recipients {
+		to, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return fmt.Errorf("email: invalid recipient '%s': %w", recipient, err)
+		}
+		if err := client.Rcpt(to.Address); err != nil {
+			return fmt.Errorf("email: recipient %s rejected: %w", to.Address, err)
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("email: DATA failed: %w", err)
+	}
+	if _, err := writer.Write(message); err != nil {
+		return fmt.Errorf("email: failed to send message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("email: message rejected: %w", err)
+	}
+
+	en.logger.WithFields(logrus.Fields{
+		"subject":    subject,
+		"recipients": recipients,
+	}).Debug("Email notification sent")
+	return client.Quit()
+}
+
+// buildEmail builds a quoted-printable plain text message
+func buildEmail(from string, to []string, subject, body string, date time.Time) ([]byte, error) {
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	message.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	writer := quotedprintable.NewWriter(&message)
+	if _, err := writer.Write([]byte(body)); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return message.Bytes(), nil
+}
+
+// formatBackupResult renders the results of a run as the plain text body of an email
+func formatBackupResult(results []*backup.BackupResult, overallSuccess bool) string {
+	var body strings.Builder
+	if overallSuccess {
+		body.WriteString("✅ Database Backup Completed Successfully\n\n")
+	} else {
+		body.WriteString("❌ Database Backup Failed\n\n")
+	}
+
+	for _, result := range results {
+		if result.Success {
+			fmt.Fprintf(&body, "✅ %s: Success\n", result.Strategy)
+			fmt.Fprintf(&body, "   • Duration: %v\n", result.Duration.Round(time.Second))
+			fmt.Fprintf(&body, "   • Size: %s\n", formatBytes(result.Size))
+			if result.BackupPath != "" {
+				fmt.Fprintf(&body, "   • File: %s\n", result.BackupPath)
+			}
+			if len(result.Databases) > 0 {
+				fmt.Fprintf(&body, "   • Databases: %d\n", len(result.Databases))
+				for _, database := range result.Databases {
+					fmt.Fprintf(&body, "     ◦ %s: %s in %v\n", database.Name, formatBytes(database.Size), database.Duration.Round(time.Second))
+				}
+			}
+			for _, detail := range result.Details {
+				fmt.Fprintf(&body, "   • %s: %s\n", detail.Label, detail.Value)
+			}
+		} else {
+			fmt.Fprintf(&body, "❌ %s: Failed\n", result.Strategy)
+			if result.Error != nil {
+				fmt.Fprintf(&body, "   • Error: %s\n", result.Error.Error())
+			}
+			if result.Duration > 0 {
+				fmt.Fprintf(&body, "   • Duration before failure: %v\n", result.Duration.Round(time.Second))
+			}
+			if !result.StartTime.IsZero() {
+				fmt.Fprintf(&body, "   • Started at: %s\n", result.StartTime.UTC().Format("15:04:05 UTC"))
+			}
+			if !result.EndTime.IsZero() {
+				fmt.Fprintf(&body, "   • Failed at: %s\n", result.EndTime.UTC().Format("15:04:05 UTC"))
+			}
+
+			// The command output is only included for failed backups
+			if len(result.CommandLogs) > 0 {
+				body.WriteString("   • Command Details:\n")
+				for _, cmdLog := range result.CommandLogs {
+					// Truncate very long output to keep the email readable
+					if len(cmdLog) > 500 {
+						cmdLog = cmdLog[:497] + "..."
+					}
+					for _, line := range strings.Split(cmdLog, "\n") {
+						if strings.TrimSpace(line) != "" {
+							fmt.Fprintf(&body, "     %s\n", line)
+						}
+					}
+				}
+			}
+		}
+		body.WriteString("\n")
+	}
+
+	fmt.Fprintf(&body, "Completed at: %s", time.Now().UTC().Format("2006-01-02 15:04:05 UTC"))
+	return body.String()
+}
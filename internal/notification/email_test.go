@@ -0,0 +1,253 @@
+package notification
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"easy-backup/internal/backup"
+	"easy-backup/internal/config"
+)
+
+// sinkMessage is an email received by smtpSink
+type sinkMessage struct {
+	from string
+	to   []string
+	auth string
+	data string
+}
+
+// smtpSink is a minimal SMTP server accepting every message
+type smtpSink struct {
+	listener net.Listener
+	mu       sync.Mutex
+	messages []sinkMessage
+}
+
+func newSMTPSink(t *testing.T) *smtpSink {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	sink := &smtpSink{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go sink.serve(conn)
+		}
+	}()
+	return sink
+}
+
+func (s *smtpSink) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpSink) received() []sinkMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]sinkMessage(nil), s.messages...)
+}
+
+func (s *smtpSink) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	var message sinkMessage
+	reply("220 localhost ESMTP sink")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.TrimSpace(line)
+		switch verb := strings.ToUpper(strings.SplitN(command, " ", 2)[0]); verb {
+		case "EHLO", "HELO":
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			credentials, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(command, "AUTH PLAIN "))
+			message.auth = strings.ReplaceAll(string(credentials), "\x00", ":")
+			reply("235 Authentication successful")
+		case "MAIL":
+			message.from = strings.Trim(strings.TrimPrefix(command, "MAIL FROM:"), "<>")
+			reply("250 OK")
+		case "RCPT":
+			message.to = append(message.to, strings.Trim(strings.TrimPrefix(command, "RCPT TO:"), "<>"))
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			message.data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, message)
+			s.mu.Unlock()
+			message = sinkMessage{}
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// decodeBody returns the decoded text of a quoted-printable message
+func decodeBody(t *testing.T, data string) (*mail.Message, string) {
+	message, err := mail.ReadMessage(strings.NewReader(data))
+	require.NoError(t, err)
+	body, err := io.ReadAll(quotedprintable.NewReader(message.Body))
+	require.NoError(t, err)
+	return message, string(body)
+}
+
+func newEmailTestConfig(sink *smtpSink) *config.Config {
+	return &config.Config{
+		Global: config.GlobalConfig{Notifications: config.NotificationsConfig{Email: config.EmailConfig{
+			Host:    "127.0.0.1",
+			Port:    sink.port(),
+			From:    "Easy Backup <backups@example.com>",
+			To:      []string{"dba@example.com"},
+			TLS:     config.EmailTLSNone,
+			Timeout: "5s",
+		}}},
+		Strategies: []config.StrategyConfig{
+			{Name: "postgres-main", Email: config.StrategyEmailConfig{To: []string{"pg-team@example.com", "dba@example.com"}}},
+			{Name: "mysql-main"},
+		},
+	}
+}
+
+func TestEmailNotifier_NotifyResult(t *testing.T) {
+	sink := newSMTPSink(t)
+	cfg := newEmailTestConfig(sink)
+	cfg.Global.Notifications.Email.Username = "backups"
+	cfg.Global.Notifications.Email.Password = "smtp-password"
+	notifier := NewEmailNotifier(cfg)
+	ctx := context.Background()
+
+	success := &backup.BackupResult{Strategy: "mysql-main", Success: true, Size: 1024, Duration: time.Minute}
+	require.NoError(t, notifier.NotifyResult(ctx, NewRun([]string{"mysql-main"}, config.SlackConfig{}), []*backup.BackupResult{success}, true))
+	assert.Empty(t, sink.received(), "successes are only sent with on_success")
+
+	failure := &backup.BackupResult{
+		Strategy:    "postgres-main",
+		Error:       errors.New("pg_dump exited with status 1"),
+		CommandLogs: []string{"pg_dump: error: connection refused"},
+	}
+	run := NewRun([]string{"postgres-main", "mysql-main"}, config.SlackConfig{})
+	require.NoError(t, notifier.NotifyResult(ctx, run, []*backup.BackupResult{failure, success}, false))
+
+	messages := sink.received()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "backups@example.com", messages[0].from)
+	assert.Equal(t, []string{"pg-team@example.com", "dba@example.com"}, messages[0].to)
+	assert.Equal(t, ":backups:smtp-password", messages[0].auth)
+
+	message, body := decodeBody(t, messages[0].data)
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "[easy-backup] Backup failed: postgres-main, mysql-main", subject)
+	assert.Contains(t, body, "❌ Database Backup Failed")
+	assert.Contains(t, body, "Error: pg_dump exited with status 1")
+	assert.Contains(t, body, "pg_dump: error: connection refused")
+	assert.NotContains(t, body, "**")
+	assert.NotContains(t, body, "`")
+}
+
+func TestEmailNotifier_OnSuccess(t *testing.T) {
+	sink := newSMTPSink(t)
+	cfg := newEmailTestConfig(sink)
+	cfg.Global.Notifications.Email.OnSuccess = true
+	notifier := NewEmailNotifier(cfg)
+
+	result := &backup.BackupResult{Strategy: "mysql-main", Success: true, Size: 1024, Duration: time.Minute}
+	require.NoError(t, notifier.NotifyResult(context.Background(), NewRun([]string{"mysql-main"}, config.SlackConfig{}), []*backup.BackupResult{result}, true))
+
+	messages := sink.received()
+	require.Len(t, messages, 1)
+	assert.Equal(t, []string{"dba@example.com"}, messages[0].to)
+	_, body := decodeBody(t, messages[0].data)
+	assert.Contains(t, body, "✅ Database Backup Completed Successfully")
+	assert.Contains(t, body, "Size: 1.0 KB")
+}
+
+func TestEmailNotifier_Digest(t *testing.T) {
+	sink := newSMTPSink(t)
+	cfg := newEmailTestConfig(sink)
+	cfg.Global.Notifications.Email.Digest = "0 8 * * *"
+	notifier := NewEmailNotifier(cfg)
+	ctx := context.Background()
+
+	router := NewRouter(cfg)
+	router.Add(config.NotifierSlack, &SlackService{})
+	router.Add(config.NotifierEmail, notifier)
+	assert.Equal(t, []Digester{notifier}, Digesters(router))
+
+	results := []*backup.BackupResult{
+		{Strategy: "mysql-main", Success: true, Size: 2048, Duration: 2 * time.Minute, EndTime: time.Now()},
+		{Strategy: "postgres-main", Error: errors.New("connection refused"), EndTime: time.Now()},
+	}
+	run := NewRun([]string{"mysql-main", "postgres-main"}, config.SlackConfig{})
+	for _, result := range results {
+		require.NoError(t, notifier.NotifyResult(ctx, run, []*backup.BackupResult{result}, result.Success))
+	}
+	require.Len(t, sink.received(), 1, "the failure is sent immediately")
+
+	require.NoError(t, notifier.SendDigest(ctx))
+	messages := sink.received()
+	require.Len(t, messages, 2)
+	assert.Equal(t, []string{"dba@example.com"}, messages[1].to)
+	message, body := decodeBody(t, messages[1].data)
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "[easy-backup] Backup digest: 1 successful, 1 failed", subject)
+	assert.Contains(t, body, "✅ mysql-main: Success")
+	assert.Contains(t, body, "❌ postgres-main: Failed")
+	assert.Contains(t, body, "connection refused")
+
+	// The next digest starts empty
+	require.NoError(t, notifier.SendDigest(ctx))
+	_, body = decodeBody(t, sink.received()[2].data)
+	assert.Contains(t, body, "No backups ran in this period.")
+}
+
+func TestEmailNotifier_ConnectionFailure(t *testing.T) {
+	sink := newSMTPSink(t)
+	cfg := newEmailTestConfig(sink)
+	sink.listener.Close()
+	notifier := NewEmailNotifier(cfg)
+
+	result := &backup.BackupResult{Strategy: "mysql-main", Error: errors.New("failed")}
+	err := notifier.NotifyResult(context.Background(), NewRun([]string{"mysql-main"}, config.SlackConfig{}), []*backup.BackupResult{result}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "email: failed to connect to 127.0.0.1")
+}
@@ -0,0 +1,130 @@
+package notification
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"easy-backup/internal/backup"
+	"easy-backup/internal/config"
+)
+
+// Notifier sends the notifications of backup runs to a channel. Notifiers skip runs they
+// could not announce, e.g. Slack when the start message could not be posted.
+type Notifier interface {
+	NotifyStarted(ctx context.Context, run *Run) error
+	NotifyProgress(ctx context.Context, run *Run, strategy, message string) error
+	NotifyOutput(ctx context.Context, run *Run, strategy, output string) error
+	NotifyRetry(ctx context.Context, run *Run, strategy, message string) error
+	NotifyResult(ctx context.Context, run *Run, results []*backup.BackupResult, overallSuccess bool) error
+	NotifyDetailedError(ctx context.Context, run *Run, strategy string, result *backup.BackupResult) error
+	NotifyStale(ctx context.Context, run *Run, strategy string, lastSuccess time.Time) error
+	NotifyReport(ctx context.Context, run *Run, report *Report) error
+}
+
+// Digester is implemented by notifiers sending periodic digests of the results they received
+type Digester interface {
+	DigestSchedule() string // Cron schedule, empty when digests are disabled
+	SendDigest(ctx context.Context) error
+}
+
+// Run is a backup run of one or more strategies, shared by the notifications sent for it
+type Run struct {
+	ID         string
+	Strategies []string
+	Slack      config.SlackConfig // Slack channel of the run
+	StartedAt  time.Time
+	Trigger    *Trigger // Set for runs started on demand from Slack
+
+	mention      string        // Slack mention prepended to the messages of a route
+	slackThreads *slackThreads // Slack threads of the run, shared by its routed copies
+}
+
+// Trigger describes who started a run on demand from Slack
+type Trigger struct {
+	UserID      string      // Slack user who started the run
+	Command     string      // Command or button that started the run, e.g. "/backup run postgres-prod"
+	ResponseURL string      // Response URL of a slash command, receives a link to the thread of the run
+	Thread      *ThreadInfo // Thread of a clicked button, receives a link to the thread of the run
+
+	linked sync.Once
+}
+
+// slackThreads tracks the Slack start messages of a run by channel
+type slackThreads struct {
+	mu      sync.Mutex
+	threads map[string]*ThreadInfo // Nil when the start message could not be posted
+}
+
+// NewRun creates a run of the given strategies with a random ID
+func NewRun(strategies []string, slackConfig config.SlackConfig) *Run {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return &Run{
+		ID:         hex.EncodeToString(id),
+		Strategies: strategies,
+		Slack:      slackConfig,
+		StartedAt:  time.Now(),
+
+		slackThreads: &slackThreads{threads: map[string]*ThreadInfo{}},
+	}
+}
+
+// slackThread returns the thread of the start message in the Slack channel of the run.
+// announced is false when no start message was sent to the channel.
+func (r *Run) slackThread() (thread *ThreadInfo, announced bool) {
+	r.slackThreads.mu.Lock()
+	defer r.slackThreads.mu.Unlock()
+	thread, announced = r.slackThreads.threads[r.Slack.ChannelID]
+	return thread, announced
+}
+
+func (r *Run) setSlackThread(thread *ThreadInfo) {
+	r.slackThreads.mu.Lock()
+	defer r.slackThreads.mu.Unlock()
+	r.slackThreads.threads[r.Slack.ChannelID] = thread
+}
+
+// incremental reports whether the run is an incremental backup of a strategy
+func (r *Run) incremental() bool {
+	return len(r.Strategies) == 1 && strings.HasSuffix(r.Strategies[0], IncrementalSuffix)
+}
+
+// NewNotifier creates the notifier of the service: Slack followed by the configured webhooks,
+// Teams, Discord, email and incident alerting, behind the configured routes
+func NewNotifier(cfg *config.Config, slackService *SlackService) *Router {
+	router := NewRouter(cfg)
+	router.Add(config.NotifierSlack, slackService)
+	for _, webhook := range cfg.Global.Notifications.Webhooks {
+		router.Add(config.NotifierWebhook+":"+webhook.Name, NewWebhookNotifier(webhook))
+	}
+	if cfg.Global.Teams.WebhookURL != "" || anyStrategy(cfg, func(strategy config.StrategyConfig) bool { return strategy.Teams.WebhookURL != "" }) {
+		router.Add(config.NotifierTeams, NewTeamsNotifier(cfg))
+	}
+	if cfg.Global.Discord.WebhookURL != "" || anyStrategy(cfg, func(strategy config.StrategyConfig) bool { return strategy.Discord.WebhookURL != "" }) {
+		router.Add(config.NotifierDiscord, NewDiscordNotifier(cfg))
+	}
+	if cfg.Global.Notifications.Email.Enabled() {
+		router.Add(config.NotifierEmail, NewEmailNotifier(cfg))
+	}
+	if cfg.Global.Notifications.Alerting.Enabled() || anyStrategy(cfg, func(strategy config.StrategyConfig) bool { return strategy.Alerting.RoutingKey != "" }) {
+		router.Add(config.NotifierAlerting, NewAlertNotifier(cfg))
+	}
+	return router
+}
+
+// Digesters returns the notifiers with a digest schedule
+func Digesters(notifier Notifier) []Digester {
+	var digesters []Digester
+	if router, ok := notifier.(*Router); ok {
+		for _, route := range router.notifiers {
+			digesters = append(digesters, Digesters(route.notifier)...)
+		}
+	} else if digester, ok := notifier.(Digester); ok && digester.DigestSchedule() != "" {
+		digesters = append(digesters, digester)
+	}
+	return digesters
+}
@@ -0,0 +1,156 @@
+package notification
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Report summarizes the backups of every strategy over a period, e.g. a day or a week
+type Report struct {
+	Since      time.Time
+	Until      time.Time
+	Strategies []StrategyReport
+}
+
+// StrategyReport summarizes the backups of one strategy in a report
+type StrategyReport struct {
+	Strategy    string
+	LastSuccess time.Time // Zero when unknown
+	Runs        int       // Backups that finished in the period, since the start of the service
+	Successful  int
+	Uploads     int // Backup objects stored in S3 during the period
+
+	StorageListed bool  // False when the S3 objects could not be listed
+	StoredBytes   int64 // Size of every object of the strategy stored in S3
+	StoredObjects int
+
+	FirstSize  int64 // Size of the first successful backup of the period
+	LatestSize int64 // Size of the latest successful backup of the period
+}
+
+// Title names the report after its period
+func (r *Report) Title() string {
+	switch r.Until.Sub(r.Since) {
+	case 24 * time.Hour:
+		return "📊 Daily Backup Report"
+	case 7 * 24 * time.Hour:
+		return "📊 Weekly Backup Report"
+	default:
+		return "📊 Backup Report"
+	}
+}
+
+// NotRun returns the strategies that did not back up during the period
+func (r *Report) NotRun() []string {
+	var strategies []string
+	for _, strategy := range r.Strategies {
+		if strategy.NotRun() {
+			strategies = append(strategies, strategy.Strategy)
+		}
+	}
+	return strategies
+}
+
+// Healthy reports whether every strategy ran and succeeded during the period
+func (r *Report) Healthy() bool {
+	for _, strategy := range r.Strategies {
+		if strategy.NotRun() || strategy.Successful < strategy.Runs {
+			return false
+		}
+	}
+	return true
+}
+
+// NotRun reports whether the strategy neither finished a backup nor stored one in the period
+func (sr StrategyReport) NotRun() bool {
+	return sr.Runs == 0 && sr.Uploads == 0
+}
+
+// SuccessRate returns the share of successful backups, false when no backup finished
+func (sr StrategyReport) SuccessRate() (float64, bool) {
+	if sr.Runs == 0 {
+		return 0, false
+	}
+	return float64(sr.Successful) / float64(sr.Runs), true
+}
+
+// Growth returns the relative change in size from the first to the latest successful
+// backup of the period, false with fewer than two successful backups
+func (sr StrategyReport) Growth() (float64, bool) {
+	if sr.Successful < 2 || sr.FirstSize <= 0 {
+		return 0, false
+	}
+	return float64(sr.LatestSize-sr.FirstSize) / float64(sr.FirstSize), true
+}
+
+// reportFacts lists the facts of a strategy in a report
+func reportFacts(sr StrategyReport) []cardFact {
+	facts := []cardFact{{Name: "Last success", Value: formatLastSuccess(sr.LastSuccess)}}
+
+	successRate := "no backups finished"
+	if rate, ok := sr.SuccessRate(); ok {
+		successRate = fmt.Sprintf("%.0f%% (%d/%d)", rate*100, sr.Successful, sr.Runs)
+	}
+	facts = append(facts, cardFact{Name: "Success rate", Value: successRate})
+
+	stored := "unknown, S3 could not be listed"
+	if sr.StorageListed {
+		stored = fmt.Sprintf("%s in %d objects", formatBytes(sr.StoredBytes), sr.StoredObjects)
+	}
+	facts = append(facts, cardFact{Name: "Stored", Value: stored})
+
+	growth := "not enough backups"
+	if change, ok := sr.Growth(); ok {
+		growth = fmt.Sprintf("%+.1f%% (%s → %s)", change*100, formatBytes(sr.FirstSize), formatBytes(sr.LatestSize))
+	}
+	return append(facts, cardFact{Name: "Growth", Value: growth})
+}
+
+// reportHeading is the heading of a strategy in a report
+func reportHeading(sr StrategyReport) string {
+	switch {
+	case sr.NotRun():
+		return fmt.Sprintf("⚠️ %s: Did not run", sr.Strategy)
+	case sr.Successful < sr.Runs:
+		return fmt.Sprintf("❌ %s: %d failed", sr.Strategy, sr.Runs-sr.Successful)
+	default:
+		return fmt.Sprintf("✅ %s", sr.Strategy)
+	}
+}
+
+// reportPeriod describes the period of a report
+func reportPeriod(report *Report) string {
+	return fmt.Sprintf("%s to %s", report.Since.UTC().Format("2006-01-02 15:04 UTC"), report.Until.UTC().Format("2006-01-02 15:04 UTC"))
+}
+
+// reportCard renders a report as a card for Teams and Discord
+func reportCard(report *Report) card {
+	reportCard := card{Title: report.Title(), Status: cardSuccess, Facts: []cardFact{{Name: "Period", Value: reportPeriod(report)}}}
+	if !report.Healthy() {
+		reportCard.Status = cardWarning
+	}
+	if notRun := report.NotRun(); len(notRun) > 0 {
+		reportCard.Facts = append(reportCard.Facts, cardFact{Name: "Did not run", Value: strings.Join(notRun, ", ")})
+	}
+	for _, strategy := range report.Strategies {
+		reportCard.Sections = append(reportCard.Sections, cardSection{Heading: reportHeading(strategy), Facts: reportFacts(strategy)})
+	}
+	return reportCard
+}
+
+// formatReport renders a report as plain text, e.g. for emails
+func formatReport(report *Report) string {
+	var body strings.Builder
+	fmt.Fprintf(&body, "%s\n\nPeriod: %s\n", report.Title(), reportPeriod(report))
+	if notRun := report.NotRun(); len(notRun) > 0 {
+		fmt.Fprintf(&body, "Did not run: %s\n", strings.Join(notRun, ", "))
+	}
+	for _, strategy := range report.Strategies {
+		fmt.Fprintf(&body, "\n%s\n", reportHeading(strategy))
+		for _, fact := range reportFacts(strategy) {
+			fmt.Fprintf(&body, "  %s: %s\n", fact.Name, fact.Value)
+		}
+	}
+	return body.String()
+}
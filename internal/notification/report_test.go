@@ -0,0 +1,103 @@
+package notification
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testReport() *Report {
+	until := time.Date(2024, 3, 11, 8, 0, 0, 0, time.UTC)
+	return &Report{
+		Since: until.Add(-7 * 24 * time.Hour),
+		Until: until,
+		Strategies: []StrategyReport{
+			{
+				Strategy:      "postgres-main",
+				LastSuccess:   until.Add(-6 * time.Hour),
+				Runs:          7,
+				Successful:    6,
+				Uploads:       6,
+				StorageListed: true,
+				StoredBytes:   30 << 20,
+				StoredObjects: 12,
+				FirstSize:     4 << 20,
+				LatestSize:    5 << 20,
+			},
+			{Strategy: "redis-cache", StorageListed: true},
+		},
+	}
+}
+
+func TestReport(t *testing.T) {
+	report := testReport()
+	assert.Equal(t, "📊 Weekly Backup Report", report.Title())
+	assert.Equal(t, []string{"redis-cache"}, report.NotRun())
+	assert.False(t, report.Healthy())
+
+	rate, ok := report.Strategies[0].SuccessRate()
+	require.True(t, ok)
+	assert.InDelta(t, 6.0/7, rate, 0.001)
+	growth, ok := report.Strategies[0].Growth()
+	require.True(t, ok)
+	assert.InDelta(t, 0.25, growth, 0.001)
+
+	_, ok = report.Strategies[1].SuccessRate()
+	assert.False(t, ok, "no backups finished")
+	_, ok = report.Strategies[1].Growth()
+	assert.False(t, ok)
+
+	report.Since = report.Until.Add(-24 * time.Hour)
+	assert.Equal(t, "📊 Daily Backup Report", report.Title())
+}
+
+func TestFormatReport(t *testing.T) {
+	text := formatReport(testReport())
+
+	assert.Contains(t, text, "Period: 2024-03-04 08:00 UTC to 2024-03-11 08:00 UTC")
+	assert.Contains(t, text, "Did not run: redis-cache")
+	assert.Contains(t, text, "❌ postgres-main: 1 failed")
+	assert.Contains(t, text, "Last success: 2024-03-11 02:00:00 UTC")
+	assert.Contains(t, text, "Success rate: 86% (6/7)")
+	assert.Contains(t, text, "Stored: 30.0 MB in 12 objects")
+	assert.Contains(t, text, "Growth: +25.0% (4.0 MB → 5.0 MB)")
+	assert.Contains(t, text, "⚠️ redis-cache: Did not run")
+	assert.Contains(t, text, "Success rate: no backups finished")
+}
+
+func TestReportMessage(t *testing.T) {
+	report := testReport()
+	for i := 0; i < 60; i++ {
+		report.Strategies = append(report.Strategies, StrategyReport{Strategy: "db", Runs: 1, Successful: 1})
+	}
+
+	message := reportMessage(report)
+	assert.Equal(t, slackColorWarning, message.Color)
+	assert.LessOrEqual(t, len(message.Blocks)+1, slackMaxBlocks, "room for a mention")
+	last, ok := message.Blocks[len(message.Blocks)-1].(*slack.ContextBlock)
+	require.True(t, ok)
+	assert.Contains(t, last.ContextElements.Elements[0].(*slack.TextBlockObject).Text, "more strategies are not shown")
+}
+
+func TestNewWebhookReport(t *testing.T) {
+	report := testReport()
+	report.Strategies[1].StorageListed = false
+
+	body, err := json.Marshal(newWebhookReport(report))
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &decoded))
+	assert.Equal(t, []interface{}{"redis-cache"}, decoded["not_run"])
+	strategies := decoded["strategies"].([]interface{})
+	main := strategies[0].(map[string]interface{})
+	assert.Equal(t, "2024-03-11T02:00:00Z", main["last_success"])
+	assert.Equal(t, float64(30<<20), main["stored_bytes"])
+	assert.InDelta(t, 0.25, main["growth"], 0.001)
+	assert.NotContains(t, strategies[1], "stored_bytes", "S3 could not be listed")
+	assert.NotContains(t, strategies[1], "success_rate")
+}
@@ -0,0 +1,213 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"easy-backup/internal/backup"
+	"easy-backup/internal/config"
+)
+
+// IncrementalSuffix marks the runs of incremental backups, e.g. "mysql-main (incremental)"
+const IncrementalSuffix = " (incremental)"
+
+// Router sends every event to the notifiers selected by the notification routes of the
+// strategy of its run, falling back to the global routes. Without routes every notifier
+// receives every event.
+type Router struct {
+	config    *config.Config
+	notifiers []routedNotifier
+}
+
+// routedNotifier is a notifier with the name routes select it by, e.g. slack or
+// webhook:<name>
+type routedNotifier struct {
+	name     string
+	notifier Notifier
+}
+
+// NewRouter creates a router without notifiers
+func NewRouter(cfg *config.Config) *Router {
+	return &Router{config: cfg}
+}
+
+// Add registers a notifier under its route name
+func (r *Router) Add(name string, notifier Notifier) {
+	r.notifiers = append(r.notifiers, routedNotifier{name: name, notifier: notifier})
+}
+
+// NotifyStarted routes the started event
+func (r *Router) NotifyStarted(ctx context.Context, run *Run) error {
+	return r.dispatch(run, config.EventStarted, func(notifier Notifier, run *Run) error {
+		return notifier.NotifyStarted(ctx, run)
+	})
+}
+
+// NotifyProgress routes a progress update
+func (r *Router) NotifyProgress(ctx context.Context, run *Run, strategy, message string) error {
+	return r.dispatch(run, config.EventProgress, func(notifier Notifier, run *Run) error {
+		return notifier.NotifyProgress(ctx, run, strategy, message)
+	})
+}
+
+// NotifyOutput routes dump tool output as progress
+func (r *Router) NotifyOutput(ctx context.Context, run *Run, strategy, output string) error {
+	return r.dispatch(run, config.EventProgress, func(notifier Notifier, run *Run) error {
+		return notifier.NotifyOutput(ctx, run, strategy, output)
+	})
+}
+
+// NotifyRetry routes a failed attempt or retry
+func (r *Router) NotifyRetry(ctx context.Context, run *Run, strategy, message string) error {
+	return r.dispatch(run, config.EventRetry, func(notifier Notifier, run *Run) error {
+		return notifier.NotifyRetry(ctx, run, strategy, message)
+	})
+}
+
+// NotifyResult routes the successful results as a success event and the failed results as a
+// failure event. Notifiers routed for both events to the same channel get every result in a
+// single message.
+func (r *Router) NotifyResult(ctx context.Context, run *Run, results []*backup.BackupResult, overallSuccess bool) error {
+	var succeeded, failed []*backup.BackupResult
+	for _, result := range results {
+		if result.Success {
+			succeeded = append(succeeded, result)
+		} else {
+			failed = append(failed, result)
+		}
+	}
+
+	var events []string
+	if len(succeeded) > 0 {
+		events = append(events, config.EventSuccess)
+	}
+	if len(failed) > 0 {
+		events = append(events, config.EventFailure)
+	}
+	if len(events) == 0 {
+		events = []string{config.EventSuccess}
+		if !overallSuccess {
+			events = []string{config.EventFailure}
+		}
+	}
+
+	return r.dispatchEvents(run, events, func(notifier Notifier, run *Run, matched []string) error {
+		switch {
+		case len(matched) == len(events):
+			return notifier.NotifyResult(ctx, run, results, overallSuccess)
+		case matched[0] == config.EventSuccess:
+			return notifier.NotifyResult(ctx, run, succeeded, true)
+		default:
+			return notifier.NotifyResult(ctx, run, failed, false)
+		}
+	})
+}
+
+// NotifyDetailedError routes the details of a failed backup as a failure event
+func (r *Router) NotifyDetailedError(ctx context.Context, run *Run, strategy string, result *backup.BackupResult) error {
+	return r.dispatch(run, config.EventFailure, func(notifier Notifier, run *Run) error {
+		return notifier.NotifyDetailedError(ctx, run, strategy, result)
+	})
+}
+
+// NotifyStale routes the warning about a strategy without a recent successful backup
+func (r *Router) NotifyStale(ctx context.Context, run *Run, strategy string, lastSuccess time.Time) error {
+	return r.dispatch(run, config.EventStale, func(notifier Notifier, run *Run) error {
+		return notifier.NotifyStale(ctx, run, strategy, lastSuccess)
+	})
+}
+
+// NotifyReport routes a scheduled report
+func (r *Router) NotifyReport(ctx context.Context, run *Run, report *Report) error {
+	return r.dispatch(run, config.EventReport, func(notifier Notifier, run *Run) error {
+		return notifier.NotifyReport(ctx, run, report)
+	})
+}
+
+// dispatch calls every notifier selected for the event, once per Slack channel and mention
+// of its routes, so that a failing channel does not silence the others
+func (r *Router) dispatch(run *Run, event string, notify func(Notifier, *Run) error) error {
+	return r.dispatchEvents(run, []string{event}, func(notifier Notifier, run *Run, _ []string) error {
+		return notify(notifier, run)
+	})
+}
+
+// dispatchEvents calls every notifier selected for any of the events once per Slack channel
+// and mention of its routes, with the events routed to that channel
+func (r *Router) dispatchEvents(run *Run, events []string, notify func(Notifier, *Run, []string) error) error {
+	routes := r.routes(run)
+	var errs []error
+	for _, target := range r.notifiers {
+		if len(routes) == 0 {
+			if err := notify(target.notifier, run, events); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		var keys []string
+		routedRuns := map[string]*Run{}
+		matched := map[string][]string{}
+		for _, route := range routes {
+			for _, event := range events {
+				if !routeMatches(route, target.name, event) {
+					continue
+				}
+				routed := run.withRoute(route)
+				key := routed.Slack.ChannelID + "\x00" + routed.mention
+				if _, ok := routedRuns[key]; !ok {
+					keys = append(keys, key)
+					routedRuns[key] = routed
+				}
+				if !slices.Contains(matched[key], event) {
+					matched[key] = append(matched[key], event)
+				}
+			}
+		}
+		for _, key := range keys {
+			if err := notify(target.notifier, routedRuns[key], matched[key]); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// routes returns the routes of the strategy of a single strategy run, or the global routes
+func (r *Router) routes(run *Run) []config.NotificationRoute {
+	if len(run.Strategies) == 1 {
+		name := strings.TrimSuffix(run.Strategies[0], IncrementalSuffix)
+		for _, strategy := range r.config.Strategies {
+			if strategy.Name == name && len(strategy.NotificationRoutes) > 0 {
+				return strategy.NotificationRoutes
+			}
+		}
+	}
+	return r.config.Global.Notifications.Routes
+}
+
+// routeMatches reports whether a route sends the event to the named notifier
+func routeMatches(route config.NotificationRoute, name, event string) bool {
+	if route.Notifier != name && (route.Notifier != config.NotifierWebhook || !strings.HasPrefix(name, config.NotifierWebhook+":")) {
+		return false
+	}
+	if slices.Contains(route.Events, event) {
+		return true
+	}
+	// Incidents are resolved by the next successful backup
+	return name == config.NotifierAlerting && event == config.EventSuccess &&
+		(slices.Contains(route.Events, config.EventFailure) || slices.Contains(route.Events, config.EventStale))
+}
+
+// withRoute returns a copy of the run with the Slack channel and mention of a route
+func (r *Run) withRoute(route config.NotificationRoute) *Run {
+	routed := *r
+	if route.ChannelID != "" {
+		routed.Slack.ChannelID = route.ChannelID
+	}
+	routed.mention = route.Mention
+	return &routed
+}
@@ -0,0 +1,223 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"easy-backup/internal/backup"
+	"easy-backup/internal/config"
+)
+
+// recordingNotifier records the events it receives as "<event> <channel> <mention>", and the
+// strategies of the results it receives
+type recordingNotifier struct {
+	events  []string
+	results [][]string
+	err     error
+}
+
+func (rn *recordingNotifier) record(event string, run *Run) error {
+	rn.events = append(rn.events, fmt.Sprintf("%s %s %s", event, run.Slack.ChannelID, run.mention))
+	return rn.err
+}
+
+func (rn *recordingNotifier) NotifyStarted(ctx context.Context, run *Run) error {
+	return rn.record("started", run)
+}
+
+func (rn *recordingNotifier) NotifyProgress(ctx context.Context, run *Run, strategy, message string) error {
+	return rn.record("progress", run)
+}
+
+func (rn *recordingNotifier) NotifyOutput(ctx context.Context, run *Run, strategy, output string) error {
+	return rn.record("output", run)
+}
+
+func (rn *recordingNotifier) NotifyRetry(ctx context.Context, run *Run, strategy, message string) error {
+	return rn.record("retry", run)
+}
+
+func (rn *recordingNotifier) NotifyResult(ctx context.Context, run *Run, results []*backup.BackupResult, overallSuccess bool) error {
+	var strategies []string
+	for _, result := range results {
+		strategies = append(strategies, result.Strategy)
+	}
+	rn.results = append(rn.results, strategies)
+	return rn.record(fmt.Sprintf("result:%t", overallSuccess), run)
+}
+
+func (rn *recordingNotifier) NotifyDetailedError(ctx context.Context, run *Run, strategy string, result *backup.BackupResult) error {
+	return rn.record("error", run)
+}
+
+func (rn *recordingNotifier) NotifyStale(ctx context.Context, run *Run, strategy string, lastSuccess time.Time) error {
+	return rn.record("stale", run)
+}
+
+func (rn *recordingNotifier) NotifyReport(ctx context.Context, run *Run, report *Report) error {
+	return rn.record("report", run)
+}
+
+// notifyAll sends every kind of event of a run
+func notifyAll(t *testing.T, notifier Notifier, run *Run) {
+	ctx := context.Background()
+	strategy := run.Strategies[0]
+	require.NoError(t, notifier.NotifyStarted(ctx, run))
+	require.NoError(t, notifier.NotifyProgress(ctx, run, strategy, "Uploading to S3..."))
+	require.NoError(t, notifier.NotifyOutput(ctx, run, strategy, "warning: skipping table"))
+	require.NoError(t, notifier.NotifyRetry(ctx, run, strategy, "Retrying backup (attempt 2/3)"))
+	require.NoError(t, notifier.NotifyResult(ctx, run, nil, true))
+	require.NoError(t, notifier.NotifyResult(ctx, run, nil, false))
+	require.NoError(t, notifier.NotifyDetailedError(ctx, run, strategy, nil))
+	require.NoError(t, notifier.NotifyStale(ctx, run, strategy, time.Time{}))
+}
+
+func TestRouter_WithoutRoutes(t *testing.T) {
+	slack, email := &recordingNotifier{}, &recordingNotifier{}
+	router := NewRouter(&config.Config{})
+	router.Add(config.NotifierSlack, slack)
+	router.Add(config.NotifierEmail, email)
+
+	notifyAll(t, router, NewRun([]string{"postgres-main"}, config.SlackConfig{ChannelID: "C-BACKUPS"}))
+
+	expected := []string{
+		"started C-BACKUPS ", "progress C-BACKUPS ", "output C-BACKUPS ", "retry C-BACKUPS ",
+		"result:true C-BACKUPS ", "result:false C-BACKUPS ", "error C-BACKUPS ", "stale C-BACKUPS ",
+	}
+	assert.Equal(t, expected, slack.events)
+	assert.Equal(t, expected, email.events)
+}
+
+func TestRouter_Routes(t *testing.T) {
+	cfg := &config.Config{
+		Global: config.GlobalConfig{Notifications: config.NotificationsConfig{Routes: []config.NotificationRoute{
+			{Events: []string{config.EventSuccess}, Notifier: config.NotifierSlack, ChannelID: "C-QUIET"},
+			{Events: []string{config.EventFailure, config.EventStale}, Notifier: config.NotifierSlack, ChannelID: "C-CRITICAL", Mention: "@here"},
+			{Events: []string{config.EventFailure, config.EventStale}, Notifier: config.NotifierSlack, ChannelID: "C-CRITICAL", Mention: "@here"},
+			{Events: []string{config.EventFailure}, Notifier: config.NotifierAlerting},
+			{Events: []string{config.EventStarted, config.EventFailure}, Notifier: config.NotifierWebhook},
+		}}},
+		Strategies: []config.StrategyConfig{
+			{Name: "postgres-main"},
+			{Name: "mysql-main", NotificationRoutes: []config.NotificationRoute{
+				{Events: []string{config.EventStarted, config.EventProgress, config.EventRetry, config.EventSuccess, config.EventFailure}, Notifier: config.NotifierSlack},
+			}},
+		},
+	}
+	slack, alerting, incidents, audit := &recordingNotifier{}, &recordingNotifier{}, &recordingNotifier{}, &recordingNotifier{}
+	router := NewRouter(cfg)
+	router.Add(config.NotifierSlack, slack)
+	router.Add(config.NotifierAlerting, alerting)
+	router.Add("webhook:incidents", incidents)
+	router.Add("webhook:audit", audit)
+
+	t.Run("global routes", func(t *testing.T) {
+		notifyAll(t, router, NewRun([]string{"postgres-main"}, config.SlackConfig{ChannelID: "C-BACKUPS"}))
+
+		assert.Equal(t, []string{
+			"result:true C-QUIET ",
+			"result:false C-CRITICAL @here",
+			"error C-CRITICAL @here",
+			"stale C-CRITICAL @here",
+		}, slack.events)
+		assert.Equal(t, []string{"result:true C-BACKUPS ", "result:false C-BACKUPS ", "error C-BACKUPS "}, alerting.events, "alerting receives successes to resolve incidents")
+		assert.Equal(t, []string{"started C-BACKUPS ", "result:false C-BACKUPS ", "error C-BACKUPS "}, incidents.events)
+		assert.Equal(t, incidents.events, audit.events)
+	})
+
+	t.Run("strategy routes", func(t *testing.T) {
+		slack.events, alerting.events, incidents.events = nil, nil, nil
+		notifyAll(t, router, NewRun([]string{"mysql-main" + IncrementalSuffix}, config.SlackConfig{ChannelID: "C-MYSQL"}))
+
+		assert.Equal(t, []string{
+			"started C-MYSQL ", "progress C-MYSQL ", "output C-MYSQL ", "retry C-MYSQL ",
+			"result:true C-MYSQL ", "result:false C-MYSQL ", "error C-MYSQL ",
+		}, slack.events)
+		assert.Empty(t, alerting.events)
+		assert.Empty(t, incidents.events)
+	})
+}
+
+func TestRouter_MixedResults(t *testing.T) {
+	cfg := &config.Config{
+		Global: config.GlobalConfig{Notifications: config.NotificationsConfig{Routes: []config.NotificationRoute{
+			{Events: []string{config.EventSuccess}, Notifier: config.NotifierSlack, ChannelID: "C-QUIET"},
+			{Events: []string{config.EventFailure}, Notifier: config.NotifierSlack, ChannelID: "C-CRITICAL", Mention: "@here"},
+			{Events: []string{config.EventFailure}, Notifier: config.NotifierAlerting},
+			{Events: []string{config.EventSuccess, config.EventFailure}, Notifier: config.NotifierEmail},
+		}}},
+	}
+	slack, alerting, email := &recordingNotifier{}, &recordingNotifier{}, &recordingNotifier{}
+	router := NewRouter(cfg)
+	router.Add(config.NotifierSlack, slack)
+	router.Add(config.NotifierAlerting, alerting)
+	router.Add(config.NotifierEmail, email)
+
+	results := []*backup.BackupResult{
+		{Strategy: "postgres-main", Success: true},
+		{Strategy: "mysql-main", Error: errors.New("access denied")},
+	}
+	run := NewRun([]string{"postgres-main", "mysql-main"}, config.SlackConfig{ChannelID: "C-BACKUPS"})
+	require.NoError(t, router.NotifyResult(context.Background(), run, results, false))
+
+	assert.Equal(t, []string{"result:true C-QUIET ", "result:false C-CRITICAL @here"}, slack.events)
+	assert.Equal(t, [][]string{{"postgres-main"}, {"mysql-main"}}, slack.results)
+	assert.Equal(t, []string{"result:false C-BACKUPS "}, alerting.events, "alerting gets every result in one call")
+	assert.Equal(t, [][]string{{"postgres-main", "mysql-main"}}, alerting.results)
+	assert.Equal(t, [][]string{{"postgres-main", "mysql-main"}}, email.results)
+}
+
+func TestRouter_Report(t *testing.T) {
+	cfg := &config.Config{Global: config.GlobalConfig{Notifications: config.NotificationsConfig{Routes: []config.NotificationRoute{
+		{Events: []string{config.EventFailure}, Notifier: config.NotifierSlack},
+		{Events: []string{config.EventReport}, Notifier: config.NotifierEmail},
+	}}}}
+	slack, email := &recordingNotifier{}, &recordingNotifier{}
+	router := NewRouter(cfg)
+	router.Add(config.NotifierSlack, slack)
+	router.Add(config.NotifierEmail, email)
+
+	run := NewRun([]string{"postgres-main", "mysql-main"}, config.SlackConfig{ChannelID: "C-BACKUPS"})
+	require.NoError(t, router.NotifyReport(context.Background(), run, &Report{}))
+	assert.Empty(t, slack.events)
+	assert.Equal(t, []string{"report C-BACKUPS "}, email.events)
+}
+
+func TestRouter_FailingNotifier(t *testing.T) {
+	failing, working := &recordingNotifier{err: errors.New("slack unavailable")}, &recordingNotifier{}
+	router := NewRouter(&config.Config{})
+	router.Add(config.NotifierSlack, failing)
+	router.Add(config.NotifierEmail, working)
+
+	err := router.NotifyResult(context.Background(), NewRun([]string{"postgres-main"}, config.SlackConfig{}), nil, false)
+	assert.EqualError(t, err, "slack unavailable")
+	assert.Len(t, working.events, 1)
+}
+
+func TestSlackService_Thread(t *testing.T) {
+	ss := &SlackService{}
+	run := NewRun([]string{"postgres-main"}, config.SlackConfig{ChannelID: "C-BACKUPS"})
+	run.setSlackThread(&ThreadInfo{Channel: "C-BACKUPS", Timestamp: "1700000000.000100"})
+
+	assert.Equal(t, &ThreadInfo{Channel: "C-BACKUPS", Timestamp: "1700000000.000100"}, ss.thread(run))
+
+	routed := run.withRoute(config.NotificationRoute{ChannelID: "C-CRITICAL", Mention: "@here"})
+	assert.Equal(t, &ThreadInfo{Channel: "C-CRITICAL", mention: "@here"}, ss.thread(routed), "channels without a start message get top-level messages")
+
+	routed.setSlackThread(nil)
+	assert.Nil(t, ss.thread(routed), "runs whose start message failed are skipped")
+	assert.NotNil(t, ss.thread(run))
+}
+
+func TestWithMention(t *testing.T) {
+	assert.Equal(t, "message", withMention("", "message"))
+	assert.Equal(t, "<!here> message", withMention("@here", "message"))
+	assert.Equal(t, "<!channel> message", withMention("@channel", "message"))
+	assert.Equal(t, "<@U012AB3CD> message", withMention("<@U012AB3CD>", "message"))
+}
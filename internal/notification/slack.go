@@ -24,7 +24,9 @@ type SlackService struct {
 // ThreadInfo stores information about a Slack thread
 type ThreadInfo struct {
 	Channel   string
-	Timestamp string
+	Timestamp string // Empty to post top-level messages
+
+	mention string // Slack mention prepended to every message
 }
 
 // NewSlackService creates a new Slack service
@@ -73,31 +75,16 @@ func isValidBotToken(token string) bool {
 
 // SendBackupStarted sends the initial backup started message
 func (ss *SlackService) SendBackupStarted(ctx context.Context, strategies []string, slackConfig config.SlackConfig) (*ThreadInfo, error) {
+	return ss.sendBackupStarted(ctx, strategies, slackConfig, "", nil)
+}
+
+func (ss *SlackService) sendBackupStarted(ctx context.Context, strategies []string, slackConfig config.SlackConfig, mention string, trigger *Trigger) (*ThreadInfo, error) {
 	if ss.client == nil {
 		ss.logger.Warn("Slack client not configured, skipping notification")
 		return nil, nil
 	}
 
-	var message string
-	if len(strategies) == 1 {
-		message = fmt.Sprintf("🔄 **Database Backup Started**\n\n"+
-			"**Strategy:** %s\n"+
-			"**Started at:** %s\n\n"+
-			"_This message will be updated with the final status..._",
-			strategies[0],
-			time.Now().Format("2006-01-02 15:04:05 UTC"))
-	} else {
-		message = fmt.Sprintf("🔄 **Database Backups Started**\n\n"+
-			"**Total Strategies:** %d\n"+
-			"**Strategies:** %s\n"+
-			"**Started at:** %s\n\n"+
-			"_This message will be updated with the final status..._",
-			len(strategies),
-			strings.Join(strategies, ", "),
-			time.Now().Format("2006-01-02 15:04:05 UTC"))
-	}
-
-	timestamp, err := ss.sendMessage(ctx, slackConfig.ChannelID, message)
+	timestamp, err := ss.sendMessage(ctx, slackConfig.ChannelID, startedMessage(strategies, time.Now(), trigger), mention)
 	if err != nil {
 		return nil, err
 	}
@@ -114,25 +101,7 @@ func (ss *SlackService) SendBackupProgress(ctx context.Context, thread *ThreadIn
 		return nil
 	}
 
-	// Determine the icon based on message content
-	var icon string
-	messageLower := strings.ToLower(message)
-	if strings.Contains(messageLower, "error") || strings.Contains(messageLower, "failed") || strings.Contains(messageLower, "failure") {
-		icon = "❌"
-	} else if strings.Contains(messageLower, "retry") || strings.Contains(messageLower, "retrying") {
-		icon = "🔄"
-	} else if strings.Contains(messageLower, "uploading") {
-		icon = "📤"
-	} else if strings.Contains(messageLower, "cleaning") || strings.Contains(messageLower, "cleanup") {
-		icon = "🧹"
-	} else if strings.Contains(messageLower, "completed") || strings.Contains(messageLower, "success") {
-		icon = "✅"
-	} else {
-		icon = "📊"
-	}
-
-	progressMessage := fmt.Sprintf("%s **%s**: %s", icon, strategy, message)
-	_, err := ss.sendThreadMessage(ctx, thread.Channel, thread.Timestamp, progressMessage)
+	_, err := ss.sendThreadMessage(ctx, thread, progressMessage(strategy, message))
 	return err
 }
 
@@ -142,171 +111,27 @@ func (ss *SlackService) SendBackupResult(ctx context.Context, thread *ThreadInfo
 		return nil
 	}
 
-	// Create summary message
-	var message string
-	if overallSuccess {
-		message = "✅ **Database Backup Completed Successfully**\n\n"
-	} else {
-		message = "❌ **Database Backup Failed**\n\n"
-	}
-
-	// Add details for each strategy
-	for _, result := range results {
-		var status, icon string
-		if result.Success {
-			status = "Success"
-			icon = "✅"
-		} else {
-			status = "Failed"
-			icon = "❌"
-		}
-
-		message += fmt.Sprintf("%s **%s**: %s\n", icon, result.Strategy, status)
-
-		if result.Success {
-			message += fmt.Sprintf("   • Duration: %v\n", result.Duration.Round(time.Second))
-			message += fmt.Sprintf("   • Size: %s\n", formatBytes(result.Size))
-			if result.BackupPath != "" {
-				message += fmt.Sprintf("   • File: %s\n", result.BackupPath)
-			}
-			// Note: Database output is only shown for failed backups
-		} else {
-			// Enhanced error information for failed backups
-			if result.Error != nil {
-				message += fmt.Sprintf("   • **Error**: %s\n", result.Error.Error())
-			}
-
-			if result.Duration > 0 {
-				message += fmt.Sprintf("   • Duration before failure: %v\n", result.Duration.Round(time.Second))
-			}
-
-			if !result.StartTime.IsZero() {
-				message += fmt.Sprintf("   • Started at: %s\n", result.StartTime.Format("15:04:05 UTC"))
-			}
-
-			if !result.EndTime.IsZero() {
-				message += fmt.Sprintf("   • Failed at: %s\n", result.EndTime.Format("15:04:05 UTC"))
-			}
-
-			// Include command logs if available
-			if len(result.CommandLogs) > 0 {
-				message += "   • **Command Details**:\n"
-				for _, cmdLog := range result.CommandLogs {
-					// Truncate very long output to avoid Slack message limits
-					if len(cmdLog) > 500 {
-						cmdLog = cmdLog[:497] + "..."
-					}
-					// Format command logs with proper indentation
-					lines := strings.Split(cmdLog, "\n")
-					for _, line := range lines {
-						if strings.TrimSpace(line) != "" {
-							message += fmt.Sprintf("     `%s`\n", line)
-						}
-					}
-				}
+	// Offer to retry failed strategies when they can be run from Slack
+	var retryable []string
+	if ss.config.Global.SlackCommands.Enabled() {
+		for _, result := range results {
+			if !result.Success && anyStrategy(ss.config, func(strategy config.StrategyConfig) bool { return strategy.Name == result.Strategy }) {
+				retryable = append(retryable, result.Strategy)
 			}
 		}
-		message += "\n"
 	}
 
-	message += fmt.Sprintf("Completed at: %s", time.Now().Format("2006-01-02 15:04:05 UTC"))
-
-	// Send final message
-	_, err := ss.sendThreadMessage(ctx, thread.Channel, thread.Timestamp, message)
+	_, err := ss.sendThreadMessage(ctx, thread, resultMessage(results, overallSuccess, retryable))
 	if err != nil {
 		return err
 	}
 
-	// Always update the initial message with final status
-	var updatedMessage string
-
-	// Count successful and failed backups
-	totalBackups := len(results)
-	successfulBackups := 0
-	failedBackups := 0
-	var totalSize int64
-	var totalDuration time.Duration
-	var strategies []string
-
-	for _, result := range results {
-		strategies = append(strategies, result.Strategy)
-		if result.Success {
-			successfulBackups++
-			totalSize += result.Size
-		} else {
-			failedBackups++
-		}
-		totalDuration += result.Duration
-	}
-
-	if overallSuccess {
-		if totalBackups == 1 {
-			// Single backup
-			result := results[0]
-			updatedMessage = fmt.Sprintf("✅ **Database Backup Completed Successfully**\n\n"+
-				"**Strategy:** %s\n"+
-				"**Size:** %s\n"+
-				"**Duration:** %v\n"+
-				"**Completed at:** %s\n\n"+
-				"_See thread for detailed logs_",
-				result.Strategy,
-				formatBytes(result.Size),
-				result.Duration.Round(time.Second),
-				time.Now().Format("2006-01-02 15:04:05 UTC"))
-		} else {
-			// Multiple backups
-			updatedMessage = fmt.Sprintf("✅ **Database Backups Completed Successfully**\n\n"+
-				"**Total Backups:** %d/%d successful\n"+
-				"**Strategies:** %s\n"+
-				"**Total Size:** %s\n"+
-				"**Total Duration:** %v\n"+
-				"**Completed at:** %s\n\n"+
-				"_See thread for detailed logs_",
-				successfulBackups, totalBackups,
-				strings.Join(strategies, ", "),
-				formatBytes(totalSize),
-				totalDuration.Round(time.Second),
-				time.Now().Format("2006-01-02 15:04:05 UTC"))
-		}
-	} else {
-		if totalBackups == 1 {
-			// Single backup failed
-			result := results[0]
-			updatedMessage = fmt.Sprintf("❌ **Database Backup Failed**\n\n"+
-				"**Strategy:** %s\n"+
-				"**Error:** %s\n"+
-				"**Duration:** %v\n"+
-				"**Failed at:** %s\n\n"+
-				"_See thread for detailed error information_",
-				result.Strategy,
-				func() string {
-					if result.Error != nil {
-						errorMsg := result.Error.Error()
-						if len(errorMsg) > 100 {
-							return errorMsg[:97] + "..."
-						}
-						return errorMsg
-					}
-					return "Unknown error"
-				}(),
-				result.Duration.Round(time.Second),
-				time.Now().Format("2006-01-02 15:04:05 UTC"))
-		} else {
-			// Multiple backups with failures
-			updatedMessage = fmt.Sprintf("❌ **Database Backups Failed**\n\n"+
-				"**Results:** %d successful, %d failed (%d total)\n"+
-				"**Strategies:** %s\n"+
-				"**Total Duration:** %v\n"+
-				"**Completed at:** %s\n\n"+
-				"_See thread for detailed error information_",
-				successfulBackups, failedBackups, totalBackups,
-				strings.Join(strategies, ", "),
-				totalDuration.Round(time.Second),
-				time.Now().Format("2006-01-02 15:04:05 UTC"))
-		}
+	// Replace the start message with the color-coded final status, unless the result was
+	// posted to a channel without one
+	if thread.Timestamp == "" {
+		return nil
 	}
-
-	err = ss.updateMessage(ctx, thread.Channel, thread.Timestamp, updatedMessage)
+	err = ss.updateMessage(ctx, thread.Channel, thread.Timestamp, statusMessage(results, overallSuccess))
 	if err != nil {
 		ss.logger.WithError(err).Warn("Failed to update original message with final status")
 	}
@@ -314,49 +139,29 @@ func (ss *SlackService) SendBackupResult(ctx context.Context, thread *ThreadInfo
 	return nil
 }
 
-// SendDetailedError sends detailed error information for debugging
+// SendDetailedError sends detailed error information for debugging. The command logs are
+// uploaded as a text file, shown as a collapsible preview in the thread.
 func (ss *SlackService) SendDetailedError(ctx context.Context, thread *ThreadInfo, strategy string, result *backup.BackupResult) error {
 	if ss.client == nil || thread == nil || result == nil {
 		return nil
 	}
 
-	var message strings.Builder
-	message.WriteString(fmt.Sprintf("🔍 **Detailed Error Information for %s**\n\n", strategy))
-
-	if result.Error != nil {
-		message.WriteString(fmt.Sprintf("**Error Message:**\n```%s```\n\n", result.Error.Error()))
-	}
-
-	if !result.StartTime.IsZero() {
-		message.WriteString(fmt.Sprintf("**Start Time:** %s\n", result.StartTime.Format("2006-01-02 15:04:05 UTC")))
-	}
-
-	if !result.EndTime.IsZero() {
-		message.WriteString(fmt.Sprintf("**End Time:** %s\n", result.EndTime.Format("2006-01-02 15:04:05 UTC")))
-	}
-
-	if result.Duration > 0 {
-		message.WriteString(fmt.Sprintf("**Duration:** %v\n", result.Duration.Round(time.Second)))
+	if _, err := ss.sendThreadMessage(ctx, thread, detailedErrorMessage(strategy, result)); err != nil {
+		return err
 	}
-
-	if result.BackupPath != "" {
-		message.WriteString(fmt.Sprintf("**Backup Path:** %s\n", result.BackupPath))
+	if len(result.CommandLogs) == 0 {
+		return nil
 	}
 
-	if len(result.CommandLogs) > 0 {
-		message.WriteString("\n**Command Execution Logs:**\n")
-		for i, cmdLog := range result.CommandLogs {
-			// Split long logs into multiple messages if needed
-			if len(cmdLog) > 2000 {
-				// For very long logs, truncate and provide a summary
-				message.WriteString(fmt.Sprintf("```Log %d (truncated):\n%s...\n```\n", i+1, cmdLog[:2000]))
-			} else {
-				message.WriteString(fmt.Sprintf("```Log %d:\n%s\n```\n", i+1, cmdLog))
-			}
-		}
+	err := ss.uploadSnippet(ctx, thread, strategy+"-command-log.txt",
+		fmt.Sprintf("Command log of %s", strategy), strings.Join(result.CommandLogs, "\n"))
+	if err == nil {
+		return nil
 	}
 
-	_, err := ss.sendThreadMessage(ctx, thread.Channel, thread.Timestamp, message.String())
+	// Fall back to the tail of the log, e.g. without the files:write scope
+	ss.logger.WithError(err).Warn("Failed to upload command log to Slack, sending its tail instead")
+	_, err = ss.sendThreadMessage(ctx, thread, commandLogMessage(strategy, result.CommandLogs))
 	return err
 }
 
@@ -392,26 +197,96 @@ func (ss *SlackService) SendDatabaseOutput(ctx context.Context, thread *ThreadIn
 		icon = "⚠️"
 		messageType = "Database Warning"
 	} else {
-		icon = "�"
+		icon = "🚨"
 		messageType = "Database Issue"
 	}
 
-	// Truncate very long output
-	if len(cleanOutput) > 1500 {
-		cleanOutput = cleanOutput[:1497] + "..."
+	_, err := ss.sendThreadMessage(ctx, thread, outputMessage(strategy, icon, messageType, cleanOutput))
+	return err
+}
+
+// SendStaleWarning posts a warning about a strategy without a recent successful backup
+func (ss *SlackService) SendStaleWarning(ctx context.Context, thread *ThreadInfo, strategy string, lastSuccess time.Time) error {
+	if ss.client == nil || thread == nil {
+		return nil
 	}
 
-	// Format the message
-	var message strings.Builder
-	message.WriteString(fmt.Sprintf("%s **%s** - %s:\n", icon, strategy, messageType))
-	message.WriteString("```\n")
-	message.WriteString(cleanOutput)
-	message.WriteString("\n```")
+	_, err := ss.sendThreadMessage(ctx, thread, staleMessage(strategy, lastSuccess))
+	return err
+}
 
-	_, err := ss.sendThreadMessage(ctx, thread.Channel, thread.Timestamp, message.String())
+// NotifyStarted posts the backup started message and keeps its thread for the run
+func (ss *SlackService) NotifyStarted(ctx context.Context, run *Run) error {
+	thread, err := ss.sendBackupStarted(ctx, run.Strategies, run.Slack, run.mention, run.Trigger)
+	run.setSlackThread(thread)
+	if err != nil || thread == nil || run.Trigger == nil {
+		return err
+	}
+
+	// Link the command that started the run to its thread, once for routed copies
+	run.Trigger.linked.Do(func() {
+		err = ss.linkTrigger(ctx, run.Trigger, thread, run.Strategies)
+	})
+	return err
+}
+
+// NotifyProgress posts a progress update in the thread of the run
+func (ss *SlackService) NotifyProgress(ctx context.Context, run *Run, strategy, message string) error {
+	return ss.SendBackupProgress(ctx, ss.thread(run), strategy, message)
+}
+
+// NotifyOutput posts errors and warnings of the dump tools in the thread of the run
+func (ss *SlackService) NotifyOutput(ctx context.Context, run *Run, strategy, output string) error {
+	return ss.SendDatabaseOutput(ctx, ss.thread(run), strategy, output)
+}
+
+// NotifyRetry posts a failed attempt or retry in the thread of the run
+func (ss *SlackService) NotifyRetry(ctx context.Context, run *Run, strategy, message string) error {
+	return ss.SendBackupProgress(ctx, ss.thread(run), strategy, message)
+}
+
+// NotifyResult posts the results in the thread of the run and updates its start message
+func (ss *SlackService) NotifyResult(ctx context.Context, run *Run, results []*backup.BackupResult, overallSuccess bool) error {
+	return ss.SendBackupResult(ctx, ss.thread(run), results, overallSuccess)
+}
+
+// NotifyDetailedError posts the details of a failed backup in the thread of the run
+func (ss *SlackService) NotifyDetailedError(ctx context.Context, run *Run, strategy string, result *backup.BackupResult) error {
+	return ss.SendDetailedError(ctx, ss.thread(run), strategy, result)
+}
+
+// NotifyStale posts a warning about a strategy without a recent successful backup
+func (ss *SlackService) NotifyStale(ctx context.Context, run *Run, strategy string, lastSuccess time.Time) error {
+	return ss.SendStaleWarning(ctx, ss.thread(run), strategy, lastSuccess)
+}
+
+// NotifyReport posts a scheduled report to the channel of the run
+func (ss *SlackService) NotifyReport(ctx context.Context, run *Run, report *Report) error {
+	thread := ss.thread(run)
+	if ss.client == nil || thread == nil || thread.Channel == "" {
+		return nil
+	}
+
+	_, err := ss.sendThreadMessage(ctx, thread, reportMessage(report))
 	return err
 }
 
+// thread returns the thread of the run in its Slack channel. Channels that did not get the
+// start message, e.g. the channel of a route for failures, get top-level messages, and
+// runs whose start message could not be posted are skipped.
+func (ss *SlackService) thread(run *Run) *ThreadInfo {
+	thread, announced := run.slackThread()
+	if !announced {
+		thread = &ThreadInfo{Channel: run.Slack.ChannelID}
+	}
+	if thread == nil || run.mention == "" {
+		return thread
+	}
+	mentioned := *thread
+	mentioned.mention = run.mention
+	return &mentioned
+}
+
 // TestConnection tests the Slack connection
 func (ss *SlackService) TestConnection(ctx context.Context) error {
 	if ss.client == nil {
@@ -460,11 +335,8 @@ func (ss *SlackService) TestConnection(ctx context.Context) error {
 }
 
 // sendMessage sends a message to a Slack channel
-func (ss *SlackService) sendMessage(ctx context.Context, channel, message string) (string, error) {
-	_, timestamp, err := ss.client.PostMessageContext(ctx, channel,
-		slack.MsgOptionText(message, false),
-		slack.MsgOptionAsUser(true),
-	)
+func (ss *SlackService) sendMessage(ctx context.Context, channel string, message slackMessage, mention string) (string, error) {
+	_, timestamp, err := ss.client.PostMessageContext(ctx, channel, message.options(mention)...)
 	if err != nil {
 		return "", fmt.Errorf("failed to send Slack message: %w", err)
 	}
@@ -477,20 +349,21 @@ func (ss *SlackService) sendMessage(ctx context.Context, channel, message string
 	return timestamp, nil
 }
 
-// sendThreadMessage sends a message as a reply in a thread
-func (ss *SlackService) sendThreadMessage(ctx context.Context, channel, threadTimestamp, message string) (string, error) {
-	_, timestamp, err := ss.client.PostMessageContext(ctx, channel,
-		slack.MsgOptionText(message, false),
-		slack.MsgOptionTS(threadTimestamp),
-		slack.MsgOptionAsUser(true),
-	)
+// sendThreadMessage sends a message as a reply in a thread, or as a top-level message
+// when the thread has no timestamp
+func (ss *SlackService) sendThreadMessage(ctx context.Context, thread *ThreadInfo, message slackMessage) (string, error) {
+	options := message.options(thread.mention)
+	if thread.Timestamp != "" {
+		options = append(options, slack.MsgOptionTS(thread.Timestamp))
+	}
+	_, timestamp, err := ss.client.PostMessageContext(ctx, thread.Channel, options...)
 	if err != nil {
 		return "", fmt.Errorf("failed to send Slack thread message: %w", err)
 	}
 
 	ss.logger.WithFields(logrus.Fields{
-		"channel":         channel,
-		"thread":          threadTimestamp,
+		"channel":         thread.Channel,
+		"thread":          thread.Timestamp,
 		"reply_timestamp": timestamp,
 	}).Debug("Sent Slack thread message")
 
@@ -498,11 +371,8 @@ func (ss *SlackService) sendThreadMessage(ctx context.Context, channel, threadTi
 }
 
 // updateMessage updates an existing Slack message
-func (ss *SlackService) updateMessage(ctx context.Context, channel, timestamp, message string) error {
-	_, _, _, err := ss.client.UpdateMessageContext(ctx, channel, timestamp,
-		slack.MsgOptionText(message, false),
-		slack.MsgOptionAsUser(true),
-	)
+func (ss *SlackService) updateMessage(ctx context.Context, channel, timestamp string, message slackMessage) error {
+	_, _, _, err := ss.client.UpdateMessageContext(ctx, channel, timestamp, message.options("")...)
 	if err != nil {
 		return fmt.Errorf("failed to update Slack message: %w", err)
 	}
@@ -515,6 +385,92 @@ func (ss *SlackService) updateMessage(ctx context.Context, channel, timestamp, m
 	return nil
 }
 
+// linkTrigger posts a link to the thread of a run started from Slack as the response to its
+// command, or in the thread of the clicked button
+func (ss *SlackService) linkTrigger(ctx context.Context, trigger *Trigger, thread *ThreadInfo, strategies []string) error {
+	permalink, err := ss.client.GetPermalinkContext(ctx, &slack.PermalinkParameters{Channel: thread.Channel, Ts: thread.Timestamp})
+	if err != nil {
+		return fmt.Errorf("failed to get Slack permalink: %w", err)
+	}
+
+	text := fmt.Sprintf("<@%s> started a backup of %s: <%s|follow it in its thread>", trigger.UserID, escapeMrkdwn(strings.Join(strategies, ", ")), permalink)
+	if trigger.ResponseURL != "" {
+		err := slack.PostWebhookContext(ctx, trigger.ResponseURL, &slack.WebhookMessage{Text: text, ResponseType: slack.ResponseTypeInChannel})
+		if err != nil {
+			return fmt.Errorf("failed to respond to Slack command: %w", err)
+		}
+	}
+	if trigger.Thread != nil {
+		message := slackMessage{Text: text, Blocks: []slack.Block{slack.NewSectionBlock(mrkdwn("🔁 "+text), nil, nil)}}
+		if _, err := ss.sendThreadMessage(ctx, trigger.Thread, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// userGroupMembers returns the user IDs of the members of a Slack user group, which requires
+// the usergroups:read scope
+func (ss *SlackService) userGroupMembers(ctx context.Context, group string) ([]string, error) {
+	if ss.client == nil {
+		return nil, fmt.Errorf("Slack client not configured")
+	}
+	members, err := ss.client.GetUserGroupMembersContext(ctx, group)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members of Slack user group %s: %w", group, err)
+	}
+	return members, nil
+}
+
+// uploadSnippet uploads text as a file in a thread, which requires the files:write scope
+func (ss *SlackService) uploadSnippet(ctx context.Context, thread *ThreadInfo, filename, title, content string) error {
+	_, err := ss.client.UploadFileV2Context(ctx, slack.UploadFileV2Parameters{
+		Channel:         thread.Channel,
+		ThreadTimestamp: thread.Timestamp,
+		Filename:        filename,
+		Title:           title,
+		Content:         content,
+		FileSize:        len(content),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload Slack file: %w", err)
+	}
+
+	ss.logger.WithFields(logrus.Fields{
+		"channel":  thread.Channel,
+		"thread":   thread.Timestamp,
+		"filename": filename,
+	}).Debug("Uploaded Slack file")
+
+	return nil
+}
+
+// withMention prepends a mention to a message
+func withMention(mention, message string) string {
+	if mention == "" {
+		return message
+	}
+	return slackMention(mention) + " " + message
+}
+
+// slackMention converts @here, @channel and @everyone to Slack's special mentions, other
+// mentions such as <@U012AB3CD> are used as they are
+func slackMention(mention string) string {
+	switch mention {
+	case "@here", "@channel", "@everyone":
+		return "<!" + strings.TrimPrefix(mention, "@") + ">"
+	}
+	return mention
+}
+
+// formatLastSuccess describes the time of the last successful backup of a strategy
+func formatLastSuccess(lastSuccess time.Time) string {
+	if lastSuccess.IsZero() {
+		return "none since the service started"
+	}
+	return lastSuccess.UTC().Format("2006-01-02 15:04:05 UTC")
+}
+
 // formatBytes formats bytes into human readable format
 func formatBytes(bytes int64) string {
 	const unit = 1024
@@ -0,0 +1,361 @@
+package notification
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"easy-backup/internal/backup"
+)
+
+// Limits of the Slack Block Kit
+const (
+	slackMaxSectionText = 3000
+	slackMaxFieldText   = 2000
+	slackMaxFields      = 10
+	slackMaxHeaderText  = 150
+	slackMaxBlocks      = 50
+)
+
+// Colors of the status card attachments
+const (
+	slackColorRunning = "#439fe0"
+	slackColorSuccess = "#2eb886"
+	slackColorFailure = "#e01e5a"
+	slackColorWarning = "#daa038"
+)
+
+// slackMessage is a Block Kit message with the plain text shown in notifications
+type slackMessage struct {
+	Text   string
+	Blocks []slack.Block
+	Color  string // Sends the blocks as a color-coded attachment, empty for plain blocks
+}
+
+// options returns the options posting or updating the message, with the mention above it
+func (m slackMessage) options(mention string) []slack.MsgOption {
+	var blocks []slack.Block
+	if mention != "" {
+		blocks = append(blocks, slack.NewSectionBlock(mrkdwn(slackMention(mention)), nil, nil))
+	}
+
+	options := []slack.MsgOption{
+		slack.MsgOptionText(withMention(mention, m.Text), false),
+		slack.MsgOptionAsUser(true),
+	}
+	if m.Color != "" {
+		options = append(options, slack.MsgOptionAttachments(slack.Attachment{
+			Color:    m.Color,
+			Fallback: m.Text,
+			Blocks:   slack.Blocks{BlockSet: m.Blocks},
+		}))
+	} else {
+		blocks = append(blocks, m.Blocks...)
+	}
+	if len(blocks) > slackMaxBlocks {
+		blocks = blocks[:slackMaxBlocks]
+	}
+	return append(options, slack.MsgOptionBlocks(blocks...))
+}
+
+// startedMessage announces a run, it is updated with the final status later
+func startedMessage(strategies []string, startedAt time.Time, trigger *Trigger) slackMessage {
+	title := "🔄 Database Backup Started"
+	facts := []cardFact{{Name: "Strategy", Value: strings.Join(strategies, ", ")}}
+	if len(strategies) > 1 {
+		title = "🔄 Database Backups Started"
+		facts = []cardFact{
+			{Name: "Total Strategies", Value: fmt.Sprint(len(strategies))},
+			{Name: "Strategies", Value: strings.Join(strategies, ", ")},
+		}
+	}
+	facts = append(facts, cardFact{Name: "Started at", Value: formatSlackTime(startedAt)})
+
+	blocks := append([]slack.Block{headerBlock(title)}, factBlocks(facts)...)
+	if trigger != nil {
+		blocks = append(blocks, contextBlock(fmt.Sprintf("Started by <@%s> with `%s`", trigger.UserID, escapeMrkdwn(trigger.Command))))
+	}
+	return slackMessage{
+		Text:   fmt.Sprintf("%s: %s", title, strings.Join(strategies, ", ")),
+		Color:  slackColorRunning,
+		Blocks: append(blocks, contextBlock("_This message will be updated with the final status..._")),
+	}
+}
+
+// progressMessage is a progress update of a strategy, with an icon matching its content
+func progressMessage(strategy, message string) slackMessage {
+	var icon string
+	messageLower := strings.ToLower(message)
+	if strings.Contains(messageLower, "error") || strings.Contains(messageLower, "failed") || strings.Contains(messageLower, "failure") {
+		icon = "❌"
+	} else if strings.Contains(messageLower, "retry") || strings.Contains(messageLower, "retrying") {
+		icon = "🔄"
+	} else if strings.Contains(messageLower, "uploading") {
+		icon = "📤"
+	} else if strings.Contains(messageLower, "cleaning") || strings.Contains(messageLower, "cleanup") {
+		icon = "🧹"
+	} else if strings.Contains(messageLower, "completed") || strings.Contains(messageLower, "success") {
+		icon = "✅"
+	} else {
+		icon = "📊"
+	}
+
+	return slackMessage{
+		Text:   fmt.Sprintf("%s %s: %s", icon, strategy, message),
+		Blocks: []slack.Block{slack.NewSectionBlock(mrkdwn(fmt.Sprintf("%s *%s*: %s", icon, escapeMrkdwn(strategy), escapeMrkdwn(message))), nil, nil)},
+	}
+}
+
+// resultMessage lists the result of every strategy of a run in its thread, with a "Retry now"
+// button for the retryable strategies
+func resultMessage(results []*backup.BackupResult, overallSuccess bool, retryable []string) slackMessage {
+	title := "✅ Database Backup Completed Successfully"
+	if !overallSuccess {
+		title = "❌ Database Backup Failed"
+	}
+
+	blocks := []slack.Block{headerBlock(title)}
+	for _, result := range results {
+		section := resultSection(result)
+		blocks = append(blocks, slack.NewDividerBlock(), slack.NewSectionBlock(mrkdwn("*"+escapeMrkdwn(section.Heading)+"*"), nil, nil))
+		blocks = append(blocks, factBlocks(section.Facts)...)
+		for _, database := range result.Databases {
+			blocks = append(blocks, contextBlock(fmt.Sprintf("%s: %s in %v", escapeMrkdwn(database.Name), formatBytes(database.Size), database.Duration.Round(time.Second))))
+		}
+		if slices.Contains(retryable, result.Strategy) {
+			button := slack.NewButtonBlockElement(retryActionID, result.Strategy, slack.NewTextBlockObject(slack.PlainTextType, "Retry now", false, false))
+			blocks = append(blocks, slack.NewActionBlock("", button.WithStyle(slack.StylePrimary)))
+		}
+	}
+	blocks = append(blocks, contextBlock("Completed at "+formatSlackTime(time.Now())))
+
+	return slackMessage{Text: title, Blocks: blocks}
+}
+
+// statusMessage is the color-coded final status replacing the start message of a run
+func statusMessage(results []*backup.BackupResult, overallSuccess bool) slackMessage {
+	successful, failed := 0, 0
+	var totalSize int64
+	var totalDuration time.Duration
+	var strategies []string
+	for _, result := range results {
+		strategies = append(strategies, result.Strategy)
+		if result.Success {
+			successful++
+			totalSize += result.Size
+		} else {
+			failed++
+		}
+		totalDuration += result.Duration
+	}
+
+	var title string
+	var facts []cardFact
+	color := slackColorSuccess
+	footer := "_See thread for detailed logs_"
+	switch {
+	case overallSuccess && len(results) == 1:
+		result := results[0]
+		title = "✅ Database Backup Completed Successfully"
+		facts = []cardFact{
+			{Name: "Strategy", Value: result.Strategy},
+			{Name: "Size", Value: formatBytes(result.Size)},
+			{Name: "Duration", Value: result.Duration.Round(time.Second).String()},
+		}
+		if len(result.S3Locations) > 0 {
+			facts = append(facts, cardFact{Name: "S3 location", Value: strings.Join(result.S3Locations, "\n")})
+		}
+		facts = append(facts, cardFact{Name: "Completed at", Value: formatSlackTime(time.Now())})
+	case overallSuccess:
+		title = "✅ Database Backups Completed Successfully"
+		facts = []cardFact{
+			{Name: "Total Backups", Value: fmt.Sprintf("%d/%d successful", successful, len(results))},
+			{Name: "Strategies", Value: strings.Join(strategies, ", ")},
+			{Name: "Total Size", Value: formatBytes(totalSize)},
+			{Name: "Total Duration", Value: totalDuration.Round(time.Second).String()},
+			{Name: "Completed at", Value: formatSlackTime(time.Now())},
+		}
+	case len(results) == 1:
+		result := results[0]
+		title = "❌ Database Backup Failed"
+		color = slackColorFailure
+		footer = "_See thread for detailed error information_"
+		message := "Unknown error"
+		if result.Error != nil {
+			message = result.Error.Error()
+		}
+		facts = []cardFact{
+			{Name: "Strategy", Value: result.Strategy},
+			{Name: "Duration", Value: result.Duration.Round(time.Second).String()},
+			{Name: "Error", Value: message},
+			{Name: "Failed at", Value: formatSlackTime(time.Now())},
+		}
+	default:
+		title = "❌ Database Backups Failed"
+		color = slackColorFailure
+		footer = "_See thread for detailed error information_"
+		facts = []cardFact{
+			{Name: "Results", Value: fmt.Sprintf("%d successful, %d failed (%d total)", successful, failed, len(results))},
+			{Name: "Strategies", Value: strings.Join(strategies, ", ")},
+			{Name: "Total Duration", Value: totalDuration.Round(time.Second).String()},
+			{Name: "Completed at", Value: formatSlackTime(time.Now())},
+		}
+	}
+
+	return slackMessage{
+		Text:   fmt.Sprintf("%s: %s", title, strings.Join(strategies, ", ")),
+		Color:  color,
+		Blocks: append(append([]slack.Block{headerBlock(title)}, factBlocks(facts)...), contextBlock(footer)),
+	}
+}
+
+// detailedErrorMessage describes a failed backup. Its command log is uploaded separately.
+func detailedErrorMessage(strategy string, result *backup.BackupResult) slackMessage {
+	title := fmt.Sprintf("🔍 Detailed Error Information for %s", strategy)
+	blocks := []slack.Block{headerBlock(title)}
+	if result.Error != nil {
+		blocks = append(blocks, slack.NewSectionBlock(mrkdwn("*Error Message:*\n"+codeBlock(result.Error.Error())), nil, nil))
+	}
+
+	var facts []cardFact
+	if !result.StartTime.IsZero() {
+		facts = append(facts, cardFact{Name: "Start Time", Value: formatSlackTime(result.StartTime)})
+	}
+	if !result.EndTime.IsZero() {
+		facts = append(facts, cardFact{Name: "End Time", Value: formatSlackTime(result.EndTime)})
+	}
+	if result.Duration > 0 {
+		facts = append(facts, cardFact{Name: "Duration", Value: result.Duration.Round(time.Second).String()})
+	}
+	if result.BackupPath != "" {
+		facts = append(facts, cardFact{Name: "Backup Path", Value: result.BackupPath})
+	}
+	blocks = append(blocks, factBlocks(facts)...)
+
+	return slackMessage{Text: title, Blocks: blocks}
+}
+
+// commandLogMessage shows the tail of a command log inline, for when it cannot be uploaded
+func commandLogMessage(strategy string, logs []string) slackMessage {
+	title := fmt.Sprintf("Command log of %s (last %d lines)", strategy, len(commandLogTail(logs)))
+	return slackMessage{
+		Text: title,
+		Blocks: []slack.Block{
+			slack.NewSectionBlock(mrkdwn("*"+escapeMrkdwn(title)+"*\n"+codeBlock(strings.Join(commandLogTail(logs), "\n"))), nil, nil),
+		},
+	}
+}
+
+// outputMessage shows errors and warnings printed by the dump tools
+func outputMessage(strategy, icon, messageType, output string) slackMessage {
+	title := fmt.Sprintf("%s *%s* - %s:", icon, escapeMrkdwn(strategy), messageType)
+	return slackMessage{
+		Text:   fmt.Sprintf("%s %s - %s", icon, strategy, messageType),
+		Blocks: []slack.Block{slack.NewSectionBlock(mrkdwn(title+"\n"+codeBlock(output)), nil, nil)},
+	}
+}
+
+// staleMessage warns about a strategy without a recent successful backup
+func staleMessage(strategy string, lastSuccess time.Time) slackMessage {
+	title := "⚠️ Backup Overdue"
+	return slackMessage{
+		Text:  fmt.Sprintf("%s: %s", title, strategy),
+		Color: slackColorWarning,
+		Blocks: append([]slack.Block{headerBlock(title)}, factBlocks([]cardFact{
+			{Name: "Strategy", Value: strategy},
+			{Name: "Last successful backup", Value: formatLastSuccess(lastSuccess)},
+		})...),
+	}
+}
+
+// reportMessage lists the summary of every strategy of a report, one section each within
+// the block limit
+func reportMessage(report *Report) slackMessage {
+	color := slackColorSuccess
+	if !report.Healthy() {
+		color = slackColorWarning
+	}
+
+	blocks := []slack.Block{headerBlock(report.Title()), contextBlock(reportPeriod(report))}
+	if notRun := report.NotRun(); len(notRun) > 0 {
+		blocks = append(blocks, slack.NewSectionBlock(mrkdwn("*Did not run:* "+escapeMrkdwn(strings.Join(notRun, ", "))), nil, nil))
+	}
+
+	// Keep room for a mention and the note about hidden strategies
+	strategies := report.Strategies
+	if limit := slackMaxBlocks - len(blocks) - 2; len(strategies) > limit {
+		strategies = strategies[:limit]
+	}
+	for _, strategy := range strategies {
+		lines := []string{"*" + escapeMrkdwn(reportHeading(strategy)) + "*"}
+		for _, fact := range reportFacts(strategy) {
+			lines = append(lines, fmt.Sprintf("%s: %s", escapeMrkdwn(fact.Name), escapeMrkdwn(fact.Value)))
+		}
+		blocks = append(blocks, slack.NewSectionBlock(mrkdwn(truncateText(strings.Join(lines, "\n"), slackMaxSectionText)), nil, nil))
+	}
+	if hidden := len(report.Strategies) - len(strategies); hidden > 0 {
+		blocks = append(blocks, contextBlock(fmt.Sprintf("_%d more strategies are not shown_", hidden)))
+	}
+
+	return slackMessage{
+		Text:   fmt.Sprintf("%s: %s", report.Title(), reportPeriod(report)),
+		Color:  color,
+		Blocks: blocks,
+	}
+}
+
+// factBlocks lays out facts as section fields, at most ten per section
+func factBlocks(facts []cardFact) []slack.Block {
+	var blocks []slack.Block
+	for start := 0; start < len(facts); start += slackMaxFields {
+		end := min(start+slackMaxFields, len(facts))
+		var fields []*slack.TextBlockObject
+		for _, fact := range facts[start:end] {
+			text := fmt.Sprintf("*%s*\n%s", escapeMrkdwn(fact.Name), escapeMrkdwn(fact.Value))
+			fields = append(fields, slack.NewTextBlockObject(slack.MarkdownType, truncateText(text, slackMaxFieldText), false, false))
+		}
+		blocks = append(blocks, slack.NewSectionBlock(nil, fields, nil))
+	}
+	return blocks
+}
+
+func headerBlock(text string) slack.Block {
+	return slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, truncateText(text, slackMaxHeaderText), true, false))
+}
+
+func contextBlock(text string) slack.Block {
+	return slack.NewContextBlock("", mrkdwn(text))
+}
+
+// mrkdwn creates a mrkdwn text object, truncated to the limit of section texts
+func mrkdwn(text string) *slack.TextBlockObject {
+	return slack.NewTextBlockObject(slack.MarkdownType, truncateText(text, slackMaxSectionText), false, false)
+}
+
+// codeBlock formats text as an escaped code block, keeping its end when it is too long
+func codeBlock(text string) string {
+	text = escapeMrkdwn(strings.ReplaceAll(text, "```", "'''"))
+	if limit := slackMaxSectionText - 100; len(text) > limit {
+		text = "..." + text[len(text)-limit:]
+	}
+	return "```" + text + "```"
+}
+
+// escapeMrkdwn escapes the control characters of Slack mrkdwn
+func escapeMrkdwn(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+func truncateText(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	return text[:limit-3] + "..."
+}
+
+func formatSlackTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05 UTC")
+}
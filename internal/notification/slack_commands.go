@@ -0,0 +1,240 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"easy-backup/internal/config"
+	"easy-backup/internal/logger"
+)
+
+const (
+	// retryActionID identifies the "Retry now" buttons of failure messages
+	retryActionID = "retry_backup"
+
+	// slackRequestBodyLimit bounds the body of a command or interaction request
+	slackRequestBodyLimit = 1 << 20
+)
+
+// StrategyRunner runs a backup strategy on demand, implemented by the scheduler
+type StrategyRunner interface {
+	ExecuteStrategyWithTrigger(strategyName string, trigger *Trigger) error
+}
+
+// SlackCommandHandler serves the slash command and the "Retry now" buttons of a Slack app.
+// Requests are verified with the signing secret of the app and only allowed users can run
+// backups, which run inside the daemon.
+type SlackCommandHandler struct {
+	config *config.Config
+	logger *logrus.Logger
+	slack  *SlackService
+	runner StrategyRunner
+
+	mu      sync.Mutex
+	running map[string]bool // Strategies started from Slack that are still running
+}
+
+// NewSlackCommandHandler creates the handler of the Slack commands endpoint
+func NewSlackCommandHandler(cfg *config.Config, slackService *SlackService, runner StrategyRunner) *SlackCommandHandler {
+	return &SlackCommandHandler{
+		config:  cfg,
+		logger:  logger.GetLogger(),
+		slack:   slackService,
+		runner:  runner,
+		running: map[string]bool{},
+	}
+}
+
+// ServeHTTP handles a slash command or a block action
+func (h *SlackCommandHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, slackRequestBodyLimit))
+	if err != nil {
+		http.Error(w, "failed to read request", http.StatusBadRequest)
+		return
+	}
+	if err := h.verify(r.Header, body); err != nil {
+		h.logger.WithError(err).Warn("Rejected unsigned Slack request")
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	if payload := values.Get("payload"); payload != "" {
+		h.handleInteraction(r.Context(), w, payload)
+		return
+	}
+	h.handleCommand(r.Context(), w, values)
+}
+
+// verify checks the signature and timestamp of a request
+func (h *SlackCommandHandler) verify(header http.Header, body []byte) error {
+	verifier, err := slack.NewSecretsVerifier(header, h.config.Global.SlackCommands.SigningSecret)
+	if err != nil {
+		return err
+	}
+	if _, err := verifier.Write(body); err != nil {
+		return err
+	}
+	return verifier.Ensure()
+}
+
+// handleCommand answers a slash command, e.g. "/backup run postgres-prod"
+func (h *SlackCommandHandler) handleCommand(ctx context.Context, w http.ResponseWriter, values url.Values) {
+	command, text, userID := values.Get("command"), strings.TrimSpace(values.Get("text")), values.Get("user_id")
+	args := strings.Fields(text)
+
+	switch {
+	case len(args) == 2 && args[0] == "run":
+		trigger := &Trigger{
+			UserID:      userID,
+			Command:     strings.TrimSpace(command + " " + text),
+			ResponseURL: values.Get("response_url"),
+		}
+		writeSlackResponse(w, h.run(ctx, args[1], trigger))
+	case len(args) == 1 && args[0] == "list":
+		writeSlackResponse(w, h.strategyList())
+	default:
+		writeSlackResponse(w, fmt.Sprintf("Usage: `%s run <strategy>` runs a backup now, `%s list` lists the strategies", command, command))
+	}
+}
+
+// handleInteraction answers a click on a "Retry now" button through the response URL of the
+// message, the retry is linked in the thread of the failure. The click is acknowledged first,
+// since Slack expects the acknowledgement within 3 seconds and checking the allowed user
+// groups calls the Slack API.
+func (h *SlackCommandHandler) handleInteraction(ctx context.Context, w http.ResponseWriter, payload string) {
+	var callback slack.InteractionCallback
+	if err := json.Unmarshal([]byte(payload), &callback); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	if callback.Type != slack.InteractionTypeBlockActions {
+		return
+	}
+
+	// The request context is canceled once the handler returns
+	ctx = context.WithoutCancel(ctx)
+	for _, action := range callback.ActionCallback.BlockActions {
+		if action.ActionID != retryActionID {
+			continue
+		}
+
+		channel := callback.Container.ChannelID
+		if channel == "" {
+			channel = callback.Channel.ID
+		}
+		threadTS := callback.Container.ThreadTs
+		if threadTS == "" {
+			threadTS = callback.Container.MessageTs
+		}
+		trigger := &Trigger{
+			UserID:  callback.User.ID,
+			Command: "Retry now: " + action.Value,
+			Thread:  &ThreadInfo{Channel: channel, Timestamp: threadTS},
+		}
+
+		go func() {
+			reply := h.run(ctx, action.Value, trigger)
+			if callback.ResponseURL == "" {
+				return
+			}
+			message := &slack.WebhookMessage{Text: reply, ResponseType: slack.ResponseTypeEphemeral}
+			if err := slack.PostWebhookContext(ctx, callback.ResponseURL, message); err != nil {
+				h.logger.WithError(err).Warn("Failed to respond to Slack button")
+			}
+		}()
+	}
+}
+
+// run starts a backup of a strategy for an allowed user and returns the reply to the user
+func (h *SlackCommandHandler) run(ctx context.Context, strategyName string, trigger *Trigger) string {
+	fields := logrus.Fields{"strategy": strategyName, "user": trigger.UserID, "command": trigger.Command}
+	if !h.allowed(ctx, trigger.UserID) {
+		h.logger.WithFields(fields).Warn("Slack user is not allowed to run backups")
+		return "⛔ You are not allowed to run backups."
+	}
+	if !anyStrategy(h.config, func(strategy config.StrategyConfig) bool { return strategy.Name == strategyName }) {
+		return fmt.Sprintf("❓ Unknown strategy `%s`.", strategyName)
+	}
+
+	h.mu.Lock()
+	if h.running[strategyName] {
+		h.mu.Unlock()
+		return fmt.Sprintf("⏳ A backup of `%s` started from Slack is still running.", strategyName)
+	}
+	h.running[strategyName] = true
+	h.mu.Unlock()
+
+	h.logger.WithFields(fields).Info("Starting backup from Slack")
+	go func() {
+		defer func() {
+			h.mu.Lock()
+			delete(h.running, strategyName)
+			h.mu.Unlock()
+		}()
+		if err := h.runner.ExecuteStrategyWithTrigger(strategyName, trigger); err != nil {
+			h.logger.WithFields(fields).WithError(err).Error("Backup started from Slack failed to run")
+		}
+	}()
+	return fmt.Sprintf("🚀 Starting a backup of `%s`, its thread will be linked here.", strategyName)
+}
+
+// allowed reports whether a Slack user is allowed or a member of an allowed user group
+func (h *SlackCommandHandler) allowed(ctx context.Context, userID string) bool {
+	commands := h.config.Global.SlackCommands
+	if userID == "" {
+		return false
+	}
+	if slices.Contains(commands.AllowedUsers, userID) {
+		return true
+	}
+	for _, group := range commands.AllowedGroups {
+		members, err := h.slack.userGroupMembers(ctx, group)
+		if err != nil {
+			h.logger.WithError(err).Warn("Failed to check Slack user group")
+			continue
+		}
+		if slices.Contains(members, userID) {
+			return true
+		}
+	}
+	return false
+}
+
+// strategyList lists the strategies that can be run
+func (h *SlackCommandHandler) strategyList() string {
+	if len(h.config.Strategies) == 0 {
+		return "No backup strategies are configured."
+	}
+	lines := []string{"*Backup strategies:*"}
+	for _, strategy := range h.config.Strategies {
+		lines = append(lines, fmt.Sprintf("• `%s` (%s, %s)", strategy.Name, strategy.DatabaseType, strategy.Schedule))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// writeSlackResponse answers a slash command with a message only its user sees
+func writeSlackResponse(w http.ResponseWriter, text string) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(slack.Msg{ResponseType: slack.ResponseTypeEphemeral, Text: text})
+}
@@ -0,0 +1,239 @@
+package notification
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"easy-backup/internal/backup"
+	"easy-backup/internal/config"
+	"easy-backup/internal/logger"
+)
+
+const testSigningSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+
+// fakeRunner records the strategies it runs
+type fakeRunner struct {
+	runs chan *Trigger
+}
+
+func (fr *fakeRunner) ExecuteStrategyWithTrigger(strategyName string, trigger *Trigger) error {
+	fr.runs <- trigger
+	return nil
+}
+
+func newTestCommandHandler(t *testing.T) (*SlackCommandHandler, *fakeRunner) {
+	_ = logger.InitLogger("info")
+	service, _ := newSlackAPI(t)
+	service.config.Global.SlackCommands = config.SlackCommandsConfig{
+		SigningSecret: testSigningSecret,
+		AllowedUsers:  []string{"U012AB3CD"},
+		AllowedGroups: []string{"S0614TZR7"},
+	}
+	service.config.Strategies = []config.StrategyConfig{{Name: "postgres-prod", DatabaseType: "postgres", Schedule: "0 2 * * *"}}
+
+	runner := &fakeRunner{runs: make(chan *Trigger, 1)}
+	return NewSlackCommandHandler(service.config, service, runner), runner
+}
+
+// signedRequest builds a request signed like Slack does
+func signedRequest(method string, form url.Values, secret string) *http.Request {
+	body := form.Encode()
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+
+	request := httptest.NewRequest(method, "/slack/commands", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	request.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return request
+}
+
+func TestSlackCommandHandler_Command(t *testing.T) {
+	command := func(user, text string) url.Values {
+		return url.Values{"command": {"/backup"}, "text": {text}, "user_id": {user}, "response_url": {"https://hooks.slack.com/commands/T1/1/abc"}}
+	}
+
+	tests := []struct {
+		name    string
+		request *http.Request
+		status  int
+		reply   string
+		runs    bool
+	}{
+		{name: "run", request: signedRequest(http.MethodPost, command("U012AB3CD", "run postgres-prod"), testSigningSecret), status: http.StatusOK, reply: "Starting a backup of `postgres-prod`", runs: true},
+		{name: "member of allowed group", request: signedRequest(http.MethodPost, command("U0GROUPMEMBER", "run postgres-prod"), testSigningSecret), status: http.StatusOK, reply: "Starting a backup", runs: true},
+		{name: "user not allowed", request: signedRequest(http.MethodPost, command("U0STRANGER", "run postgres-prod"), testSigningSecret), status: http.StatusOK, reply: "not allowed"},
+		{name: "unknown strategy", request: signedRequest(http.MethodPost, command("U012AB3CD", "run mysql-prod"), testSigningSecret), status: http.StatusOK, reply: "Unknown strategy `mysql-prod`"},
+		{name: "list", request: signedRequest(http.MethodPost, command("U012AB3CD", "list"), testSigningSecret), status: http.StatusOK, reply: "`postgres-prod` (postgres, 0 2 * * *)"},
+		{name: "usage", request: signedRequest(http.MethodPost, command("U012AB3CD", ""), testSigningSecret), status: http.StatusOK, reply: "Usage: `/backup run <strategy>`"},
+		{name: "invalid signature", request: signedRequest(http.MethodPost, command("U012AB3CD", "run postgres-prod"), "wrong-secret"), status: http.StatusUnauthorized},
+		{name: "unsigned", request: httptest.NewRequest(http.MethodPost, "/slack/commands", strings.NewReader(command("U012AB3CD", "run postgres-prod").Encode())), status: http.StatusUnauthorized},
+		{name: "not a post", request: signedRequest(http.MethodGet, command("U012AB3CD", "list"), testSigningSecret), status: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, runner := newTestCommandHandler(t)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, tt.request)
+
+			require.Equal(t, tt.status, recorder.Code)
+			if tt.status != http.StatusOK {
+				return
+			}
+			var reply slack.Msg
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &reply))
+			assert.Equal(t, slack.ResponseTypeEphemeral, reply.ResponseType)
+			assert.Contains(t, reply.Text, tt.reply)
+
+			if !tt.runs {
+				assert.Empty(t, runner.runs)
+				return
+			}
+			select {
+			case trigger := <-runner.runs:
+				assert.Equal(t, "/backup run postgres-prod", trigger.Command)
+				assert.Equal(t, "https://hooks.slack.com/commands/T1/1/abc", trigger.ResponseURL)
+				assert.Nil(t, trigger.Thread)
+			case <-time.After(time.Second):
+				t.Fatal("backup was not started")
+			}
+		})
+	}
+}
+
+func TestSlackCommandHandler_RetryButton(t *testing.T) {
+	handler, runner := newTestCommandHandler(t)
+
+	// The response URL only answers once the click was acknowledged
+	acknowledged := make(chan struct{})
+	responses := make(chan slack.WebhookMessage, 1)
+	responseServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-acknowledged
+		var message slack.WebhookMessage
+		body, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(body, &message))
+		responses <- message
+	}))
+	defer responseServer.Close()
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"type":         "block_actions",
+		"user":         map[string]string{"id": "U012AB3CD"},
+		"response_url": responseServer.URL,
+		"container":    map[string]string{"type": "message", "channel_id": "C1234567890", "message_ts": "1700000000.000300", "thread_ts": "1700000000.000100"},
+		"actions":      []map[string]string{{"action_id": retryActionID, "block_id": "retry", "value": "postgres-prod", "type": "button"}},
+	})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		handler.ServeHTTP(recorder, signedRequest(http.MethodPost, url.Values{"payload": {string(payload)}}, testSigningSecret))
+	}()
+	select {
+	case <-served:
+	case <-time.After(time.Second):
+		close(acknowledged)
+		t.Fatal("click was not acknowledged before replying")
+	}
+	close(acknowledged)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	select {
+	case trigger := <-runner.runs:
+		assert.Equal(t, "U012AB3CD", trigger.UserID)
+		assert.Equal(t, &ThreadInfo{Channel: "C1234567890", Timestamp: "1700000000.000100"}, trigger.Thread, "the retry is linked in the thread of the failure")
+	case <-time.After(time.Second):
+		t.Fatal("backup was not retried")
+	}
+	select {
+	case response := <-responses:
+		assert.Equal(t, slack.ResponseTypeEphemeral, response.ResponseType)
+		assert.Contains(t, response.Text, "Starting a backup of `postgres-prod`")
+	case <-time.After(time.Second):
+		t.Fatal("no reply to the click")
+	}
+}
+
+func TestSlackService_NotifyStarted_LinksTrigger(t *testing.T) {
+	_ = logger.InitLogger("info")
+	service, requests := newSlackAPI(t)
+
+	responses := make(chan slack.WebhookMessage, 1)
+	responseServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message slack.WebhookMessage
+		body, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(body, &message))
+		responses <- message
+	}))
+	defer responseServer.Close()
+
+	run := NewRun([]string{"postgres-prod"}, config.SlackConfig{ChannelID: "C1234567890"})
+	run.Trigger = &Trigger{
+		UserID:      "U012AB3CD",
+		Command:     "/backup run postgres-prod",
+		ResponseURL: responseServer.URL,
+		Thread:      &ThreadInfo{Channel: "C0FAILURES", Timestamp: "1700000000.000100"},
+	}
+	require.NoError(t, service.NotifyStarted(context.Background(), run))
+	require.NoError(t, service.NotifyStarted(context.Background(), run.withRoute(config.NotificationRoute{ChannelID: "C0CRITICAL"})))
+
+	received := requests()
+	var methods []string
+	for _, request := range received {
+		methods = append(methods, request.method)
+	}
+	assert.Equal(t, []string{"chat.postMessage", "chat.getPermalink", "chat.postMessage", "chat.postMessage"}, methods, "the trigger is linked once")
+	assert.Contains(t, received[0].form["attachments"], "with `/backup run postgres-prod`")
+	assert.Equal(t, "C0FAILURES", received[2].form["channel"])
+	assert.Equal(t, "1700000000.000100", received[2].form["thread_ts"])
+	assert.Contains(t, received[2].form["text"], "https://example.slack.com/archives/C1234567890/p1700000000000200")
+
+	response := <-responses
+	assert.Equal(t, slack.ResponseTypeInChannel, response.ResponseType)
+	assert.Contains(t, response.Text, "<@U012AB3CD> started a backup of postgres-prod")
+}
+
+func TestSlackService_SendBackupResult_RetryButton(t *testing.T) {
+	_ = logger.InitLogger("info")
+	service, requests := newSlackAPI(t)
+	service.config.Strategies = []config.StrategyConfig{{Name: "postgres-prod"}}
+	thread := &ThreadInfo{Channel: "C1234567890", Timestamp: "1700000000.000100"}
+	results := []*backup.BackupResult{{Strategy: "postgres-prod", Error: assert.AnError}}
+
+	require.NoError(t, service.SendBackupResult(context.Background(), thread, results, false))
+	assert.NotContains(t, requests()[0].form["blocks"], retryActionID, "buttons need Slack commands")
+
+	service.config.Global.SlackCommands = config.SlackCommandsConfig{SigningSecret: testSigningSecret, AllowedUsers: []string{"U012AB3CD"}}
+	require.NoError(t, service.SendBackupResult(context.Background(), thread, results, false))
+	var blocks slack.Blocks
+	require.NoError(t, json.Unmarshal([]byte(requests()[2].form["blocks"]), &blocks))
+	var buttons []*slack.ButtonBlockElement
+	for _, block := range blocks.BlockSet {
+		if actions, ok := block.(*slack.ActionBlock); ok {
+			buttons = append(buttons, actions.Elements.ElementSet[0].(*slack.ButtonBlockElement))
+		}
+	}
+	require.Len(t, buttons, 1)
+	button := buttons[0]
+	assert.Equal(t, retryActionID, button.ActionID)
+	assert.Equal(t, "postgres-prod", button.Value)
+}
@@ -2,7 +2,12 @@ package notification
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -10,7 +15,9 @@ import (
 	"easy-backup/internal/config"
 	"easy-backup/internal/logger"
 
+	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewSlackService(t *testing.T) {
@@ -505,3 +512,153 @@ func TestSlackService_SendDatabaseOutput(t *testing.T) {
 		assert.NoError(t, err, "Should handle nil thread gracefully")
 	})
 }
+
+// slackAPIRequest is a request received by newSlackAPI
+type slackAPIRequest struct {
+	method string
+	form   map[string]string
+}
+
+// newSlackAPI serves the Slack Web API methods used by SlackService and records the requests
+func newSlackAPI(t *testing.T) (*SlackService, func() []slackAPIRequest) {
+	var mu sync.Mutex
+	var requests []slackAPIRequest
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		request := slackAPIRequest{method: strings.TrimPrefix(r.URL.Path, "/"), form: map[string]string{}}
+		for key := range r.PostForm {
+			request.form[key] = r.PostForm.Get(key)
+		}
+		mu.Lock()
+		requests = append(requests, request)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"ok": true, "channel": %q, "ts": "1700000000.000200", "upload_url": %q, "file_id": "F0123456789", "files": [{"id": "F0123456789"}], "permalink": "https://example.slack.com/archives/C1234567890/p1700000000000200", "users": ["U0GROUPMEMBER"]}`,
+			request.form["channel"], server.URL+"/upload")
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := &config.Config{Global: config.GlobalConfig{Slack: config.SlackConfig{ChannelID: "C1234567890"}}}
+	service := NewSlackService(cfg)
+	service.client = slack.New("fake-test-token", slack.OptionAPIURL(server.URL+"/"))
+	return service, func() []slackAPIRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]slackAPIRequest(nil), requests...)
+	}
+}
+
+func TestSlackService_SendBackupResult_BlockKit(t *testing.T) {
+	_ = logger.InitLogger("info")
+	service, requests := newSlackAPI(t)
+	ctx := context.Background()
+
+	thread, err := service.SendBackupStarted(ctx, []string{"postgres-main"}, config.SlackConfig{ChannelID: "C1234567890"})
+	require.NoError(t, err)
+	assert.Equal(t, &ThreadInfo{Channel: "C1234567890", Timestamp: "1700000000.000200"}, thread)
+
+	result := &backup.BackupResult{
+		Strategy:    "postgres-main",
+		Success:     true,
+		Size:        2048,
+		Duration:    90 * time.Second,
+		S3Locations: []string{"s3://backups/postgres-main/backup.sql.gz"},
+	}
+	require.NoError(t, service.SendBackupResult(ctx, thread, []*backup.BackupResult{result}, true))
+
+	received := requests()
+	require.Len(t, received, 3)
+	assert.Equal(t, "chat.postMessage", received[0].method)
+	assert.Contains(t, received[0].form["attachments"], slackColorRunning)
+
+	reply := received[1]
+	assert.Equal(t, "chat.postMessage", reply.method)
+	assert.Equal(t, "1700000000.000200", reply.form["thread_ts"])
+	assert.Contains(t, reply.form["blocks"], `"type":"header"`)
+	assert.Contains(t, reply.form["blocks"], `*Size*\n2.0 KB`)
+	assert.Contains(t, reply.form["blocks"], `*S3 location*\ns3://backups/postgres-main/backup.sql.gz`)
+	assert.NotContains(t, reply.form["blocks"], "**")
+
+	update := received[2]
+	assert.Equal(t, "chat.update", update.method)
+	assert.Equal(t, "1700000000.000200", update.form["ts"])
+	var attachments []slack.Attachment
+	require.NoError(t, json.Unmarshal([]byte(update.form["attachments"]), &attachments))
+	require.Len(t, attachments, 1)
+	assert.Equal(t, slackColorSuccess, attachments[0].Color)
+	assert.Contains(t, update.form["attachments"], "Database Backup Completed Successfully")
+}
+
+func TestSlackService_SendDetailedError_UploadsCommandLog(t *testing.T) {
+	_ = logger.InitLogger("info")
+	service, requests := newSlackAPI(t)
+	thread := &ThreadInfo{Channel: "C1234567890", Timestamp: "1700000000.000100", mention: "@here"}
+
+	result := &backup.BackupResult{
+		Strategy:    "postgres-main",
+		Error:       fmt.Errorf("pg_dump exited with status 1: <connection refused>"),
+		CommandLogs: []string{"pg_dump: error: connection refused", "pg_dump: aborting"},
+	}
+	require.NoError(t, service.SendDetailedError(context.Background(), thread, "postgres-main", result))
+
+	received := requests()
+	require.NotEmpty(t, received)
+	message := received[0]
+	assert.Equal(t, "chat.postMessage", message.method)
+	assert.True(t, strings.HasPrefix(message.form["text"], "<!here> "))
+	var blocks slack.Blocks
+	require.NoError(t, json.Unmarshal([]byte(message.form["blocks"]), &blocks))
+	assert.Contains(t, blocks.BlockSet[2].(*slack.SectionBlock).Text.Text, "&lt;connection refused&gt;")
+
+	var methods []string
+	for _, request := range received[1:] {
+		methods = append(methods, request.method)
+		if request.method == "files.getUploadURLExternal" {
+			assert.Equal(t, "postgres-main-command-log.txt", request.form["filename"])
+		}
+		if request.method == "files.completeUploadExternal" {
+			assert.Equal(t, "1700000000.000100", request.form["thread_ts"])
+		}
+	}
+	assert.Equal(t, []string{"files.getUploadURLExternal", "upload", "files.completeUploadExternal"}, methods, "the command log is uploaded as a file")
+}
+
+func TestStatusMessage(t *testing.T) {
+	failed := &backup.BackupResult{Strategy: "mysql-main", Error: fmt.Errorf("access denied"), Duration: time.Second}
+	succeeded := &backup.BackupResult{Strategy: "postgres-main", Success: true, Size: 1024, Duration: time.Minute}
+
+	message := statusMessage([]*backup.BackupResult{failed}, false)
+	assert.Equal(t, slackColorFailure, message.Color)
+	assert.Equal(t, "❌ Database Backup Failed: mysql-main", message.Text)
+
+	message = statusMessage([]*backup.BackupResult{succeeded, failed}, false)
+	assert.Equal(t, slackColorFailure, message.Color)
+	fields := message.Blocks[1].(*slack.SectionBlock).Fields
+	assert.Equal(t, "*Results*\n1 successful, 1 failed (2 total)", fields[0].Text)
+
+	message = statusMessage([]*backup.BackupResult{succeeded}, true)
+	assert.Equal(t, slackColorSuccess, message.Color)
+}
+
+func TestFactBlocks(t *testing.T) {
+	var facts []cardFact
+	for i := 0; i < 12; i++ {
+		facts = append(facts, cardFact{Name: fmt.Sprintf("Fact %d", i), Value: strings.Repeat("x", 3000)})
+	}
+
+	blocks := factBlocks(facts)
+	require.Len(t, blocks, 2, "sections have at most ten fields")
+	assert.Len(t, blocks[0].(*slack.SectionBlock).Fields, 10)
+	assert.Len(t, blocks[1].(*slack.SectionBlock).Fields, 2)
+	assert.Len(t, blocks[0].(*slack.SectionBlock).Fields[0].Text, slackMaxFieldText)
+}
+
+func TestCodeBlock(t *testing.T) {
+	assert.Equal(t, "```a &lt; b'''```", codeBlock("a < b```"))
+
+	long := codeBlock(strings.Repeat("x", 5000) + "end")
+	assert.LessOrEqual(t, len(long), slackMaxSectionText)
+	assert.True(t, strings.HasSuffix(long, "end```"), "the end of long logs is kept")
+}
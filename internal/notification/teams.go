@@ -0,0 +1,80 @@
+package notification
+
+import (
+	"strings"
+
+	"easy-backup/internal/config"
+)
+
+// NewTeamsNotifier creates a notifier posting Adaptive Cards to Microsoft Teams incoming
+// webhooks or workflows
+func NewTeamsNotifier(cfg *config.Config) *ChatNotifier {
+	return newChatNotifier(cfg, "teams", func(strategy config.StrategyConfig) string {
+		return strategy.Teams.WebhookURL
+	}, cfg.Global.Teams.WebhookURL, renderTeamsCard)
+}
+
+// renderTeamsCard builds a Teams message with an Adaptive Card attachment
+func renderTeamsCard(c card) interface{} {
+	color := map[cardStatus]string{cardRunning: "Accent", cardSuccess: "Good", cardFailure: "Attention", cardWarning: "Warning"}[c.Status]
+	body := []map[string]interface{}{{
+		"type":   "TextBlock",
+		"text":   c.Title,
+		"weight": "Bolder",
+		"size":   "Medium",
+		"color":  color,
+		"wrap":   true,
+	}}
+	if len(c.Facts) > 0 {
+		body = append(body, teamsFactSet(c.Facts))
+	}
+	for _, section := range c.Sections {
+		body = append(body, map[string]interface{}{
+			"type":      "TextBlock",
+			"text":      section.Heading,
+			"weight":    "Bolder",
+			"separator": true,
+			"wrap":      true,
+		})
+		if len(section.Facts) > 0 {
+			body = append(body, teamsFactSet(section.Facts))
+		}
+	}
+	if len(c.Log) > 0 {
+		body = append(body, map[string]interface{}{
+			"type":      "TextBlock",
+			"text":      "Command log",
+			"weight":    "Bolder",
+			"separator": true,
+		}, map[string]interface{}{
+			"type":     "TextBlock",
+			"text":     strings.Join(c.Log, "\n\n"),
+			"fontType": "Monospace",
+			"size":     "Small",
+			"isSubtle": true,
+			"wrap":     true,
+		})
+	}
+
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]interface{}{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"msteams": map[string]string{"width": "Full"},
+				"body":    body,
+			},
+		}},
+	}
+}
+
+func teamsFactSet(facts []cardFact) map[string]interface{} {
+	var items []map[string]string
+	for _, fact := range facts {
+		items = append(items, map[string]string{"title": fact.Name, "value": fact.Value})
+	}
+	return map[string]interface{}{"type": "FactSet", "facts": items}
+}
@@ -0,0 +1,310 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"easy-backup/internal/backup"
+	"easy-backup/internal/config"
+	"easy-backup/internal/logger"
+)
+
+const (
+	// logTailLines is the number of command log lines sent with failed backups
+	logTailLines = 20
+	// logLineLimit truncates long command log lines, e.g. dumped SQL statements
+	logLineLimit = 500
+)
+
+// WebhookNotifier POSTs backup events as JSON to an HTTP endpoint
+type WebhookNotifier struct {
+	config     config.WebhookConfig
+	logger     *logrus.Logger
+	httpClient *http.Client
+}
+
+// WebhookEvent is the JSON body of a webhook request
+type WebhookEvent struct {
+	Type       string    `json:"type"`
+	RunID      string    `json:"run_id"`
+	Timestamp  time.Time `json:"timestamp"`
+	Strategies []string  `json:"strategies"`
+	Strategy   string    `json:"strategy,omitempty"`
+	Message    string    `json:"message,omitempty"`
+	Success    *bool     `json:"success,omitempty"`
+	// LastSuccess is the time of the last successful backup of a stale strategy
+	LastSuccess *time.Time      `json:"last_success,omitempty"`
+	Results     []WebhookResult `json:"results,omitempty"`
+	Report      *WebhookReport  `json:"report,omitempty"`
+}
+
+// WebhookReport is the scheduled report of a report event
+type WebhookReport struct {
+	Since      time.Time               `json:"since"`
+	Until      time.Time               `json:"until"`
+	NotRun     []string                `json:"not_run"`
+	Strategies []WebhookStrategyReport `json:"strategies"`
+}
+
+// WebhookStrategyReport summarizes the backups of one strategy in a report event
+type WebhookStrategyReport struct {
+	Strategy      string     `json:"strategy"`
+	LastSuccess   *time.Time `json:"last_success,omitempty"`
+	Runs          int        `json:"runs"`
+	Successful    int        `json:"successful"`
+	SuccessRate   *float64   `json:"success_rate,omitempty"` // Absent when no backup finished
+	Uploads       int        `json:"uploads"`
+	StoredBytes   *int64     `json:"stored_bytes,omitempty"` // Absent when S3 could not be listed
+	StoredObjects *int       `json:"stored_objects,omitempty"`
+	Growth        *float64   `json:"growth,omitempty"` // Relative change from the first to the latest backup
+}
+
+// WebhookResult describes the result of one strategy in a webhook event
+type WebhookResult struct {
+	Strategy        string   `json:"strategy"`
+	Status          string   `json:"status"`
+	Size            int64    `json:"size"`
+	DurationSeconds float64  `json:"duration_seconds"`
+	S3Locations     []string `json:"s3_locations,omitempty"`
+	Error           string   `json:"error,omitempty"`
+	CommandLogTail  []string `json:"command_log_tail,omitempty"`
+}
+
+// NewWebhookNotifier creates a notifier for a configured webhook
+func NewWebhookNotifier(cfg config.WebhookConfig) *WebhookNotifier {
+	timeout, err := time.ParseDuration(cfg.Timeout)
+	if err != nil || timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &WebhookNotifier{
+		config:     cfg,
+		logger:     logger.GetLogger(),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// NotifyStarted sends a started event
+func (wn *WebhookNotifier) NotifyStarted(ctx context.Context, run *Run) error {
+	return wn.send(ctx, wn.newEvent(config.EventStarted, run))
+}
+
+// NotifyProgress sends a progress event
+func (wn *WebhookNotifier) NotifyProgress(ctx context.Context, run *Run, strategy, message string) error {
+	event := wn.newEvent(config.EventProgress, run)
+	event.Strategy = strategy
+	event.Message = message
+	return wn.send(ctx, event)
+}
+
+// NotifyOutput sends the errors and warnings printed by the dump tools
+func (wn *WebhookNotifier) NotifyOutput(ctx context.Context, run *Run, strategy, output string) error {
+	event := wn.newEvent(config.EventOutput, run)
+	event.Strategy = strategy
+	event.Message = output
+	return wn.send(ctx, event)
+}
+
+// NotifyRetry sends a retry event
+func (wn *WebhookNotifier) NotifyRetry(ctx context.Context, run *Run, strategy, message string) error {
+	event := wn.newEvent(config.EventRetry, run)
+	event.Strategy = strategy
+	event.Message = message
+	return wn.send(ctx, event)
+}
+
+// NotifyResult sends a result event with the outcome of every strategy of the run
+func (wn *WebhookNotifier) NotifyResult(ctx context.Context, run *Run, results []*backup.BackupResult, overallSuccess bool) error {
+	event := wn.newEvent(config.EventResult, run)
+	event.Success = &overallSuccess
+	for _, result := range results {
+		event.Results = append(event.Results, newWebhookResult(result))
+	}
+	return wn.send(ctx, event)
+}
+
+// NotifyDetailedError sends an error event with the details of a failed backup
+func (wn *WebhookNotifier) NotifyDetailedError(ctx context.Context, run *Run, strategy string, result *backup.BackupResult) error {
+	event := wn.newEvent(config.EventError, run)
+	event.Strategy = strategy
+	if result != nil {
+		event.Results = []WebhookResult{newWebhookResult(result)}
+	}
+	return wn.send(ctx, event)
+}
+
+// NotifyStale sends a stale event for a strategy without a recent successful backup
+func (wn *WebhookNotifier) NotifyStale(ctx context.Context, run *Run, strategy string, lastSuccess time.Time) error {
+	event := wn.newEvent(config.EventStale, run)
+	event.Strategy = strategy
+	event.Message = fmt.Sprintf("No successful backup of %s, last successful backup: %s", strategy, formatLastSuccess(lastSuccess))
+	if !lastSuccess.IsZero() {
+		lastSuccess = lastSuccess.UTC()
+		event.LastSuccess = &lastSuccess
+	}
+	return wn.send(ctx, event)
+}
+
+// NotifyReport sends a report event with the summary of every strategy
+func (wn *WebhookNotifier) NotifyReport(ctx context.Context, run *Run, report *Report) error {
+	event := wn.newEvent(config.EventReport, run)
+	event.Report = newWebhookReport(report)
+	return wn.send(ctx, event)
+}
+
+func (wn *WebhookNotifier) newEvent(eventType string, run *Run) *WebhookEvent {
+	return &WebhookEvent{
+		Type:       eventType,
+		RunID:      run.ID,
+		Timestamp:  time.Now().UTC(),
+		Strategies: run.Strategies,
+	}
+}
+
+// newWebhookReport converts a report, omitting the values that are unknown
+func newWebhookReport(report *Report) *WebhookReport {
+	webhookReport := &WebhookReport{
+		Since:  report.Since.UTC(),
+		Until:  report.Until.UTC(),
+		NotRun: report.NotRun(),
+	}
+	if webhookReport.NotRun == nil {
+		webhookReport.NotRun = []string{}
+	}
+	for _, strategy := range report.Strategies {
+		strategyReport := WebhookStrategyReport{
+			Strategy:   strategy.Strategy,
+			Runs:       strategy.Runs,
+			Successful: strategy.Successful,
+			Uploads:    strategy.Uploads,
+		}
+		if !strategy.LastSuccess.IsZero() {
+			lastSuccess := strategy.LastSuccess.UTC()
+			strategyReport.LastSuccess = &lastSuccess
+		}
+		if rate, ok := strategy.SuccessRate(); ok {
+			strategyReport.SuccessRate = &rate
+		}
+		if strategy.StorageListed {
+			strategyReport.StoredBytes = &strategy.StoredBytes
+			strategyReport.StoredObjects = &strategy.StoredObjects
+		}
+		if growth, ok := strategy.Growth(); ok {
+			strategyReport.Growth = &growth
+		}
+		webhookReport.Strategies = append(webhookReport.Strategies, strategyReport)
+	}
+	return webhookReport
+}
+
+// newWebhookResult converts a backup result, including the tail of the command log of
+// failed backups
+func newWebhookResult(result *backup.BackupResult) WebhookResult {
+	webhookResult := WebhookResult{
+		Strategy:        result.Strategy,
+		Status:          "success",
+		Size:            result.Size,
+		DurationSeconds: result.Duration.Seconds(),
+		S3Locations:     result.S3Locations,
+	}
+	if result.Success {
+		return webhookResult
+	}
+
+	webhookResult.Status = "failed"
+	if result.Error != nil {
+		webhookResult.Error = result.Error.Error()
+	}
+	webhookResult.CommandLogTail = commandLogTail(result.CommandLogs)
+	return webhookResult
+}
+
+// send POSTs an event if the webhook subscribed to its type. The body is signed with
+// HMAC-SHA256 when a secret is configured.
+func (wn *WebhookNotifier) send(ctx context.Context, event *WebhookEvent) error {
+	if !slices.Contains(wn.config.Events, event.Type) {
+		return nil
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("webhook '%s': failed to encode event: %w", wn.config.Name, err)
+	}
+
+	headers := map[string]string{}
+	for name, value := range wn.config.Headers {
+		headers[name] = value
+	}
+	headers["X-Easy-Backup-Event"] = event.Type
+	if wn.config.Secret != "" {
+		headers["X-Easy-Backup-Signature"] = "sha256=" + Sign(wn.config.Secret, body)
+	}
+	if err := postJSON(ctx, wn.httpClient, wn.config.URL, body, headers); err != nil {
+		return fmt.Errorf("webhook '%s': %w", wn.config.Name, err)
+	}
+
+	wn.logger.WithFields(logrus.Fields{
+		"webhook": wn.config.Name,
+		"event":   event.Type,
+	}).Debug("Webhook event sent")
+	return nil
+}
+
+// commandLogTail returns the last lines of a command log, truncating long lines
+func commandLogTail(logs []string) []string {
+	if len(logs) > logTailLines {
+		logs = logs[len(logs)-logTailLines:]
+	}
+	var tail []string
+	for _, line := range logs {
+		if len(line) > logLineLimit {
+			line = line[:logLineLimit] + "... (truncated)"
+		}
+		tail = append(tail, line)
+	}
+	return tail
+}
+
+// postJSON POSTs a JSON body and fails on responses other than 2xx
+func postJSON(ctx context.Context, client *http.Client, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "easy-backup")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of a webhook body, as sent in the
+// X-Easy-Backup-Signature header
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,117 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"easy-backup/internal/backup"
+	"easy-backup/internal/config"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	var events []WebhookEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer incident-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "sha256="+Sign("webhook-secret", body), r.Header.Get("X-Easy-Backup-Signature"))
+
+		var event WebhookEvent
+		require.NoError(t, json.Unmarshal(body, &event))
+		assert.Equal(t, event.Type, r.Header.Get("X-Easy-Backup-Event"))
+		events = append(events, event)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(config.WebhookConfig{
+		Name:    "incidents",
+		URL:     server.URL,
+		Headers: map[string]string{"Authorization": "Bearer incident-token"},
+		Secret:  "webhook-secret",
+		Events:  []string{config.EventStarted, config.EventResult},
+		Timeout: "5s",
+	})
+	run := NewRun([]string{"postgres-main"}, config.SlackConfig{})
+	ctx := context.Background()
+
+	require.NoError(t, notifier.NotifyStarted(ctx, run))
+	require.NoError(t, notifier.NotifyProgress(ctx, run, "postgres-main", "Uploading to S3..."))
+
+	logs := make([]string, 30)
+	for i := range logs {
+		logs[i] = fmt.Sprintf("line %d", i+1)
+	}
+	result := &backup.BackupResult{
+		Strategy:    "postgres-main",
+		Success:     false,
+		Error:       errors.New("pg_dump exited with status 1"),
+		Size:        2048,
+		Duration:    90 * time.Second,
+		CommandLogs: logs,
+	}
+	require.NoError(t, notifier.NotifyResult(ctx, run, []*backup.BackupResult{result}, false))
+
+	// Progress events were not subscribed to
+	require.Len(t, events, 2)
+	assert.Equal(t, config.EventStarted, events[0].Type)
+	assert.Equal(t, run.ID, events[0].RunID)
+	assert.Equal(t, []string{"postgres-main"}, events[0].Strategies)
+
+	resultEvent := events[1]
+	assert.Equal(t, config.EventResult, resultEvent.Type)
+	require.NotNil(t, resultEvent.Success)
+	assert.False(t, *resultEvent.Success)
+	require.Len(t, resultEvent.Results, 1)
+	assert.Equal(t, "failed", resultEvent.Results[0].Status)
+	assert.Equal(t, int64(2048), resultEvent.Results[0].Size)
+	assert.Equal(t, 90.0, resultEvent.Results[0].DurationSeconds)
+	assert.Equal(t, "pg_dump exited with status 1", resultEvent.Results[0].Error)
+	assert.Equal(t, logs[10:], resultEvent.Results[0].CommandLogTail)
+}
+
+func TestWebhookNotifier_SuccessfulResult(t *testing.T) {
+	var event WebhookEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("X-Easy-Backup-Signature"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(config.WebhookConfig{Name: "incidents", URL: server.URL, Events: []string{config.EventResult}})
+	result := &backup.BackupResult{
+		Strategy:    "postgres-main",
+		Success:     true,
+		Size:        4096,
+		S3Locations: []string{"s3://backups/postgres-main/postgres-main-20240101-020000.sql.gz"},
+		CommandLogs: []string{"pg_dump finished"},
+	}
+	require.NoError(t, notifier.NotifyResult(context.Background(), NewRun([]string{"postgres-main"}, config.SlackConfig{}), []*backup.BackupResult{result}, true))
+
+	require.Len(t, event.Results, 1)
+	assert.Equal(t, "success", event.Results[0].Status)
+	assert.Equal(t, result.S3Locations, event.Results[0].S3Locations)
+	assert.Empty(t, event.Results[0].CommandLogTail)
+}
+
+func TestWebhookNotifier_UnexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(config.WebhookConfig{Name: "incidents", URL: server.URL, Events: []string{config.EventStarted}})
+	err := notifier.NotifyStarted(context.Background(), NewRun([]string{"postgres-main"}, config.SlackConfig{}))
+	assert.EqualError(t, err, "webhook 'incidents': unexpected status 401 Unauthorized: invalid signature")
+}
@@ -0,0 +1,126 @@
+package scheduler
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"easy-backup/internal/backup"
+	"easy-backup/internal/config"
+	"easy-backup/internal/notification"
+	"easy-backup/internal/storage"
+)
+
+// runRecord is a finished backup kept in the run history for reports
+type runRecord struct {
+	strategy string
+	success  bool
+	size     int64
+	finished time.Time
+}
+
+// recordRun adds a finished backup to the run history and drops the backups older than the
+// longest report period. Callers hold ss.mu.
+func (ss *SchedulerService) recordRun(strategyName string, success bool, size int64) {
+	if ss.reportPeriod == 0 {
+		return
+	}
+
+	now := time.Now()
+	ss.history = append(ss.history, runRecord{strategy: strategyName, success: success, size: size, finished: now})
+	ss.history = slices.DeleteFunc(ss.history, func(record runRecord) bool {
+		return record.finished.Before(now.Add(-ss.reportPeriod))
+	})
+}
+
+// recordFailure adds a failed backup to the run history
+func (ss *SchedulerService) recordFailure(strategyName string) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.recordRun(strategyName, false, 0)
+}
+
+// sendReport sends the report of the period ending now to the notifiers
+func (ss *SchedulerService) sendReport(period time.Duration) {
+	until := time.Now()
+	since := until.Add(-period)
+
+	var strategyNames []string
+	objects := map[string][]storage.BackupObject{}
+	for _, strategy := range ss.config.Strategies {
+		strategyNames = append(strategyNames, strategy.Name)
+		list, err := ss.s3Service.ListBackups(ss.ctx, strategy.Name)
+		if err != nil {
+			ss.logger.WithError(err).WithField("strategy", strategy.Name).Warn("Failed to list stored backups for the report")
+			continue
+		}
+		objects[strategy.Name] = list
+	}
+
+	ss.mu.Lock()
+	report := buildReport(ss.config.Strategies, ss.history, ss.lastSuccess, objects, since, until)
+	ss.mu.Unlock()
+
+	run := notification.NewRun(strategyNames, ss.config.Global.Slack)
+	if err := ss.notifier.NotifyReport(ss.ctx, run, report); err != nil {
+		ss.logger.WithError(err).Warn("Failed to send backup report")
+		return
+	}
+
+	ss.logger.WithFields(logrus.Fields{
+		"period":     period,
+		"strategies": len(report.Strategies),
+		"not_run":    report.NotRun(),
+	}).Info("Sent backup report")
+}
+
+// buildReport summarizes the run history and the S3 objects of every strategy over a
+// period. Strategies missing from objects could not be listed. The last success falls back
+// to the newest stored object when no backup succeeded since the start of the service.
+func buildReport(strategies []config.StrategyConfig, history []runRecord, lastSuccess map[string]time.Time, objects map[string][]storage.BackupObject, since, until time.Time) *notification.Report {
+	report := &notification.Report{Since: since, Until: until}
+	for _, strategy := range strategies {
+		strategyReport := notification.StrategyReport{
+			Strategy:    strategy.Name,
+			LastSuccess: lastSuccess[strategy.Name],
+		}
+
+		for _, record := range history {
+			if record.strategy != strategy.Name || record.finished.Before(since) || record.finished.After(until) {
+				continue
+			}
+			strategyReport.Runs++
+			if !record.success {
+				continue
+			}
+			if strategyReport.Successful == 0 {
+				strategyReport.FirstSize = record.size
+			}
+			strategyReport.Successful++
+			strategyReport.LatestSize = record.size
+		}
+
+		list, listed := objects[strategy.Name]
+		strategyReport.StorageListed = listed
+		var newest time.Time
+		for _, object := range list {
+			strategyReport.StoredBytes += object.Size
+			strategyReport.StoredObjects++
+			if object.LastModified.After(newest) {
+				newest = object.LastModified
+			}
+			inPeriod := !object.LastModified.Before(since) && !object.LastModified.After(until)
+			if inPeriod && !strings.HasSuffix(object.Name, backup.ManifestSuffix) {
+				strategyReport.Uploads++
+			}
+		}
+		if strategyReport.LastSuccess.IsZero() {
+			strategyReport.LastSuccess = newest
+		}
+
+		report.Strategies = append(report.Strategies, strategyReport)
+	}
+	return report
+}
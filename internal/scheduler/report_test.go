@@ -0,0 +1,74 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"easy-backup/internal/config"
+	"easy-backup/internal/storage"
+)
+
+func TestBuildReport(t *testing.T) {
+	until := time.Date(2024, 3, 11, 8, 0, 0, 0, time.UTC)
+	since := until.Add(-24 * time.Hour)
+	strategies := []config.StrategyConfig{{Name: "postgres-main"}, {Name: "mysql-main"}, {Name: "redis-cache"}}
+
+	history := []runRecord{
+		{strategy: "postgres-main", success: true, size: 100, finished: since.Add(-time.Hour)}, // Before the period
+		{strategy: "postgres-main", success: true, size: 200, finished: since.Add(2 * time.Hour)},
+		{strategy: "postgres-main", success: false, finished: since.Add(8 * time.Hour)},
+		{strategy: "postgres-main", success: true, size: 250, finished: since.Add(14 * time.Hour)},
+	}
+	lastSuccess := map[string]time.Time{"postgres-main": since.Add(14 * time.Hour)}
+	objects := map[string][]storage.BackupObject{
+		"postgres-main": {
+			{Name: "postgres-main-1.sql.gz", Size: 200, LastModified: since.Add(2 * time.Hour)},
+			{Name: "postgres-main-1.sql.gz.manifest.json", Size: 1, LastModified: since.Add(2 * time.Hour)},
+			{Name: "postgres-main-2.sql.gz", Size: 250, LastModified: since.Add(14 * time.Hour)},
+		},
+		"mysql-main": {
+			{Name: "mysql-main-1.sql.gz", Size: 500, LastModified: since.Add(-48 * time.Hour)},
+			{Name: "mysql-main-2.sql.gz", Size: 600, LastModified: since.Add(3 * time.Hour)},
+		},
+	}
+
+	report := buildReport(strategies, history, lastSuccess, objects, since, until)
+	require.Len(t, report.Strategies, 3)
+
+	postgres := report.Strategies[0]
+	assert.Equal(t, 3, postgres.Runs)
+	assert.Equal(t, 2, postgres.Successful)
+	assert.Equal(t, int64(200), postgres.FirstSize)
+	assert.Equal(t, int64(250), postgres.LatestSize)
+	assert.Equal(t, int64(451), postgres.StoredBytes)
+	assert.Equal(t, 3, postgres.StoredObjects)
+	assert.Equal(t, 2, postgres.Uploads, "manifests are not counted as backups")
+	assert.Equal(t, since.Add(14*time.Hour), postgres.LastSuccess)
+
+	mysql := report.Strategies[1]
+	assert.Equal(t, 0, mysql.Runs, "no backups since the start of the service")
+	assert.Equal(t, 1, mysql.Uploads)
+	assert.False(t, mysql.NotRun())
+	assert.Equal(t, since.Add(3*time.Hour), mysql.LastSuccess, "falls back to the newest stored backup")
+
+	redis := report.Strategies[2]
+	assert.True(t, redis.NotRun())
+	assert.False(t, redis.StorageListed)
+	assert.Equal(t, []string{"redis-cache"}, report.NotRun())
+}
+
+func TestRecordRun(t *testing.T) {
+	ss := &SchedulerService{reportPeriod: time.Hour}
+	ss.history = []runRecord{{strategy: "postgres-main", success: true, finished: time.Now().Add(-2 * time.Hour)}}
+
+	ss.recordFailure("postgres-main")
+	require.Len(t, ss.history, 1, "records older than the longest report period are dropped")
+	assert.False(t, ss.history[0].success)
+
+	ss = &SchedulerService{}
+	ss.recordFailure("postgres-main")
+	assert.Empty(t, ss.history, "no history without reports")
+}
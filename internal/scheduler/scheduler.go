@@ -2,7 +2,11 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/robfig/cron/v3"
@@ -10,6 +14,7 @@ import (
 
 	"easy-backup/internal/backup"
 	"easy-backup/internal/config"
+	"easy-backup/internal/hooks"
 	"easy-backup/internal/logger"
 	"easy-backup/internal/monitoring"
 	"easy-backup/internal/notification"
@@ -23,19 +28,31 @@ type SchedulerService struct {
 	cron              *cron.Cron
 	backupService     *backup.BackupService
 	s3Service         *storage.S3Service
-	slackService      *notification.SlackService
+	notifier          notification.Notifier
 	monitoringService *monitoring.MonitoringService
+	hookService       *hooks.HookService
 	semaphore         chan struct{}
 	ctx               context.Context
 	cancel            context.CancelFunc
+
+	startedAt     time.Time
+	mu            sync.Mutex
+	lastSuccess   map[string]time.Time // Last successful backup by strategy
+	staleNotified map[string]bool      // Strategies already reported as stale since their last success
+	incrementalOK map[string]bool      // Strategies whose last incremental backup succeeded
+	history       []runRecord          // Finished backups within the longest report period
+	reportPeriod  time.Duration        // Longest period of the configured reports, zero without reports
 }
 
+// staleCheckInterval is how often strategies are checked against their stale_after
+const staleCheckInterval = 5 * time.Minute
+
 // NewSchedulerService creates a new scheduler service
 func NewSchedulerService(
 	cfg *config.Config,
 	backupService *backup.BackupService,
 	s3Service *storage.S3Service,
-	slackService *notification.SlackService,
+	notifier notification.Notifier,
 	monitoringService *monitoring.MonitoringService,
 ) *SchedulerService {
 	// Parse timezone
@@ -50,17 +67,31 @@ func NewSchedulerService(
 
 	ctx, cancel := context.WithCancel(context.Background())
 
+	// Keep the run history for the longest report
+	var reportPeriod time.Duration
+	for _, report := range cfg.Global.Notifications.Reports {
+		if period, err := config.ParseDuration(report.Period); err == nil {
+			reportPeriod = max(reportPeriod, period)
+		}
+	}
+
 	return &SchedulerService{
 		config:            cfg,
 		logger:            logger.GetLogger(),
 		cron:              cronScheduler,
 		backupService:     backupService,
 		s3Service:         s3Service,
-		slackService:      slackService,
+		notifier:          notifier,
 		monitoringService: monitoringService,
+		hookService:       hooks.NewHookService(),
 		semaphore:         make(chan struct{}, cfg.Global.MaxParallel),
 		ctx:               ctx,
 		cancel:            cancel,
+		startedAt:         time.Now(),
+		lastSuccess:       map[string]time.Time{},
+		staleNotified:     map[string]bool{},
+		incrementalOK:     map[string]bool{},
+		reportPeriod:      reportPeriod,
 	}
 }
 
@@ -78,7 +109,7 @@ func (ss *SchedulerService) Start() error {
 		// Capture strategy in closure
 		strategyConfig := strategy
 		_, err = ss.cron.AddFunc(cronExpr, func() {
-			ss.executeBackupJob(strategyConfig)
+			ss.executeBackupJob(strategyConfig, nil)
 		})
 		if err != nil {
 			return fmt.Errorf("failed to schedule strategy %s: %w", strategy.Name, err)
@@ -91,6 +122,85 @@ func (ss *SchedulerService) Start() error {
 		}).Info("Scheduled backup strategy")
 	}
 
+	// Schedule incremental binlog backups
+	for _, strategy := range ss.config.Strategies {
+		if !strategy.MySQL.Binlog.Enabled {
+			continue
+		}
+
+		cronExpr, err := ss.convertToCronExpression(strategy.MySQL.Binlog.Schedule)
+		if err != nil {
+			return fmt.Errorf("invalid binlog schedule for strategy %s: %w", strategy.Name, err)
+		}
+
+		strategyConfig := strategy
+		_, err = ss.cron.AddFunc(cronExpr, func() {
+			ss.executeIncrementalJob(strategyConfig)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to schedule incremental backups for strategy %s: %w", strategy.Name, err)
+		}
+
+		ss.logger.WithFields(logrus.Fields{
+			"strategy": strategy.Name,
+			"schedule": strategy.MySQL.Binlog.Schedule,
+			"cron":     cronExpr,
+		}).Info("Scheduled incremental binlog backups")
+	}
+
+	// Schedule notification digests
+	for _, digester := range notification.Digesters(ss.notifier) {
+		cronExpr, err := ss.convertToCronExpression(digester.DigestSchedule())
+		if err != nil {
+			return fmt.Errorf("invalid digest schedule: %w", err)
+		}
+
+		digester := digester
+		_, err = ss.cron.AddFunc(cronExpr, func() {
+			if err := digester.SendDigest(ss.ctx); err != nil {
+				ss.logger.WithError(err).Warn("Failed to send notification digest")
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("failed to schedule notification digest: %w", err)
+		}
+
+		ss.logger.WithField("cron", cronExpr).Info("Scheduled notification digest")
+	}
+
+	// Schedule backup reports
+	for _, report := range ss.config.Global.Notifications.Reports {
+		cronExpr, err := ss.convertToCronExpression(report.Schedule)
+		if err != nil {
+			return fmt.Errorf("invalid report schedule: %w", err)
+		}
+		period, err := config.ParseDuration(report.Period)
+		if err != nil {
+			return fmt.Errorf("invalid report period: %w", err)
+		}
+
+		_, err = ss.cron.AddFunc(cronExpr, func() {
+			ss.sendReport(period)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to schedule backup report: %w", err)
+		}
+
+		ss.logger.WithFields(logrus.Fields{
+			"cron":   cronExpr,
+			"period": report.Period,
+		}).Info("Scheduled backup report")
+	}
+
+	// Check for strategies without a recent successful backup
+	for _, strategy := range ss.config.Strategies {
+		if strategy.StaleAfter != "" {
+			ss.cron.Schedule(cron.Every(staleCheckInterval), cron.FuncJob(ss.checkStaleBackups))
+			ss.logger.WithField("interval", staleCheckInterval).Info("Scheduled stale backup checks")
+			break
+		}
+	}
+
 	// Start the cron scheduler
 	ss.cron.Start()
 	ss.logger.Info("Backup scheduler started")
@@ -117,8 +227,9 @@ func (ss *SchedulerService) Stop() {
 	ss.logger.Info("Backup scheduler stopped")
 }
 
-// executeBackupJob executes a backup job for a specific strategy
-func (ss *SchedulerService) executeBackupJob(strategy config.StrategyConfig) {
+// executeBackupJob executes a backup job for a specific strategy, trigger is set for runs
+// started from Slack
+func (ss *SchedulerService) executeBackupJob(strategy config.StrategyConfig, trigger *notification.Trigger) {
 	// Acquire semaphore to limit parallel executions
 	select {
 	case ss.semaphore <- struct{}{}:
@@ -135,12 +246,22 @@ func (ss *SchedulerService) executeBackupJob(strategy config.StrategyConfig) {
 		LastRun: time.Now().UTC().Format(time.RFC3339),
 	})
 
-	// Send Slack notification
-	thread, err := ss.slackService.SendBackupStarted(ss.ctx, []string{strategy.Name}, strategy.Slack)
-	if err != nil {
+	// Announce the run
+	run := notification.NewRun([]string{strategy.Name}, strategy.Slack)
+	run.Trigger = trigger
+	if err := ss.notifier.NotifyStarted(ss.ctx, run); err != nil {
 		ss.logger.WithError(err).Warn("Failed to send backup started notification")
 	}
 
+	// Run pre-backup hooks, a failing hook aborts the backup
+	preHookLogs, err := ss.runPreBackupHooks(strategy)
+	if err != nil {
+		result := ss.hookFailureResult(strategy, preHookLogs)
+		ss.runCompletionHooks(strategy, result, nil, err)
+		ss.handleBackupFailure(strategy, err, result, run, false)
+		return
+	}
+
 	// Execute backup with retry
 	var result *backup.BackupResult
 	var lastErr error
@@ -151,20 +272,16 @@ func (ss *SchedulerService) executeBackupJob(strategy config.StrategyConfig) {
 				"strategy": strategy.Name,
 				"attempt":  attempt,
 			}).Info("Retrying backup")
-			if thread != nil {
-				retryMsg := fmt.Sprintf("Retrying backup (attempt %d/%d)", attempt, ss.config.Global.Retry.MaxAttempts)
-				if err := ss.slackService.SendBackupProgress(ss.ctx, thread, strategy.Name, retryMsg); err != nil {
-					ss.logger.WithError(err).Warn("Failed to send backup progress notification")
-				}
+			retryMsg := fmt.Sprintf("Retrying backup (attempt %d/%d)", attempt, ss.config.Global.Retry.MaxAttempts)
+			if err := ss.notifier.NotifyRetry(ss.ctx, run, strategy.Name, retryMsg); err != nil {
+				ss.logger.WithError(err).Warn("Failed to send backup retry notification")
 			}
 		}
 
 		result, lastErr = ss.backupService.ExecuteBackupWithProgress(ss.ctx, strategy, func(strategyName, message string) {
-			// Send database output to Slack
-			if thread != nil {
-				if err := ss.slackService.SendDatabaseOutput(ss.ctx, thread, strategyName, message); err != nil {
-					ss.logger.WithError(err).Warn("Failed to send database output to Slack")
-				}
+			// Send database output to the notifiers
+			if err := ss.notifier.NotifyOutput(ss.ctx, run, strategyName, message); err != nil {
+				ss.logger.WithError(err).Warn("Failed to send database output notification")
 			}
 		})
 		if lastErr == nil {
@@ -176,49 +293,57 @@ func (ss *SchedulerService) executeBackupJob(strategy config.StrategyConfig) {
 			"attempt":  attempt,
 		}).Warn("Backup attempt failed")
 
+		// Missing tools, credentials or privileges do not fix themselves
+		if !backup.IsRetryable(lastErr) {
+			break
+		}
+
 		// Send progress update about the failed attempt
-		if thread != nil && attempt < ss.config.Global.Retry.MaxAttempts {
+		if attempt < ss.config.Global.Retry.MaxAttempts {
 			failureMsg := fmt.Sprintf("Attempt %d/%d failed: %s", attempt, ss.config.Global.Retry.MaxAttempts, lastErr.Error())
-			if err := ss.slackService.SendBackupProgress(ss.ctx, thread, strategy.Name, failureMsg); err != nil {
-				ss.logger.WithError(err).Warn("Failed to send backup progress notification")
+			if err := ss.notifier.NotifyRetry(ss.ctx, run, strategy.Name, failureMsg); err != nil {
+				ss.logger.WithError(err).Warn("Failed to send backup retry notification")
 			}
 		}
 	}
 
+	if result != nil {
+		result.CommandLogs = append(preHookLogs, result.CommandLogs...)
+	}
+
 	if lastErr != nil {
 		// All attempts failed
-		ss.handleBackupFailure(strategy, lastErr, result, thread)
+		ss.runCompletionHooks(strategy, result, nil, lastErr)
+		ss.handleBackupFailure(strategy, lastErr, result, run, false)
 		return
 	}
 
 	// Backup successful, upload to S3
-	if thread != nil {
-		if err := ss.slackService.SendBackupProgress(ss.ctx, thread, strategy.Name, "Uploading to S3..."); err != nil {
-			ss.logger.WithError(err).Warn("Failed to send backup progress notification")
-		}
+	if err := ss.notifier.NotifyProgress(ss.ctx, run, strategy.Name, "Uploading to S3..."); err != nil {
+		ss.logger.WithError(err).Warn("Failed to send backup progress notification")
 	}
 
-	s3Location, err := ss.s3Service.UploadBackup(ss.ctx, strategy.Name, result.BackupPath)
+	s3Locations, err := ss.uploadArtifacts(strategy.Name, result)
 	if err != nil {
 		ss.logger.WithError(err).WithField("strategy", strategy.Name).Error("Failed to upload backup to S3")
-		ss.handleBackupFailure(strategy, err, nil, thread)
+		ss.runCompletionHooks(strategy, result, nil, err)
+		ss.handleBackupFailure(strategy, err, nil, run, false)
 		return
 	}
+	result.S3Locations = s3Locations
 
-	// Clean up local file
-	if err := ss.backupService.CleanupTempFiles(result.BackupPath); err != nil {
-		ss.logger.WithError(err).Warn("Failed to cleanup temporary files")
-	}
+	// Run post-backup hooks while the local artifacts still exist
+	ss.runCompletionHooks(strategy, result, ss.artifactKeys(strategy.Name, result), nil)
+
+	// Clean up local files
+	ss.cleanupArtifacts(result)
 
 	// Clean up old backups
-	if thread != nil {
-		if err := ss.slackService.SendBackupProgress(ss.ctx, thread, strategy.Name, "Cleaning up old backups..."); err != nil {
-			ss.logger.WithError(err).Warn("Failed to send backup progress notification")
-		}
+	if err := ss.notifier.NotifyProgress(ss.ctx, run, strategy.Name, "Cleaning up old backups..."); err != nil {
+		ss.logger.WithError(err).Warn("Failed to send backup progress notification")
 	}
 
-	err = ss.s3Service.CleanupOldBackups(ss.ctx, strategy.Name, strategy.Retention)
-	if err != nil {
+	if err := ss.cleanupOldBackups(strategy); err != nil {
 		ss.logger.WithError(err).WithField("strategy", strategy.Name).Warn("Failed to cleanup old backups")
 	}
 
@@ -232,65 +357,427 @@ func (ss *SchedulerService) executeBackupJob(strategy config.StrategyConfig) {
 		NextRun: nextRun,
 	})
 
+	ss.recordSuccess(strategy.Name, result.Size)
+
 	// Send success notification
-	if thread != nil {
-		if err := ss.slackService.SendBackupResult(ss.ctx, thread, []*backup.BackupResult{result}, true); err != nil {
-			ss.logger.WithError(err).Warn("Failed to send backup result notification")
-		}
+	if err := ss.notifier.NotifyResult(ss.ctx, run, []*backup.BackupResult{result}, true); err != nil {
+		ss.logger.WithError(err).Warn("Failed to send backup result notification")
 	}
 
 	ss.logger.WithFields(logrus.Fields{
 		"strategy":    strategy.Name,
 		"duration":    result.Duration,
 		"size":        result.Size,
-		"s3_location": s3Location,
+		"s3_location": s3Locations,
 	}).Info("Backup completed successfully")
 }
 
-// handleBackupFailure handles backup failures
-func (ss *SchedulerService) handleBackupFailure(strategy config.StrategyConfig, err error, result *backup.BackupResult, thread *notification.ThreadInfo) {
-	ss.logger.WithError(err).WithField("strategy", strategy.Name).Error("Backup failed after all retry attempts")
+// uploadArtifacts uploads every artifact of a backup result to S3, followed by its manifest
+func (ss *SchedulerService) uploadArtifacts(strategyName string, result *backup.BackupResult) ([]string, error) {
+	var locations []string
+	for _, artifactPath := range result.ArtifactPaths() {
+		location, err := ss.s3Service.UploadBackup(ss.ctx, strategyName, artifactPath)
+		if err != nil {
+			return locations, err
+		}
+		locations = append(locations, location)
+	}
+
+	// The manifest is uploaded last so that its presence implies complete artifacts
+	if result.ManifestPath != "" {
+		if _, err := ss.s3Service.UploadBackup(ss.ctx, strategyName, result.ManifestPath); err != nil {
+			return locations, fmt.Errorf("failed to upload manifest: %w", err)
+		}
+	}
+
+	return locations, nil
+}
+
+// cleanupArtifacts removes the local artifacts and manifest of a backup result
+func (ss *SchedulerService) cleanupArtifacts(result *backup.BackupResult) {
+	paths := result.ArtifactPaths()
+	if result.ManifestPath != "" {
+		paths = append(paths, result.ManifestPath)
+	}
+
+	for _, path := range paths {
+		if err := ss.backupService.CleanupTempFiles(path); err != nil {
+			ss.logger.WithError(err).Warn("Failed to cleanup temporary files")
+		}
+	}
+}
+
+// runPreBackupHooks runs the pre_backup hooks of a strategy and returns their logs
+func (ss *SchedulerService) runPreBackupHooks(strategy config.StrategyConfig) ([]string, error) {
+	logs, err := ss.hookService.RunHooks(ss.ctx, hooks.StagePreBackup, strategy.Hooks.PreBackup, ss.hookRun(strategy, nil, nil, nil))
+	if err != nil {
+		return logs, fmt.Errorf("pre_backup %w", err)
+	}
+	return logs, nil
+}
+
+// runCompletionHooks runs the post_backup hooks followed by the on_success or on_failure
+// hooks of a strategy and adds their logs to the result. Failing hooks do not change
+// the outcome of the backup.
+func (ss *SchedulerService) runCompletionHooks(strategy config.StrategyConfig, result *backup.BackupResult, s3Keys []string, backupErr error) {
+	run := ss.hookRun(strategy, result, s3Keys, backupErr)
+
+	outcomeStage, outcomeHooks := hooks.StageOnSuccess, strategy.Hooks.OnSuccess
+	if backupErr != nil {
+		outcomeStage, outcomeHooks = hooks.StageOnFailure, strategy.Hooks.OnFailure
+	}
+
+	postLogs, _ := ss.hookService.RunHooks(ss.ctx, hooks.StagePostBackup, strategy.Hooks.PostBackup, run)
+	outcomeLogs, _ := ss.hookService.RunHooks(ss.ctx, outcomeStage, outcomeHooks, run)
+	if result != nil {
+		result.CommandLogs = append(result.CommandLogs, postLogs...)
+		result.CommandLogs = append(result.CommandLogs, outcomeLogs...)
+	}
+}
+
+// hookRun describes a backup run to hooks
+func (ss *SchedulerService) hookRun(strategy config.StrategyConfig, result *backup.BackupResult, s3Keys []string, backupErr error) hooks.Run {
+	run := hooks.Run{
+		Strategy:     strategy.Name,
+		DatabaseType: strategy.DatabaseType,
+		Status:       "running",
+		S3Keys:       s3Keys,
+	}
+	if result != nil {
+		run.ArtifactPaths = result.ArtifactPaths()
+		run.Size = result.Size
+		run.Duration = result.Duration
+		run.Status = "success"
+	}
+	if backupErr != nil {
+		run.Status = "failed"
+		run.Error = backupErr.Error()
+	}
+	return run
+}
+
+// hookFailureResult creates the result of a backup aborted by a pre_backup hook
+func (ss *SchedulerService) hookFailureResult(strategy config.StrategyConfig, hookLogs []string) *backup.BackupResult {
+	now := time.Now()
+	return &backup.BackupResult{
+		Strategy:    strategy.Name,
+		StartTime:   now,
+		EndTime:     now,
+		CommandLogs: hookLogs,
+	}
+}
+
+// failedResult marks the result of a failed backup with its error, keeping its command logs.
+// A minimal result is created when the backup did not return one.
+func failedResult(strategy config.StrategyConfig, err error, result *backup.BackupResult) *backup.BackupResult {
+	if result == nil {
+		now := time.Now()
+		return &backup.BackupResult{
+			Strategy:  strategy.Name,
+			Error:     err,
+			StartTime: now,
+			EndTime:   now,
+		}
+	}
+	result.Success = false
+	result.Error = err
+	return result
+}
+
+// artifactKeys returns the S3 keys of the uploaded artifacts of a backup result
+func (ss *SchedulerService) artifactKeys(strategyName string, result *backup.BackupResult) []string {
+	var keys []string
+	for _, artifactPath := range result.ArtifactPaths() {
+		keys = append(keys, ss.s3Service.ObjectKey(strategyName, filepath.Base(artifactPath)))
+	}
+	return keys
+}
+
+// executeIncrementalJob fetches the binary logs written since the latest backup of a strategy
+func (ss *SchedulerService) executeIncrementalJob(strategy config.StrategyConfig) {
+	select {
+	case ss.semaphore <- struct{}{}:
+		defer func() { <-ss.semaphore }()
+	case <-ss.ctx.Done():
+		return
+	}
+
+	ss.logger.WithField("strategy", strategy.Name).Info("Starting incremental binlog backup")
+
+	base, err := ss.latestManifest(strategy.Name)
+	if err != nil {
+		ss.logger.WithError(err).WithField("strategy", strategy.Name).Warn("Skipping incremental backup")
+		return
+	}
+
+	result, err := ss.backupService.ExecuteIncrementalBackup(ss.ctx, strategy, base, nil)
+	if errors.Is(err, backup.ErrNoBinlogChanges) {
+		ss.logger.WithField("strategy", strategy.Name).Debug("No binlog changes since the last backup")
+		return
+	}
+	if err != nil {
+		// Incremental backups only announce failed runs
+		run := notification.NewRun([]string{strategy.Name + notification.IncrementalSuffix}, strategy.Slack)
+		if notifyErr := ss.notifier.NotifyStarted(ss.ctx, run); notifyErr != nil {
+			ss.logger.WithError(notifyErr).Warn("Failed to send backup started notification")
+		}
+		ss.handleBackupFailure(strategy, fmt.Errorf("incremental backup failed: %w", err), result, run, true)
+		return
+	}
+
+	if _, err := ss.uploadArtifacts(strategy.Name, result); err != nil {
+		ss.logger.WithError(err).WithField("strategy", strategy.Name).Error("Failed to upload incremental backup to S3")
+		ss.handleBackupFailure(strategy, err, nil, notification.NewRun([]string{strategy.Name + notification.IncrementalSuffix}, strategy.Slack), true)
+		return
+	}
+	ss.cleanupArtifacts(result)
+
+	// Clear a previous failure of the incremental backups
+	statusName := strategy.Name + notification.IncrementalSuffix
+	ss.monitoringService.RecordBackupMetrics(statusName, result.Duration, result.Size, true)
+	ss.monitoringService.UpdateStrategyStatus(statusName, monitoring.StrategyStatus{
+		Status:  "success",
+		LastRun: time.Now().UTC().Format(time.RFC3339),
+		NextRun: ss.getNextRunTime(strategy.MySQL.Binlog.Schedule),
+	})
+
+	// Report the first success after a failure, or since the start, to resolve the incident
+	// of the incremental backups
+	ss.mu.Lock()
+	recovered := !ss.incrementalOK[strategy.Name]
+	ss.incrementalOK[strategy.Name] = true
+	ss.mu.Unlock()
+	if recovered {
+		run := notification.NewRun([]string{statusName}, strategy.Slack)
+		if err := ss.notifier.NotifyResult(ss.ctx, run, []*backup.BackupResult{result}, true); err != nil {
+			ss.logger.WithError(err).Warn("Failed to send incremental backup result notification")
+		}
+	}
+
+	ss.logger.WithFields(logrus.Fields{
+		"strategy":     strategy.Name,
+		"binlog_start": result.BinlogStart.String(),
+		"binlog_end":   result.BinlogEnd.String(),
+		"size":         result.Size,
+	}).Info("Incremental binlog backup completed successfully")
+}
+
+// cleanupOldBackups applies the retention of a strategy. With incremental backups the latest
+// full backup and its increments are kept even when they are older than the retention.
+func (ss *SchedulerService) cleanupOldBackups(strategy config.StrategyConfig) error {
+	var keep []string
+	if strategy.MySQL.Binlog.Enabled {
+		manifests, err := ss.downloadManifests(strategy.Name)
+		if err != nil {
+			return fmt.Errorf("failed to read the restore chain, skipping cleanup: %w", err)
+		}
+		keep = backup.RestoreChainObjects(manifests)
+	}
+	return ss.s3Service.CleanupOldBackups(ss.ctx, strategy.Name, strategy.Retention, keep)
+}
+
+// latestManifest returns the manifest of the most recent backup stored for a strategy
+func (ss *SchedulerService) latestManifest(strategyName string) (*backup.Manifest, error) {
+	objects, err := ss.s3Service.ListBackups(ss.ctx, strategyName)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := ""
+	for _, object := range objects {
+		if backup.IsManifestKey(object.Name) && object.Name > latest {
+			latest = object.Name
+		}
+	}
+	if latest == "" {
+		return nil, fmt.Errorf("no backup manifest found for strategy %s", strategyName)
+	}
+
+	return ss.downloadManifest(strategyName, latest)
+}
+
+// downloadManifest downloads and parses a stored manifest
+func (ss *SchedulerService) downloadManifest(strategyName, name string) (*backup.Manifest, error) {
+	localPath := filepath.Join(ss.config.Global.TempDir, name)
+	if err := os.MkdirAll(ss.config.Global.TempDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.Remove(localPath)
+
+	if err := ss.s3Service.DownloadBackup(ss.ctx, strategyName, name, localPath); err != nil {
+		return nil, err
+	}
+	return backup.ReadManifest(localPath)
+}
+
+// downloadManifests downloads and parses every stored manifest of a strategy
+func (ss *SchedulerService) downloadManifests(strategyName string) ([]*backup.Manifest, error) {
+	objects, err := ss.s3Service.ListBackups(ss.ctx, strategyName)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifests []*backup.Manifest
+	for _, object := range objects {
+		if !backup.IsManifestKey(object.Name) {
+			continue
+		}
+		manifest, err := ss.downloadManifest(strategyName, object.Name)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, manifest)
+	}
+	return manifests, nil
+}
+
+// RestoreLatestBackup downloads the latest backup chain of a strategy (full backup and
+// the incremental backups chained to it) and restores it into target
+func (ss *SchedulerService) RestoreLatestBackup(strategyName, target string) error {
+	strategy, err := ss.findStrategy(strategyName)
+	if err != nil {
+		return err
+	}
+
+	manifests, err := ss.downloadManifests(strategyName)
+	if err != nil {
+		return err
+	}
+
+	chain, err := backup.BuildRestoreChain(manifests)
+	if err != nil {
+		return fmt.Errorf("cannot restore strategy %s: %w", strategyName, err)
+	}
+
+	restoreDir, err := os.MkdirTemp(ss.config.Global.TempDir, "restore-"+strategyName+"-")
+	if err != nil {
+		return fmt.Errorf("failed to create restore directory: %w", err)
+	}
+	defer os.RemoveAll(restoreDir)
+
+	var artifactPaths []string
+	for _, manifest := range chain {
+		for _, artifact := range manifest.Artifacts {
+			localPath := filepath.Join(restoreDir, artifact)
+			if err := ss.s3Service.DownloadBackup(ss.ctx, strategyName, artifact, localPath); err != nil {
+				return err
+			}
+			artifactPaths = append(artifactPaths, localPath)
+		}
+	}
+
+	ss.logger.WithFields(logrus.Fields{
+		"strategy":    strategyName,
+		"base_backup": chain[0].Name,
+		"increments":  len(chain) - 1,
+		"artifacts":   len(artifactPaths),
+	}).Info("Restoring backup chain")
+
+	return ss.backupService.ExecuteRestore(ss.ctx, *strategy, target, artifactPaths, func(strategyName, message string) {
+		ss.logger.WithField("strategy", strategyName).Info(message)
+	})
+}
+
+// findStrategy returns the configuration of a strategy by name
+func (ss *SchedulerService) findStrategy(strategyName string) (*config.StrategyConfig, error) {
+	for i := range ss.config.Strategies {
+		if ss.config.Strategies[i].Name == strategyName {
+			return &ss.config.Strategies[i], nil
+		}
+	}
+	return nil, fmt.Errorf("strategy '%s' not found", strategyName)
+}
+
+// recordSuccess stores the time of a successful backup for the stale checks and reports
+func (ss *SchedulerService) recordSuccess(strategyName string, size int64) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.lastSuccess[strategyName] = time.Now()
+	delete(ss.staleNotified, strategyName)
+	ss.recordRun(strategyName, true, size)
+}
+
+// checkStaleBackups notifies once about every strategy that has not backed up successfully
+// within its stale_after, counted from the start of the service until its first success
+func (ss *SchedulerService) checkStaleBackups() {
+	for _, strategy := range ss.config.Strategies {
+		if strategy.StaleAfter == "" {
+			continue
+		}
+		staleAfter, err := config.ParseDuration(strategy.StaleAfter)
+		if err != nil {
+			continue
+		}
+
+		ss.mu.Lock()
+		lastSuccess := ss.lastSuccess[strategy.Name]
+		since := lastSuccess
+		if since.IsZero() {
+			since = ss.startedAt
+		}
+		stale := time.Since(since) > staleAfter && !ss.staleNotified[strategy.Name]
+		if stale {
+			ss.staleNotified[strategy.Name] = true
+		}
+		ss.mu.Unlock()
+		if !stale {
+			continue
+		}
+
+		ss.logger.WithFields(logrus.Fields{
+			"strategy":     strategy.Name,
+			"stale_after":  strategy.StaleAfter,
+			"last_success": lastSuccess,
+		}).Warn("No recent successful backup")
+
+		run := notification.NewRun([]string{strategy.Name}, strategy.Slack)
+		if err := ss.notifier.NotifyStale(ss.ctx, run, strategy.Name, lastSuccess); err != nil {
+			ss.logger.WithError(err).Warn("Failed to send stale backup notification")
+		}
+	}
+}
+
+// handleBackupFailure handles backup failures. Failed incremental backups get their own
+// status and metrics, so that they do not mark the full backups of the strategy as failed.
+func (ss *SchedulerService) handleBackupFailure(strategy config.StrategyConfig, err error, result *backup.BackupResult, run *notification.Run, incremental bool) {
+	ss.logger.WithError(err).WithFields(logrus.Fields{
+		"strategy":    strategy.Name,
+		"incremental": incremental,
+	}).Error("Backup failed after all retry attempts")
+
+	statusName, schedule := strategy.Name, strategy.Schedule
+	if incremental {
+		statusName, schedule = strategy.Name+notification.IncrementalSuffix, strategy.MySQL.Binlog.Schedule
+		ss.mu.Lock()
+		delete(ss.incrementalOK, strategy.Name)
+		ss.mu.Unlock()
+	} else {
+		// Incremental backups are not part of the run history
+		ss.recordFailure(strategy.Name)
+	}
 
 	// Update metrics and status
-	ss.monitoringService.RecordBackupMetrics(strategy.Name, 0, 0, false)
+	ss.monitoringService.RecordBackupMetrics(statusName, 0, 0, false)
 
-	nextRun := ss.getNextRunTime(strategy.Schedule)
-	ss.monitoringService.UpdateStrategyStatus(strategy.Name, monitoring.StrategyStatus{
+	nextRun := ss.getNextRunTime(schedule)
+	ss.monitoringService.UpdateStrategyStatus(statusName, monitoring.StrategyStatus{
 		Status:  "failed",
 		LastRun: time.Now().UTC().Format(time.RFC3339),
 		NextRun: nextRun,
 		Error:   err.Error(),
 	})
 
-	// Send failure notification
-	if thread != nil {
-		var failedResult *backup.BackupResult
-		if result != nil {
-			// Use the actual backup result with command logs
-			failedResult = result
-			failedResult.Success = false
-			failedResult.Error = err
-		} else {
-			// Fallback to creating a minimal result
-			failedResult = &backup.BackupResult{
-				Strategy:  strategy.Name,
-				Success:   false,
-				Error:     err,
-				StartTime: time.Now(),
-				EndTime:   time.Now(),
-			}
-		}
+	failed := failedResult(strategy, err, result)
 
-		// Send the main backup result notification
-		if err := ss.slackService.SendBackupResult(ss.ctx, thread, []*backup.BackupResult{failedResult}, false); err != nil {
-			ss.logger.WithError(err).Warn("Failed to send backup failure notification")
-		}
+	// Send the main backup result notification
+	if err := ss.notifier.NotifyResult(ss.ctx, run, []*backup.BackupResult{failed}, false); err != nil {
+		ss.logger.WithError(err).Warn("Failed to send backup failure notification")
+	}
 
-		// Send detailed error information for debugging if we have a result with command logs
-		if result != nil && len(result.CommandLogs) > 0 {
-			if err := ss.slackService.SendDetailedError(ss.ctx, thread, strategy.Name, failedResult); err != nil {
-				ss.logger.WithError(err).Warn("Failed to send detailed error information")
-			}
+	// Send detailed error information for debugging if we have a result with command logs
+	if result != nil && len(result.CommandLogs) > 0 {
+		if err := ss.notifier.NotifyDetailedError(ss.ctx, run, strategy.Name, failed); err != nil {
+			ss.logger.WithError(err).Warn("Failed to send detailed error information")
 		}
 	}
 }
@@ -361,8 +848,8 @@ func (ss *SchedulerService) ExecuteAllStrategiesManually() {
 		slackConfig = ss.config.Global.Slack
 	}
 
-	thread, err := ss.slackService.SendBackupStarted(ss.ctx, strategyNames, slackConfig)
-	if err != nil {
+	run := notification.NewRun(strategyNames, slackConfig)
+	if err := ss.notifier.NotifyStarted(ss.ctx, run); err != nil {
 		ss.logger.WithError(err).Warn("Failed to send manual backup started notification")
 	}
 
@@ -381,37 +868,38 @@ func (ss *SchedulerService) ExecuteAllStrategiesManually() {
 		})
 
 		// Send progress update
-		if thread != nil {
-			progressMsg := fmt.Sprintf("Starting backup for strategy: %s", strategy.Name)
-			if err := ss.slackService.SendBackupProgress(ss.ctx, thread, strategy.Name, progressMsg); err != nil {
-				ss.logger.WithError(err).Warn("Failed to send backup progress notification")
-			}
+		progressMsg := fmt.Sprintf("Starting backup for strategy: %s", strategy.Name)
+		if err := ss.notifier.NotifyProgress(ss.ctx, run, strategy.Name, progressMsg); err != nil {
+			ss.logger.WithError(err).Warn("Failed to send backup progress notification")
 		}
 
+		// Run pre-backup hooks, a failing hook aborts the backup
+		preHookLogs, preHookErr := ss.runPreBackupHooks(strategy)
+
 		// Execute backup with retry
 		var result *backup.BackupResult
 		var lastErr error
+		if preHookErr != nil {
+			result = ss.hookFailureResult(strategy, preHookLogs)
+			lastErr = preHookErr
+		}
 
-		for attempt := 1; attempt <= ss.config.Global.Retry.MaxAttempts; attempt++ {
+		for attempt := 1; preHookErr == nil && attempt <= ss.config.Global.Retry.MaxAttempts; attempt++ {
 			if attempt > 1 {
 				ss.logger.WithFields(logrus.Fields{
 					"strategy": strategy.Name,
 					"attempt":  attempt,
 				}).Info("Retrying manual backup")
-				if thread != nil {
-					retryMsg := fmt.Sprintf("Retrying backup for %s (attempt %d/%d)", strategy.Name, attempt, ss.config.Global.Retry.MaxAttempts)
-					if err := ss.slackService.SendBackupProgress(ss.ctx, thread, strategy.Name, retryMsg); err != nil {
-						ss.logger.WithError(err).Warn("Failed to send backup progress notification")
-					}
+				retryMsg := fmt.Sprintf("Retrying backup for %s (attempt %d/%d)", strategy.Name, attempt, ss.config.Global.Retry.MaxAttempts)
+				if err := ss.notifier.NotifyRetry(ss.ctx, run, strategy.Name, retryMsg); err != nil {
+					ss.logger.WithError(err).Warn("Failed to send backup retry notification")
 				}
 			}
 
 			result, lastErr = ss.backupService.ExecuteBackupWithProgress(ss.ctx, strategy, func(strategyName, message string) {
-				// Send database output to Slack
-				if thread != nil {
-					if err := ss.slackService.SendDatabaseOutput(ss.ctx, thread, strategyName, message); err != nil {
-						ss.logger.WithError(err).Warn("Failed to send database output to Slack")
-					}
+				// Send database output to the notifiers
+				if err := ss.notifier.NotifyOutput(ss.ctx, run, strategyName, message); err != nil {
+					ss.logger.WithError(err).Warn("Failed to send database output notification")
 				}
 			})
 			if lastErr == nil {
@@ -423,17 +911,29 @@ func (ss *SchedulerService) ExecuteAllStrategiesManually() {
 				"attempt":  attempt,
 			}).Warn("Manual backup attempt failed")
 
+			// Missing tools, credentials or privileges do not fix themselves
+			if !backup.IsRetryable(lastErr) {
+				break
+			}
+
 			// Send progress update about the failed attempt
-			if thread != nil && attempt < ss.config.Global.Retry.MaxAttempts {
+			if attempt < ss.config.Global.Retry.MaxAttempts {
 				failureMsg := fmt.Sprintf("Attempt %d/%d failed for %s: %s", attempt, ss.config.Global.Retry.MaxAttempts, strategy.Name, lastErr.Error())
-				if err := ss.slackService.SendBackupProgress(ss.ctx, thread, strategy.Name, failureMsg); err != nil {
-					ss.logger.WithError(err).Warn("Failed to send backup progress notification")
+				if err := ss.notifier.NotifyRetry(ss.ctx, run, strategy.Name, failureMsg); err != nil {
+					ss.logger.WithError(err).Warn("Failed to send backup retry notification")
 				}
 			}
 		}
 
+		if preHookErr == nil && result != nil {
+			result.CommandLogs = append(preHookLogs, result.CommandLogs...)
+		}
+
 		if lastErr != nil {
-			// All attempts failed
+			// All attempts failed, or a pre-backup hook aborted the backup
+			result = failedResult(strategy, lastErr, result)
+			ss.runCompletionHooks(strategy, result, nil, lastErr)
+			ss.recordFailure(strategy.Name)
 			failureCount++
 			results[strategy.Name] = result
 			ss.logger.WithError(lastErr).WithField("strategy", strategy.Name).Error("Manual backup failed after all attempts")
@@ -447,25 +947,24 @@ func (ss *SchedulerService) ExecuteAllStrategiesManually() {
 			})
 
 			// Send failure notification
-			if thread != nil {
-				if err := ss.slackService.SendDetailedError(ss.ctx, thread, strategy.Name, result); err != nil {
-					ss.logger.WithError(err).Warn("Failed to send backup failed notification")
-				}
+			if err := ss.notifier.NotifyDetailedError(ss.ctx, run, strategy.Name, result); err != nil {
+				ss.logger.WithError(err).Warn("Failed to send backup failed notification")
 			}
 			continue
 		}
 
 		// Backup successful, upload to S3
-		if thread != nil {
-			uploadMsg := fmt.Sprintf("Uploading %s backup to S3...", strategy.Name)
-			if err := ss.slackService.SendBackupProgress(ss.ctx, thread, strategy.Name, uploadMsg); err != nil {
-				ss.logger.WithError(err).Warn("Failed to send backup progress notification")
-			}
+		uploadMsg := fmt.Sprintf("Uploading %s backup to S3...", strategy.Name)
+		if err := ss.notifier.NotifyProgress(ss.ctx, run, strategy.Name, uploadMsg); err != nil {
+			ss.logger.WithError(err).Warn("Failed to send backup progress notification")
 		}
 
-		s3Location, err := ss.s3Service.UploadBackup(ss.ctx, strategy.Name, result.BackupPath)
+		s3Locations, err := ss.uploadArtifacts(strategy.Name, result)
 		if err != nil {
 			ss.logger.WithError(err).WithField("strategy", strategy.Name).Error("Failed to upload manual backup to S3")
+			result = failedResult(strategy, fmt.Errorf("failed to upload backup to S3: %w", err), result)
+			ss.runCompletionHooks(strategy, result, nil, err)
+			ss.recordFailure(strategy.Name)
 			failureCount++
 			results[strategy.Name] = result
 
@@ -478,27 +977,28 @@ func (ss *SchedulerService) ExecuteAllStrategiesManually() {
 			})
 
 			// Send failure notification
-			if thread != nil {
-				if err := ss.slackService.SendDetailedError(ss.ctx, thread, strategy.Name, result); err != nil {
-					ss.logger.WithError(err).Warn("Failed to send backup failed notification")
-				}
+			if err := ss.notifier.NotifyDetailedError(ss.ctx, run, strategy.Name, result); err != nil {
+				ss.logger.WithError(err).Warn("Failed to send backup failed notification")
 			}
 			continue
 		}
+		result.S3Locations = s3Locations
 
-		// Clean up local file
-		if err := ss.backupService.CleanupTempFiles(result.BackupPath); err != nil {
-			ss.logger.WithError(err).Warn("Failed to cleanup temporary files")
-		}
+		// Run post-backup hooks while the local artifacts still exist
+		ss.runCompletionHooks(strategy, result, ss.artifactKeys(strategy.Name, result), nil)
+
+		// Clean up local files
+		ss.cleanupArtifacts(result)
 
 		// Success
 		successCount++
 		results[strategy.Name] = result
+		ss.recordSuccess(strategy.Name, result.Size)
 		ss.logger.WithFields(logrus.Fields{
 			"strategy":    strategy.Name,
 			"size":        result.Size,
 			"duration":    result.Duration,
-			"s3_location": s3Location,
+			"s3_location": s3Locations,
 		}).Info("Manual backup completed successfully")
 
 		// Update strategy status
@@ -510,30 +1010,31 @@ func (ss *SchedulerService) ExecuteAllStrategiesManually() {
 	}
 
 	// Send final summary notification
-	if thread != nil {
-		var summaryResults []*backup.BackupResult
-		for _, result := range results {
-			if result != nil {
-				summaryResults = append(summaryResults, result)
-			}
+	var summaryResults []*backup.BackupResult
+	for _, result := range results {
+		if result != nil {
+			summaryResults = append(summaryResults, result)
 		}
+	}
 
-		if failureCount == 0 {
-			// All successful
-			if err := ss.slackService.SendBackupResult(ss.ctx, thread, summaryResults, true); err != nil {
-				ss.logger.WithError(err).Warn("Failed to send backup success notification")
-			}
-		} else if successCount == 0 {
-			// All failed
-			if err := ss.slackService.SendBackupResult(ss.ctx, thread, summaryResults, false); err != nil {
-				ss.logger.WithError(err).Warn("Failed to send backup failed notification")
-			}
-		} else {
-			// Mixed results
-			mixedMsg := fmt.Sprintf("Manual backup completed: %d successful, %d failed", successCount, failureCount)
-			if err := ss.slackService.SendBackupProgress(ss.ctx, thread, "Summary", mixedMsg); err != nil {
-				ss.logger.WithError(err).Warn("Failed to send backup summary notification")
-			}
+	if failureCount == 0 {
+		// All successful
+		if err := ss.notifier.NotifyResult(ss.ctx, run, summaryResults, true); err != nil {
+			ss.logger.WithError(err).Warn("Failed to send backup success notification")
+		}
+	} else if successCount == 0 {
+		// All failed
+		if err := ss.notifier.NotifyResult(ss.ctx, run, summaryResults, false); err != nil {
+			ss.logger.WithError(err).Warn("Failed to send backup failed notification")
+		}
+	} else {
+		// Mixed results
+		mixedMsg := fmt.Sprintf("Manual backup completed: %d successful, %d failed", successCount, failureCount)
+		if err := ss.notifier.NotifyProgress(ss.ctx, run, "Summary", mixedMsg); err != nil {
+			ss.logger.WithError(err).Warn("Failed to send backup summary notification")
+		}
+		if err := ss.notifier.NotifyResult(ss.ctx, run, summaryResults, false); err != nil {
+			ss.logger.WithError(err).Warn("Failed to send backup result notification")
 		}
 	}
 
@@ -546,22 +1047,21 @@ func (ss *SchedulerService) ExecuteAllStrategiesManually() {
 
 // ExecuteStrategyManually executes a specific backup strategy manually
 func (ss *SchedulerService) ExecuteStrategyManually(strategyName string) error {
+	return ss.ExecuteStrategyWithTrigger(strategyName, nil)
+}
+
+// ExecuteStrategyWithTrigger executes a specific backup strategy on demand, the trigger
+// links the run to the Slack command that started it
+func (ss *SchedulerService) ExecuteStrategyWithTrigger(strategyName string, trigger *notification.Trigger) error {
 	ss.logger.WithField("strategy", strategyName).Info("Starting manual execution of backup strategy")
 
 	// Find the strategy
-	var targetStrategy *config.StrategyConfig
-	for _, strategy := range ss.config.Strategies {
-		if strategy.Name == strategyName {
-			targetStrategy = &strategy
-			break
-		}
-	}
-
-	if targetStrategy == nil {
-		return fmt.Errorf("strategy '%s' not found", strategyName)
+	targetStrategy, err := ss.findStrategy(strategyName)
+	if err != nil {
+		return err
 	}
 
 	// Execute the backup job
-	ss.executeBackupJob(*targetStrategy)
+	ss.executeBackupJob(*targetStrategy, trigger)
 	return nil
 }
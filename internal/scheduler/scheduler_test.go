@@ -0,0 +1,119 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"easy-backup/internal/backup"
+	"easy-backup/internal/config"
+	"easy-backup/internal/logger"
+	"easy-backup/internal/monitoring"
+	"easy-backup/internal/notification"
+	"easy-backup/internal/storage"
+)
+
+// testMonitoring is shared by the tests, its metrics can only be registered once
+var testMonitoring = sync.OnceValue(func() *monitoring.MonitoringService {
+	return monitoring.NewMonitoringService(&config.Config{}, nil, nil)
+})
+
+// newTestScheduler creates a scheduler sending its notifications to notifier
+func newTestScheduler(t *testing.T, cfg *config.Config, notifier notification.Notifier) *SchedulerService {
+	require.NoError(t, logger.InitLogger("error"))
+	if cfg.Global.MaxParallel == 0 {
+		cfg.Global.MaxParallel = 1
+	}
+	return NewSchedulerService(cfg, backup.NewBackupService(cfg), nil, notifier, testMonitoring())
+}
+
+func TestHandleBackupFailure_Incremental(t *testing.T) {
+	strategy := config.StrategyConfig{Name: "mysql-main", Schedule: "0 2 * * *"}
+	cfg := &config.Config{
+		Global:     config.GlobalConfig{Notifications: config.NotificationsConfig{Reports: []config.ReportConfig{{Schedule: "0 8 * * *", Period: "1d"}}}},
+		Strategies: []config.StrategyConfig{strategy},
+	}
+	ss := newTestScheduler(t, cfg, notification.NewRouter(cfg))
+
+	run := notification.NewRun([]string{strategy.Name + notification.IncrementalSuffix}, strategy.Slack)
+	ss.handleBackupFailure(strategy, errors.New("failed to fetch binlogs"), nil, run, true)
+	assert.Empty(t, ss.history, "incremental backups are not part of the run history")
+
+	ss.handleBackupFailure(strategy, errors.New("mariadb-dump failed"), nil, notification.NewRun([]string{strategy.Name}, strategy.Slack), false)
+	require.Len(t, ss.history, 1)
+	assert.False(t, ss.history[0].success)
+}
+
+func TestExecuteAllStrategiesManually_UploadFailure(t *testing.T) {
+	s3Server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>", http.StatusForbidden)
+	}))
+	defer s3Server.Close()
+
+	var mu sync.Mutex
+	var actions []string
+	pagerDuty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event struct {
+			EventAction string `json:"event_action"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		mu.Lock()
+		actions = append(actions, event.EventAction)
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer pagerDuty.Close()
+
+	cfg := &config.Config{
+		Global: config.GlobalConfig{
+			TempDir: t.TempDir(),
+			Retry:   config.RetryConfig{MaxAttempts: 1},
+			Timeout: config.TimeoutConfig{Backup: "1h", Upload: "1h"},
+			S3: config.S3Config{
+				Bucket:      "backups",
+				Compression: "none",
+				Endpoint:    s3Server.URL,
+				Credentials: config.S3Credentials{AccessKey: "key", SecretKey: "secret", Region: "us-east-1"},
+			},
+			Notifications: config.NotificationsConfig{Alerting: config.AlertingConfig{
+				PagerDuty: config.PagerDutyConfig{RoutingKey: "routing-key", URL: pagerDuty.URL},
+			}},
+		},
+		Strategies: []config.StrategyConfig{{
+			Name:         "vendor",
+			DatabaseType: "command",
+			Schedule:     "0 2 * * *",
+			Command:      config.CommandConfig{Command: "sh", Args: []string{"-c", "echo data > \"$0\"", "{{.OutputPath}}"}},
+		}},
+	}
+	ss := newTestScheduler(t, cfg, notification.NewAlertNotifier(cfg))
+	s3Service, err := storage.NewS3Service(cfg)
+	require.NoError(t, err)
+	ss.s3Service = s3Service
+
+	ss.ExecuteAllStrategiesManually()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"trigger"}, actions, "a failed upload opens an incident instead of resolving it")
+}
+
+func TestFailedResult(t *testing.T) {
+	strategy := config.StrategyConfig{Name: "postgres-main"}
+	hookErr := errors.New("pre-backup hook 'maintenance-mode' failed")
+
+	result := failedResult(strategy, hookErr, &backup.BackupResult{Strategy: strategy.Name, Success: true, CommandLogs: []string{"Hook: maintenance-mode"}})
+	assert.False(t, result.Success)
+	assert.Equal(t, hookErr, result.Error)
+	assert.Equal(t, []string{"Hook: maintenance-mode"}, result.CommandLogs)
+
+	result = failedResult(strategy, hookErr, nil)
+	assert.Equal(t, strategy.Name, result.Strategy)
+	assert.Equal(t, hookErr, result.Error)
+}
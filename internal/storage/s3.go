@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -20,11 +22,20 @@ import (
 
 // S3Service handles S3 storage operations
 type S3Service struct {
-	config   *config.Config
-	logger   *logrus.Logger
-	session  *session.Session
-	uploader *s3manager.Uploader
-	s3Client *s3.S3
+	config     *config.Config
+	logger     *logrus.Logger
+	session    *session.Session
+	uploader   *s3manager.Uploader
+	downloader *s3manager.Downloader
+	s3Client   *s3.S3
+}
+
+// BackupObject describes a backup object stored in S3
+type BackupObject struct {
+	Key          string
+	Name         string
+	Size         int64
+	LastModified time.Time
 }
 
 // NewS3Service creates a new S3 service
@@ -52,11 +63,12 @@ func NewS3Service(cfg *config.Config) (*S3Service, error) {
 	}
 
 	return &S3Service{
-		config:   cfg,
-		logger:   logger.GetLogger(),
-		session:  sess,
-		uploader: s3manager.NewUploader(sess),
-		s3Client: s3.New(sess),
+		config:     cfg,
+		logger:     logger.GetLogger(),
+		session:    sess,
+		uploader:   s3manager.NewUploader(sess),
+		downloader: s3manager.NewDownloader(sess),
+		s3Client:   s3.New(sess),
 	}, nil
 }
 
@@ -80,8 +92,7 @@ func (s3s *S3Service) UploadBackup(ctx context.Context, strategy string, localPa
 	defer file.Close()
 
 	// Generate S3 key
-	filename := filepath.Base(localPath)
-	s3Key := filepath.Join(s3s.config.Global.S3.BasePath, strategy, filename)
+	s3Key := s3s.ObjectKey(strategy, filepath.Base(localPath))
 
 	s3s.logger.WithFields(logrus.Fields{
 		"strategy": strategy,
@@ -108,6 +119,68 @@ func (s3s *S3Service) UploadBackup(ctx context.Context, strategy string, localPa
 	return result.Location, nil
 }
 
+// ListBackups lists the objects stored for a strategy
+func (s3s *S3Service) ListBackups(ctx context.Context, strategy string) ([]BackupObject, error) {
+	prefix := s3s.ObjectKey(strategy, "")
+	listInput := &s3.ListObjectsV2Input{
+		Bucket: aws.String(s3s.config.Global.S3.Bucket),
+		Prefix: aws.String(prefix),
+	}
+
+	var objects []BackupObject
+	err := s3s.s3Client.ListObjectsV2PagesWithContext(ctx, listInput, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, obj := range page.Contents {
+			objects = append(objects, BackupObject{
+				Key:          aws.StringValue(obj.Key),
+				Name:         strings.TrimPrefix(aws.StringValue(obj.Key), prefix),
+				Size:         aws.Int64Value(obj.Size),
+				LastModified: aws.TimeValue(obj.LastModified),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list S3 objects: %w", err)
+	}
+
+	return objects, nil
+}
+
+// DownloadBackup downloads a stored object of a strategy to a local file
+func (s3s *S3Service) DownloadBackup(ctx context.Context, strategy, name, localPath string) error {
+	file, err := os.Create(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to create local file: %w", err)
+	}
+	defer file.Close()
+
+	s3Key := s3s.ObjectKey(strategy, name)
+	_, err = s3s.downloader.DownloadWithContext(ctx, file, &s3.GetObjectInput{
+		Bucket: aws.String(s3s.config.Global.S3.Bucket),
+		Key:    aws.String(s3Key),
+	})
+	if err != nil {
+		os.Remove(localPath)
+		return fmt.Errorf("failed to download %s from S3: %w", s3Key, err)
+	}
+
+	s3s.logger.WithFields(logrus.Fields{
+		"strategy": strategy,
+		"key":      s3Key,
+		"file":     localPath,
+	}).Debug("Downloaded object from S3")
+
+	return nil
+}
+
+// ObjectKey returns the S3 key of a file stored for a strategy
+func (s3s *S3Service) ObjectKey(strategy, name string) string {
+	if name == "" {
+		return filepath.Join(s3s.config.Global.S3.BasePath, strategy) + "/"
+	}
+	return filepath.Join(s3s.config.Global.S3.BasePath, strategy, name)
+}
+
 // TestConnection tests the S3 connection
 func (s3s *S3Service) TestConnection(ctx context.Context) error {
 	// Try to list objects in the bucket (limit to 1)
@@ -122,8 +195,9 @@ func (s3s *S3Service) TestConnection(ctx context.Context) error {
 	return nil
 }
 
-// CleanupOldBackups removes old backups based on retention policy
-func (s3s *S3Service) CleanupOldBackups(ctx context.Context, strategy string, retention string) error {
+// CleanupOldBackups removes old backups based on retention policy, except the objects named
+// in keep, e.g. the latest restore chain of incremental backups
+func (s3s *S3Service) CleanupOldBackups(ctx context.Context, strategy string, retention string, keep []string) error {
 	// Parse retention duration
 	retentionDuration, err := config.ParseDuration(retention)
 	if err != nil {
@@ -131,7 +205,7 @@ func (s3s *S3Service) CleanupOldBackups(ctx context.Context, strategy string, re
 	}
 
 	cutoffTime := time.Now().Add(-retentionDuration)
-	prefix := filepath.Join(s3s.config.Global.S3.BasePath, strategy) + "/"
+	prefix := s3s.ObjectKey(strategy, "")
 
 	s3s.logger.WithFields(logrus.Fields{
 		"strategy": strategy,
@@ -148,7 +222,7 @@ func (s3s *S3Service) CleanupOldBackups(ctx context.Context, strategy string, re
 	var objectsToDelete []*s3.ObjectIdentifier
 	err = s3s.s3Client.ListObjectsV2PagesWithContext(ctx, listInput, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
 		for _, obj := range page.Contents {
-			if obj.LastModified.Before(cutoffTime) {
+			if obj.LastModified.Before(cutoffTime) && !slices.Contains(keep, strings.TrimPrefix(aws.StringValue(obj.Key), prefix)) {
 				objectsToDelete = append(objectsToDelete, &s3.ObjectIdentifier{
 					Key: obj.Key,
 				})
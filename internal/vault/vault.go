@@ -0,0 +1,134 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// requestTimeout bounds a single request to the Vault server
+const requestTimeout = 30 * time.Second
+
+// Client is a minimal client of the HashiCorp Vault HTTP API
+type Client struct {
+	address    string
+	token      string
+	namespace  string
+	httpClient *http.Client
+}
+
+// Secret is the response to a read of a Vault path
+type Secret struct {
+	LeaseID       string                 `json:"lease_id"`
+	LeaseDuration int                    `json:"lease_duration"` // Seconds
+	Renewable     bool                   `json:"renewable"`
+	Data          map[string]interface{} `json:"data"`
+}
+
+// NewClient creates a client for the Vault server at address using the given token
+func NewClient(address, token, namespace string) *Client {
+	return &Client{
+		address:    strings.TrimSuffix(address, "/"),
+		token:      token,
+		namespace:  namespace,
+		httpClient: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// NewClientFromEnv creates a client configured like the vault CLI, from VAULT_ADDR,
+// VAULT_TOKEN and the optional VAULT_NAMESPACE
+func NewClientFromEnv() (*Client, error) {
+	address := os.Getenv("VAULT_ADDR")
+	if address == "" {
+		return nil, fmt.Errorf("VAULT_ADDR is not set")
+	}
+	token := os.Getenv("VAULT_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("VAULT_TOKEN is not set")
+	}
+	return NewClient(address, token, os.Getenv("VAULT_NAMESPACE")), nil
+}
+
+// Read reads the secret at a path, e.g. secret/data/easy-backup or database/creds/backup
+func (c *Client) Read(ctx context.Context, path string) (*Secret, error) {
+	var secret Secret
+	if err := c.do(ctx, http.MethodGet, "/v1/"+strings.TrimPrefix(path, "/"), nil, &secret); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return &secret, nil
+}
+
+// RevokeLease revokes the lease of a dynamic secret before it expires
+func (c *Client) RevokeLease(ctx context.Context, leaseID string) error {
+	body := map[string]string{"lease_id": leaseID}
+	if err := c.do(ctx, http.MethodPut, "/v1/sys/leases/revoke", body, nil); err != nil {
+		return fmt.Errorf("failed to revoke lease %s: %w", leaseID, err)
+	}
+	return nil
+}
+
+// KVData returns the key/value pairs of a KV secret. Version 2 engines nest the values
+// in a data field next to the version metadata.
+func (s *Secret) KVData() map[string]interface{} {
+	if nested, ok := s.Data["data"].(map[string]interface{}); ok {
+		if _, versioned := s.Data["metadata"]; versioned {
+			return nested
+		}
+	}
+	return s.Data
+}
+
+// do sends a request to the Vault API and decodes the JSON response into out
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.address+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", c.token)
+	if c.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&vaultErr)
+		if len(vaultErr.Errors) > 0 {
+			return fmt.Errorf("unexpected status %s: %s", resp.Status, strings.Join(vaultErr.Errors, "; "))
+		}
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Read(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-token", r.Header.Get("X-Vault-Token"))
+		assert.Equal(t, "team-a", r.Header.Get("X-Vault-Namespace"))
+		switch r.URL.Path {
+		case "/v1/secret/data/app":
+			w.Write([]byte(`{"data":{"data":{"password":"secret"},"metadata":{"version":1}}}`))
+		case "/v1/kv/app":
+			w.Write([]byte(`{"lease_duration":2764800,"data":{"password":"secret"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer server.Close()
+	client := NewClient(server.URL+"/", "test-token", "team-a")
+
+	t.Run("KVVersion2", func(t *testing.T) {
+		secret, err := client.Read(context.Background(), "secret/data/app")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"password": "secret"}, secret.KVData())
+	})
+
+	t.Run("KVVersion1", func(t *testing.T) {
+		secret, err := client.Read(context.Background(), "/kv/app")
+		require.NoError(t, err)
+		assert.Equal(t, 2764800, secret.LeaseDuration)
+		assert.Equal(t, map[string]interface{}{"password": "secret"}, secret.KVData())
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := client.Read(context.Background(), "secret/data/missing")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to read secret/data/missing: unexpected status 404 Not Found")
+	})
+}
+
+func TestClient_RevokeLease(t *testing.T) {
+	var revoked string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/v1/sys/leases/revoke", r.URL.Path)
+		var body struct {
+			LeaseID string `json:"lease_id"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		revoked = body.LeaseID
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-token", "")
+	require.NoError(t, client.RevokeLease(context.Background(), "database/creds/backup/abc123"))
+	assert.Equal(t, "database/creds/backup/abc123", revoked)
+}
+
+func TestNewClientFromEnv(t *testing.T) {
+	t.Setenv("VAULT_ADDR", "")
+	_, err := NewClientFromEnv()
+	assert.EqualError(t, err, "VAULT_ADDR is not set")
+
+	t.Setenv("VAULT_ADDR", "http://127.0.0.1:8200")
+	t.Setenv("VAULT_TOKEN", "")
+	_, err = NewClientFromEnv()
+	assert.EqualError(t, err, "VAULT_TOKEN is not set")
+
+	t.Setenv("VAULT_TOKEN", "root")
+	client, err := NewClientFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "http://127.0.0.1:8200", client.address)
+}